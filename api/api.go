@@ -10,6 +10,7 @@ import (
 	"mpt_data/api/person"
 	"mpt_data/api/person/absenceperson"
 	"mpt_data/api/plan"
+	"mpt_data/api/stats"
 	"mpt_data/api/task"
 	"mpt_data/helper/config"
 	"net/http"
@@ -41,6 +42,7 @@ func registerRoutes(mux *mux.Router) {
 	absencemeeting.RegisterRoutes(mux)
 	absenceperson.RegisterRoutes(mux)
 	plan.RegisterRoutes(mux)
+	stats.RegisterRoutes(mux)
 }
 
 func corsHandler() *cors.Cors {
@@ -3,6 +3,7 @@ package apihelper
 
 import (
 	"encoding/json"
+	"mpt_data/api/middleware"
 	"mpt_data/helper/errors"
 	apiModel "mpt_data/models/apimodel"
 	generalmodel "mpt_data/models/general"
@@ -96,3 +97,13 @@ func ExtractIntFromURL(r *http.Request, fieldName string) (int64, error) {
 
 	return fieldInt, nil
 }
+
+// UserIDFromRequest returns the id of the user authenticated by the middleware,
+// nil if the request is not authenticated, e.g. if authentication is disabled
+func UserIDFromRequest(r *http.Request) *uint {
+	user, ok := middleware.GetUser(r.Context())
+	if !ok {
+		return nil
+	}
+	return &user.ID
+}
@@ -5,11 +5,14 @@ import (
 	"mpt_data/api/apihelper"
 	"mpt_data/api/middleware"
 	"mpt_data/database/auth"
+	"mpt_data/helper/errors"
 	apiModel "mpt_data/models/apimodel"
+	dbModel "mpt_data/models/dbmodel"
 
 	"net/http"
 
 	"github.com/gorilla/mux"
+	"gorm.io/gorm"
 )
 
 const packageName = "api.auth"
@@ -17,7 +20,10 @@ const packageName = "api.auth"
 // RegisterRoutes adds all routes to a mux.Router
 func RegisterRoutes(mux *mux.Router) {
 	mux.HandleFunc(apiModel.LoginHref, login).Methods(http.MethodPost)
-	mux.HandleFunc(apiModel.UserChangePWHref, middleware.CheckAuthentication(changePW)).Methods(http.MethodPost)
+	mux.HandleFunc(apiModel.UserChangePWHref, middleware.CheckUser(changePW)).Methods(http.MethodPost)
+	mux.HandleFunc(apiModel.UserHref, middleware.CheckAuthentication(getUsers)).Methods(http.MethodGet)
+	mux.HandleFunc(apiModel.UserHref, middleware.CheckAuthentication(addUser)).Methods(http.MethodPost)
+	mux.HandleFunc(apiModel.UserHrefWithID, middleware.CheckAuthentication(updateUser)).Methods(http.MethodPut)
 }
 
 // @Summary		Login
@@ -78,3 +84,100 @@ func changePW(w http.ResponseWriter, r *http.Request) {
 	}
 	apihelper.ResponseJSON(w, apiModel.Result{Result: "password changed succesfull"})
 }
+
+// @Summary		Get Users
+// @Description	Get all users with their role and linked person
+// @Tags			Users
+// @Accept			json
+// @Produce		json
+// @Security		ApiKeyAuth
+// @Success		200	{array}	apiModel.UserInfo
+// @Failure		401
+// @Failure		403
+// @Router			/user [GET]
+func getUsers(w http.ResponseWriter, r *http.Request) {
+	tx := middleware.GetTx(r.Context())
+	users, err := auth.GetUsers(tx)
+	if err != nil {
+		apihelper.InternalError(w, err)
+		return
+	}
+	infos := make([]apiModel.UserInfo, 0, len(users))
+	for _, user := range users {
+		infos = append(infos, userInfo(user))
+	}
+	apihelper.ResponseJSON(w, infos)
+}
+
+// @Summary		Add User
+// @Description	Add a user with role admin or self. A user with role self needs a person,
+// @Description	it may only use the endpoints at /me, which are scoped to this person
+// @Tags			Users
+// @Accept			json
+// @Produce		json
+// @Param			User	body	apiModel.User	true	"User"
+// @Security		ApiKeyAuth
+// @Success		201	{object}	apiModel.UserInfo
+// @Failure		400	{object}	apiModel.Result
+// @Failure		401
+// @Failure		403
+// @Router			/user [POST]
+func addUser(w http.ResponseWriter, r *http.Request) {
+	var body apiModel.User
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		apihelper.ResponseBadRequest(w, apiModel.Result{Result: "error in request body"}, err)
+		return
+	}
+
+	tx := middleware.GetTx(r.Context())
+	user, err := auth.RegisterUser(tx, body)
+	responseUser(w, user, err, http.StatusCreated)
+}
+
+// @Summary		Update User
+// @Description	Change the role of a user and the person it is linked to
+// @Tags			Users
+// @Accept			json
+// @Produce		json
+// @Param			id		path	int				true	"ID of user"
+// @Param			User	body	apiModel.User	true	"Role and PersonID of user, other fields are ignored"
+// @Security		ApiKeyAuth
+// @Success		200	{object}	apiModel.UserInfo
+// @Failure		400	{object}	apiModel.Result
+// @Failure		401
+// @Failure		403
+// @Router			/user/{id} [PUT]
+func updateUser(w http.ResponseWriter, r *http.Request) {
+	id, err := apihelper.ExtractIntFromURL(r, "id")
+	if err != nil || id <= 0 {
+		apihelper.ResponseBadRequest(w, apiModel.Result{Result: "id not correctly set"}, err)
+		return
+	}
+	var body apiModel.User
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		apihelper.ResponseBadRequest(w, apiModel.Result{Result: "error in request body"}, err)
+		return
+	}
+
+	tx := middleware.GetTx(r.Context())
+	user, err := auth.UpdateUserLink(tx, uint(id), body.Role, body.PersonID)
+	responseUser(w, user, err)
+}
+
+// responseUser sends the user, or StatusBadRequest if the user data is not valid
+func responseUser(w http.ResponseWriter, user dbModel.User, err error, statusCode ...int) {
+	switch err {
+	case nil:
+		apihelper.ResponseJSON(w, userInfo(user), statusCode...)
+	case errors.ErrUserNotComplete, errors.ErrUserAlreadyExists, errors.ErrInvalidRole,
+		errors.ErrUserNotLinked, errors.ErrPersonAlreadyLinked, gorm.ErrRecordNotFound:
+		apihelper.ResponseBadRequest(w, apiModel.Result{Result: "user not valid", Error: err.Error()}, err)
+	default:
+		apihelper.InternalError(w, err)
+	}
+}
+
+// userInfo returns the user without its hash
+func userInfo(user dbModel.User) apiModel.UserInfo {
+	return apiModel.UserInfo{ID: user.ID, Username: user.Username, Role: user.Role, PersonID: user.PersonID}
+}
@@ -0,0 +1,43 @@
+package meeting
+
+import (
+	"mpt_data/api/apihelper"
+	"mpt_data/api/middleware"
+	"mpt_data/database/meeting"
+	"mpt_data/helper"
+	apiModel "mpt_data/models/apimodel"
+	generalmodel "mpt_data/models/general"
+	"net/http"
+)
+
+// @Summary		Get Holiday Meetings
+// @Description	Get all meetings in the specified time period, which are at a holiday of the configured region or ICS file
+// @Tags			Meeting
+// @Accept			json
+// @Produce		json
+// @Param			StartDate	query	string	true	"Start date/timestamp, Either English Date, or RFC3339"	Example("2023-01-21", "2023-01-21T00:00:00+00:00")
+// @Param			EndDate		query	string	true	"End date/timestamp, Either English Date, or RFC3339"	Example("2023-01-21", "2023-01-21T00:00:00+00:00")
+// @Security		ApiKeyAuth
+// @Success		200	{array}		apiModel.HolidayMeeting
+// @Failure		400	{object}	apiModel.Result
+// @Failure		401
+// @Router			/meeting/holiday [GET]
+func getHolidayMeetings(w http.ResponseWriter, r *http.Request) {
+	queryParams := r.URL.Query()
+
+	startDate, err := helper.ParseTime(queryParams.Get("StartDate"))
+	endDate, err2 := helper.ParseTime(queryParams.Get("EndDate"))
+	if err != nil || err2 != nil {
+		apihelper.ResponseBadRequest(w, apiModel.Result{Result: "could not parse StartDate and/or EndDate"}, err)
+		return
+	}
+
+	tx := middleware.GetTx(r.Context())
+	meetings, err := meeting.GetHolidayMeetings(tx, generalmodel.Period{StartDate: startDate, EndDate: endDate})
+	if err != nil {
+		// a misconfigured region or ICS file
+		apihelper.InternalError(w, err)
+		return
+	}
+	apihelper.ResponseJSON(w, meetings)
+}
@@ -23,6 +23,22 @@ const packageName = "api.meeting"
 
 // RegisterRoutes adds all routes to a mux.Router
 func RegisterRoutes(mux *mux.Router) {
+	// holiday.go
+	mux.HandleFunc(apiModel.MeetingHolidayHref, middleware.CheckAuthentication(getHolidayMeetings)).Methods(http.MethodGet)
+
+	// type.go
+	mux.HandleFunc(apiModel.MeetingTypeHref, middleware.CheckAuthentication(getMeetingTypes)).Methods(http.MethodGet)
+	mux.HandleFunc(apiModel.MeetingTypeHref, middleware.CheckAuthentication(addMeetingType)).Methods(http.MethodPost)
+	mux.HandleFunc(apiModel.MeetingTypeHrefWithID, middleware.CheckAuthentication(updateMeetingType)).Methods(http.MethodPut)
+	mux.HandleFunc(apiModel.MeetingTypeHrefWithID, middleware.CheckAuthentication(deleteMeetingType)).Methods(http.MethodDelete)
+
+	// series.go
+	mux.HandleFunc(apiModel.MeetingSeriesHref, middleware.CheckAuthentication(getMeetingSeries)).Methods(http.MethodGet)
+	mux.HandleFunc(apiModel.MeetingSeriesHref, middleware.CheckAuthentication(addMeetingSeries)).Methods(http.MethodPost)
+	mux.HandleFunc(apiModel.MeetingSeriesHrefWithID, middleware.CheckAuthentication(updateMeetingSeries)).Methods(http.MethodPut)
+	mux.HandleFunc(apiModel.MeetingSeriesHrefWithID, middleware.CheckAuthentication(deleteMeetingSeries)).Methods(http.MethodDelete)
+	mux.HandleFunc(apiModel.MeetingSeriesHrefMaterialize, middleware.CheckAuthentication(materializeMeetingSeries)).Methods(http.MethodPost)
+
 	mux.HandleFunc(apiModel.MeetingHref, middleware.CheckAuthentication(getMeetings)).Methods(http.MethodGet)
 	mux.HandleFunc(apiModel.MeetingHref, middleware.CheckAuthentication(addMeeting)).Methods(http.MethodPost)
 	mux.HandleFunc(apiModel.MeetingHrefWithID, middleware.CheckAuthentication(updatetMeeting)).Methods(http.MethodPut)
@@ -86,7 +102,7 @@ func addMeeting(w http.ResponseWriter, r *http.Request) {
 		switch err {
 		case errors.ErrNotAllMeetingsCreated:
 			apihelper.ResponseJSON(w, apiModel.Result{Result: "not all meetings created"})
-		case gorm.ErrEmptySlice, gorm.ErrInvalidData, gorm.ErrRecordNotFound:
+		case gorm.ErrEmptySlice, gorm.ErrInvalidData, gorm.ErrRecordNotFound, errors.ErrInvalidMeetingType:
 			w.WriteHeader(http.StatusBadRequest)
 		default:
 			apihelper.InternalError(w, err)
@@ -97,7 +113,7 @@ func addMeeting(w http.ResponseWriter, r *http.Request) {
 }
 
 // @Summary		Update Meetings
-// @Description	Update the date of one meeting
+// @Description	Update the date and the MeetingTypeID of one meeting
 // @Tags			Meeting
 // @Accept			json
 // @Produce		json
@@ -126,7 +142,10 @@ func updatetMeeting(w http.ResponseWriter, r *http.Request) {
 	meetingIn.ID = uint(*id)
 
 	err = meeting.UpdateMeeting(meetingIn)
-	if err != nil {
+	if err == errors.ErrInvalidMeetingType {
+		apihelper.ResponseBadRequest(w, apiModel.Result{Result: "meeting not updated", Error: err.Error()}, err)
+		return
+	} else if err != nil {
 		apihelper.InternalError(w, err)
 		return
 	}
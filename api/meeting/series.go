@@ -0,0 +1,186 @@
+package meeting
+
+import (
+	"encoding/json"
+	"mpt_data/api/apihelper"
+	"mpt_data/api/middleware"
+	"mpt_data/database/meeting"
+	"mpt_data/helper"
+	"mpt_data/helper/errors"
+	apiModel "mpt_data/models/apimodel"
+	dbModel "mpt_data/models/dbmodel"
+	"net/http"
+
+	"gorm.io/gorm"
+)
+
+// @Summary		Get Meeting Series
+// @Description	Get all meeting series with their exceptions
+// @Tags			Meeting
+// @Accept			json
+// @Produce		json
+// @Security		ApiKeyAuth
+// @Success		200	{array}	dbModel.MeetingSeries
+// @Failure		401
+// @Router			/meeting/series [GET]
+func getMeetingSeries(w http.ResponseWriter, r *http.Request) {
+	tx := middleware.GetTx(r.Context())
+	series, err := meeting.GetMeetingSeries(tx)
+	if err != nil {
+		apihelper.InternalError(w, err)
+		return
+	}
+	apihelper.ResponseJSON(w, series)
+}
+
+// @Summary		Add Meeting Series
+// @Description	Add a meeting series. Frequency is weekly or monthly, Interval 2 means every second week or month.
+// @Description	Monthly series take place on the Ordinal (1 to 4, -1 for the last) Weekday (0 for sunday) of the month.
+// @Description	No meetings are created until the series is materialized
+// @Tags			Meeting
+// @Accept			json
+// @Produce		json
+// @Param			series	body	dbModel.MeetingSeries	true	"Meeting series"
+// @Security		ApiKeyAuth
+// @Success		201	{object}	dbModel.MeetingSeries
+// @Failure		400	{object}	apiModel.Result
+// @Failure		401
+// @Router			/meeting/series [POST]
+func addMeetingSeries(w http.ResponseWriter, r *http.Request) {
+	var series dbModel.MeetingSeries
+	if err := json.NewDecoder(r.Body).Decode(&series); err != nil {
+		apihelper.ResponseBadRequest(w, apiModel.Result{Result: "error in request body"}, err)
+		return
+	}
+
+	tx := middleware.GetTx(r.Context())
+	switch err := meeting.AddMeetingSeries(tx, &series); err {
+	case nil:
+		apihelper.ResponseJSON(w, series, http.StatusCreated)
+	case errors.ErrInvalidMeetingSeries:
+		apihelper.ResponseBadRequest(w, apiModel.Result{Result: "meeting series not created", Error: err.Error()}, err)
+	default:
+		apihelper.InternalError(w, err)
+	}
+}
+
+// @Summary		Update Meeting Series
+// @Description	Update the rule and replace the exceptions of a meeting series, meetings already created are not changed
+// @Tags			Meeting
+// @Accept			json
+// @Produce		json
+// @Param			id		path	int						true	"ID of meeting series"
+// @Param			series	body	dbModel.MeetingSeries	true	"Meeting series"
+// @Security		ApiKeyAuth
+// @Success		200	{object}	dbModel.MeetingSeries
+// @Failure		400	{object}	apiModel.Result
+// @Failure		401
+// @Router			/meeting/series/{id} [PUT]
+func updateMeetingSeries(w http.ResponseWriter, r *http.Request) {
+	id, err := apihelper.ExtractIntFromURL(r, "id")
+	if err != nil || id <= 0 {
+		apihelper.ResponseBadRequest(w, apiModel.Result{Result: "id not valid"}, err)
+		return
+	}
+
+	var series dbModel.MeetingSeries
+	if err := json.NewDecoder(r.Body).Decode(&series); err != nil {
+		apihelper.ResponseBadRequest(w, apiModel.Result{Result: "error in request body"}, err)
+		return
+	}
+	series.ID = uint(id)
+
+	tx := middleware.GetTx(r.Context())
+	switch err := meeting.UpdateMeetingSeries(tx, &series); err {
+	case nil:
+		apihelper.ResponseJSON(w, series)
+	case errors.ErrInvalidMeetingSeries, gorm.ErrRecordNotFound:
+		apihelper.ResponseBadRequest(w, apiModel.Result{Result: "meeting series not updated", Error: err.Error()}, err)
+	default:
+		apihelper.InternalError(w, err)
+	}
+}
+
+// @Summary		Delete Meeting Series
+// @Description	Delete a meeting series, meetings created by the series are kept
+// @Tags			Meeting
+// @Accept			json
+// @Produce		json
+// @Param			id	path	int	true	"ID of meeting series"
+// @Security		ApiKeyAuth
+// @Success		200
+// @Failure		400	{object}	apiModel.Result
+// @Failure		401
+// @Router			/meeting/series/{id} [DELETE]
+func deleteMeetingSeries(w http.ResponseWriter, r *http.Request) {
+	id, err := apihelper.ExtractIntFromURL(r, "id")
+	if err != nil || id <= 0 {
+		apihelper.ResponseBadRequest(w, apiModel.Result{Result: "id not valid"}, err)
+		return
+	}
+
+	tx := middleware.GetTx(r.Context())
+	switch err := meeting.DeleteMeetingSeries(tx, uint(id)); err {
+	case nil:
+		w.WriteHeader(http.StatusOK)
+	case gorm.ErrRecordNotFound:
+		apihelper.ResponseBadRequest(w, apiModel.Result{Result: "meeting series not deleted", Error: err.Error()}, err)
+	default:
+		apihelper.InternalError(w, err)
+	}
+}
+
+// @Summary		Materialize Meeting Series
+// @Description	Create the meetings of a series in the period, which do not exist yet.
+// @Description	Days which already have a meeting are reported as existing, so the call can be repeated.
+// @Description	Without StartDate and EndDate the whole series is materialized
+// @Tags			Meeting
+// @Accept			json
+// @Produce		json
+// @Param			id			path	int		true	"ID of meeting series"
+// @Param			StartDate	query	string	false	"Start date/timestamp, Either English Date, or RFC3339"	Example("2023-01-21", "2023-01-21T00:00:00+00:00")
+// @Param			EndDate		query	string	false	"End date/timestamp, Either English Date, or RFC3339"	Example("2023-01-21", "2023-01-21T00:00:00+00:00")
+// @Security		ApiKeyAuth
+// @Success		200	{object}	apiModel.MaterializedMeetings
+// @Failure		400	{object}	apiModel.Result
+// @Failure		401
+// @Router			/meeting/series/{id}/materialize [POST]
+func materializeMeetingSeries(w http.ResponseWriter, r *http.Request) {
+	id, err := apihelper.ExtractIntFromURL(r, "id")
+	if err != nil || id <= 0 {
+		apihelper.ResponseBadRequest(w, apiModel.Result{Result: "id not valid"}, err)
+		return
+	}
+
+	tx := middleware.GetTx(r.Context())
+	series, err := meeting.GetMeetingSeriesByID(tx, uint(id))
+	if err == gorm.ErrRecordNotFound {
+		apihelper.ResponseBadRequest(w, apiModel.Result{Result: "meeting series not found", Error: err.Error()}, err)
+		return
+	} else if err != nil {
+		apihelper.InternalError(w, err)
+		return
+	}
+
+	period := series.Period
+	queryParams := r.URL.Query()
+	if value := queryParams.Get("StartDate"); value != "" {
+		if period.StartDate, err = helper.ParseTime(value); err != nil {
+			apihelper.ResponseBadRequest(w, apiModel.Result{Result: "could not parse StartDate and/or EndDate"}, err)
+			return
+		}
+	}
+	if value := queryParams.Get("EndDate"); value != "" {
+		if period.EndDate, err = helper.ParseTime(value); err != nil {
+			apihelper.ResponseBadRequest(w, apiModel.Result{Result: "could not parse StartDate and/or EndDate"}, err)
+			return
+		}
+	}
+
+	result, err := meeting.MaterializeMeetingSeries(tx, series.ID, period)
+	if err != nil {
+		apihelper.InternalError(w, err)
+		return
+	}
+	apihelper.ResponseJSON(w, result)
+}
@@ -0,0 +1,86 @@
+package meeting
+
+import (
+	apiModel "mpt_data/models/apimodel"
+	dbModel "mpt_data/models/dbmodel"
+	generalmodel "mpt_data/models/general"
+	api_test "mpt_data/test/api"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestAddMeetingSeries(t *testing.T) {
+	// Prepare
+	period := generalmodel.Period{
+		StartDate: time.Date(2007, 1, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2007, 12, 31, 0, 0, 0, 0, time.UTC),
+	}
+	var testcases = []struct {
+		name       string
+		series     dbModel.MeetingSeries
+		statusCode int
+	}{
+		{
+			"first friday of the month",
+			dbModel.MeetingSeries{Period: period, Frequency: dbModel.SeriesMonthly, Weekday: 5, Ordinal: 1, TimeOfDay: "19:00"},
+			http.StatusCreated,
+		},
+		{
+			"invalid frequency",
+			dbModel.MeetingSeries{Period: period, Frequency: "daily"},
+			http.StatusBadRequest,
+		},
+		{
+			"invalid time of day",
+			dbModel.MeetingSeries{Period: period, Frequency: dbModel.SeriesWeekly, TimeOfDay: "25:00"},
+			http.StatusBadRequest,
+		},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			// Act
+			response := api_test.DoRequest(t, api_test.RequestData{
+				Data:   testcase.series,
+				Route:  "/api/v1/meeting/series",
+				Method: http.MethodPost,
+				Router: addMeetingSeries,
+				Path:   apiModel.MeetingSeriesHref,
+			})
+			// Assert
+			if status := response.Code; status != testcase.statusCode {
+				t.Errorf("expected status code %d, got %d", testcase.statusCode, status)
+				t.Logf("Body: %s", response.Body)
+			}
+		})
+	}
+}
+
+func TestMaterializeMeetingSeries(t *testing.T) {
+	var testcases = []struct {
+		name       string
+		route      string
+		statusCode int
+	}{
+		{"invalid id", "/api/v1/meeting/series/0/materialize", http.StatusBadRequest},
+		{"unknown series", "/api/v1/meeting/series/16777215/materialize", http.StatusBadRequest},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			// Act
+			response := api_test.DoRequest(t, api_test.RequestData{
+				Route:  testcase.route,
+				Method: http.MethodPost,
+				Router: materializeMeetingSeries,
+				Path:   apiModel.MeetingSeriesHrefMaterialize,
+			})
+			// Assert
+			if status := response.Code; status != testcase.statusCode {
+				t.Errorf("expected status code %d, got %d", testcase.statusCode, status)
+				t.Logf("Body: %s", response.Body)
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package meeting
+
+import (
+	"encoding/json"
+	"mpt_data/api/apihelper"
+	"mpt_data/api/middleware"
+	"mpt_data/database/meeting"
+	"mpt_data/helper/errors"
+	apiModel "mpt_data/models/apimodel"
+	dbModel "mpt_data/models/dbmodel"
+	"net/http"
+
+	"gorm.io/gorm"
+)
+
+// @Summary		Get Meeting Types
+// @Description	Get all meeting types with the TaskDetails they need
+// @Tags			Meeting
+// @Accept			json
+// @Produce		json
+// @Security		ApiKeyAuth
+// @Success		200	{array}	dbModel.MeetingType
+// @Failure		401
+// @Router			/meeting/type [GET]
+func getMeetingTypes(w http.ResponseWriter, r *http.Request) {
+	tx := middleware.GetTx(r.Context())
+	types, err := meeting.GetMeetingTypes(tx)
+	if err != nil {
+		apihelper.InternalError(w, err)
+		return
+	}
+	apihelper.ResponseJSON(w, types)
+}
+
+// @Summary		Add Meeting Type
+// @Description	Add a meeting type, TaskDetails are referenced by their ID.
+// @Description	Plans of meetings with this type only get slots for these TaskDetails, meetings without type get slots for all TaskDetails
+// @Tags			Meeting
+// @Accept			json
+// @Produce		json
+// @Param			type	body	dbModel.MeetingType	true	"Meeting type"
+// @Security		ApiKeyAuth
+// @Success		201	{object}	dbModel.MeetingType
+// @Failure		400	{object}	apiModel.Result
+// @Failure		401
+// @Router			/meeting/type [POST]
+func addMeetingType(w http.ResponseWriter, r *http.Request) {
+	var meetingType dbModel.MeetingType
+	if err := json.NewDecoder(r.Body).Decode(&meetingType); err != nil {
+		apihelper.ResponseBadRequest(w, apiModel.Result{Result: "error in request body"}, err)
+		return
+	}
+
+	tx := middleware.GetTx(r.Context())
+	switch err := meeting.AddMeetingType(tx, &meetingType); err {
+	case nil:
+		apihelper.ResponseJSON(w, meetingType, http.StatusCreated)
+	case errors.ErrInvalidMeetingType, errors.ErrMeetingTypeExists:
+		apihelper.ResponseBadRequest(w, apiModel.Result{Result: "meeting type not created", Error: err.Error()}, err)
+	default:
+		apihelper.InternalError(w, err)
+	}
+}
+
+// @Summary		Update Meeting Type
+// @Description	Update the description and replace the TaskDetails of a meeting type, existing plans are not changed
+// @Tags			Meeting
+// @Accept			json
+// @Produce		json
+// @Param			id		path	int					true	"ID of meeting type"
+// @Param			type	body	dbModel.MeetingType	true	"Meeting type"
+// @Security		ApiKeyAuth
+// @Success		200	{object}	dbModel.MeetingType
+// @Failure		400	{object}	apiModel.Result
+// @Failure		401
+// @Router			/meeting/type/{id} [PUT]
+func updateMeetingType(w http.ResponseWriter, r *http.Request) {
+	id, err := apihelper.ExtractIntFromURL(r, "id")
+	if err != nil || id <= 0 {
+		apihelper.ResponseBadRequest(w, apiModel.Result{Result: "id not valid"}, err)
+		return
+	}
+
+	var meetingType dbModel.MeetingType
+	if err := json.NewDecoder(r.Body).Decode(&meetingType); err != nil {
+		apihelper.ResponseBadRequest(w, apiModel.Result{Result: "error in request body"}, err)
+		return
+	}
+	meetingType.ID = uint(id)
+
+	tx := middleware.GetTx(r.Context())
+	switch err := meeting.UpdateMeetingType(tx, &meetingType); err {
+	case nil:
+		apihelper.ResponseJSON(w, meetingType)
+	case errors.ErrInvalidMeetingType, errors.ErrMeetingTypeExists, gorm.ErrRecordNotFound:
+		apihelper.ResponseBadRequest(w, apiModel.Result{Result: "meeting type not updated", Error: err.Error()}, err)
+	default:
+		apihelper.InternalError(w, err)
+	}
+}
+
+// @Summary		Delete Meeting Type
+// @Description	Delete a meeting type, which is not used by any meeting or meeting series
+// @Tags			Meeting
+// @Accept			json
+// @Produce		json
+// @Param			id	path	int	true	"ID of meeting type"
+// @Security		ApiKeyAuth
+// @Success		200
+// @Failure		400	{object}	apiModel.Result
+// @Failure		401
+// @Router			/meeting/type/{id} [DELETE]
+func deleteMeetingType(w http.ResponseWriter, r *http.Request) {
+	id, err := apihelper.ExtractIntFromURL(r, "id")
+	if err != nil || id <= 0 {
+		apihelper.ResponseBadRequest(w, apiModel.Result{Result: "id not valid"}, err)
+		return
+	}
+
+	tx := middleware.GetTx(r.Context())
+	switch err := meeting.DeleteMeetingType(tx, uint(id)); err {
+	case nil:
+		w.WriteHeader(http.StatusOK)
+	case errors.ErrMeetingTypeInUse, gorm.ErrRecordNotFound:
+		apihelper.ResponseBadRequest(w, apiModel.Result{Result: "meeting type not deleted", Error: err.Error()}, err)
+	default:
+		apihelper.InternalError(w, err)
+	}
+}
@@ -2,13 +2,21 @@
 package middleware
 
 import (
+	"context"
+	"mpt_data/database"
 	"mpt_data/database/auth"
 	"mpt_data/helper/config"
+	dbModel "mpt_data/models/dbmodel"
 	"net/http"
+	"slices"
+	"strconv"
 	"strings"
+
+	"github.com/gorilla/mux"
 )
 
-// CheckAuthentication middleware checks if a user is correctly authenticated
+// CheckAuthentication middleware checks if a user is correctly authenticated.
+// Users with role self are forbidden, they may only use the self service endpoints
 func CheckAuthentication(next http.HandlerFunc) http.HandlerFunc {
 
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -17,22 +25,113 @@ func CheckAuthentication(next http.HandlerFunc) http.HandlerFunc {
 			return
 		}
 
-		token := r.Header.Get("Authorization")
-		if token == "" {
-			http.Error(w, "missing auth token", http.StatusUnauthorized)
+		user, ok := authenticate(w, r)
+		if !ok {
+			return
+		}
+		if user.IsSelf() {
+			http.Error(w, "forbidden for role self", http.StatusForbidden)
 			return
 		}
+		next.ServeHTTP(w, withUser(r, user))
+	}
+}
 
-		if !strings.HasPrefix(token, "Bearer ") {
-			http.Error(w, "wrong authheader type", http.StatusUnauthorized)
+// CheckUser middleware checks if a user of any role is correctly authenticated
+func CheckUser(next http.HandlerFunc) http.HandlerFunc {
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !config.Config.API.AuthenticationRequired {
+			next.ServeHTTP(w, r)
 			return
 		}
 
-		_, err := auth.ValidateJWT(strings.TrimPrefix(token, "Bearer "))
-		if err != nil {
-			http.Error(w, "invalid token", http.StatusUnauthorized)
+		user, ok := authenticate(w, r)
+		if !ok {
 			return
 		}
-		next.ServeHTTP(w, r)
+		next.ServeHTTP(w, withUser(r, user))
+	}
+}
+
+// CheckSelf middleware checks if a user of any role is correctly authenticated and linked to a person.
+// The ID of the person is set as URL variable id, so next only accesses the data of this person.
+// The user is always needed, even if authentication is not required
+func CheckSelf(next http.HandlerFunc) http.HandlerFunc {
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, ok := authenticate(w, r)
+		if !ok {
+			return
+		}
+		if user.PersonID == nil {
+			http.Error(w, "user is not linked to a person", http.StatusForbidden)
+			return
+		}
+
+		vars := mux.Vars(r)
+		if vars == nil {
+			vars = make(map[string]string)
+		}
+		vars["id"] = strconv.FormatUint(uint64(*user.PersonID), 10)
+		next.ServeHTTP(w, withUser(mux.SetURLVars(r, vars), user))
+	}
+}
+
+// authenticate validates the token of the request and loads its user.
+// Responds StatusUnauthorized and returns false, if the token or the user is not valid
+func authenticate(w http.ResponseWriter, r *http.Request) (dbModel.User, bool) {
+	token := r.Header.Get("Authorization")
+	if token == "" {
+		http.Error(w, "missing auth token", http.StatusUnauthorized)
+		return dbModel.User{}, false
+	}
+
+	if !strings.HasPrefix(token, "Bearer ") {
+		http.Error(w, "wrong authheader type", http.StatusUnauthorized)
+		return dbModel.User{}, false
+	}
+
+	if _, err := auth.ValidateJWT(strings.TrimPrefix(token, "Bearer ")); err != nil {
+		http.Error(w, "invalid token", http.StatusUnauthorized)
+		return dbModel.User{}, false
+	}
+	userID, err := auth.GetUserIDFromToken(token)
+	if err != nil {
+		http.Error(w, "invalid token", http.StatusUnauthorized)
+		return dbModel.User{}, false
+	}
+
+	// outside of the transaction of the request, as its read lock would block handlers writing without it
+	user, err := auth.GetUser(database.DB, userID)
+	if err != nil {
+		http.Error(w, "unknown user", http.StatusUnauthorized)
+		return dbModel.User{}, false
+	}
+	return user, true
+}
+
+// withUser stores the authenticated user in the context of the request
+func withUser(r *http.Request, user dbModel.User) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), userKey, user))
+}
+
+// GetUser loads the authenticated user, ok is false if the request was not authenticated
+func GetUser(ctx context.Context) (user dbModel.User, ok bool) {
+	user, ok = ctx.Value(userKey).(dbModel.User)
+	return
+}
+
+// ContactVisible reports if the user of the request may see the contact data of people.
+// Requests without authentication count as admin, the roles are configured in API.ContactRoles
+func ContactVisible(ctx context.Context) bool {
+	role := dbModel.RoleAdmin
+	if user, ok := GetUser(ctx); ok && user.Role != "" {
+		role = user.Role
+	}
+	roles := config.Config.API.ContactRoles
+	if roles == nil {
+		roles = []string{dbModel.RoleAdmin}
 	}
+	return slices.Contains(roles, role)
 }
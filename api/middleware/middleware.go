@@ -14,6 +14,7 @@ type key int
 const (
 	txKey key = iota
 	rollbackKey
+	userKey
 )
 
 // TransactionMiddleware provides database transaction handling for API
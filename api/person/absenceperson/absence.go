@@ -7,6 +7,7 @@ import (
 	"mpt_data/database"
 	"mpt_data/database/absence"
 	"mpt_data/helper"
+	"mpt_data/helper/errors"
 	"mpt_data/models/apimodel"
 	dbModel "mpt_data/models/dbmodel"
 	generalmodel "mpt_data/models/general"
@@ -27,10 +28,20 @@ func RegisterRoutes(mux *mux.Router) {
 	mux.HandleFunc(apimodel.PersonAbsenceRecuring, middleware.CheckAuthentication(getAbsenceRecurring)).Methods(http.MethodGet)
 	mux.HandleFunc(apimodel.PersonAbsenceRecuring, middleware.CheckAuthentication(addAbsenceRecurring)).Methods(http.MethodPost)
 	mux.HandleFunc(apimodel.PersonAbsenceRecuring, middleware.CheckAuthentication(deleteAbsenceRecurring)).Methods(http.MethodDelete)
+
+	// the same handlers scoped to the person linked to the user
+	mux.HandleFunc(apimodel.MeAbsenceHref, middleware.CheckSelf(getAbsence)).Methods(http.MethodGet)
+	mux.HandleFunc(apimodel.MeAbsenceHref, middleware.CheckSelf(addAbsence)).Methods(http.MethodPost)
+	mux.HandleFunc(apimodel.MeAbsenceHref, middleware.CheckSelf(deleteAbsence)).Methods(http.MethodDelete)
+
+	mux.HandleFunc(apimodel.MeAbsenceRecurringHref, middleware.CheckSelf(getAbsenceRecurring)).Methods(http.MethodGet)
+	mux.HandleFunc(apimodel.MeAbsenceRecurringHref, middleware.CheckSelf(addAbsenceRecurring)).Methods(http.MethodPost)
+	mux.HandleFunc(apimodel.MeAbsenceRecurringHref, middleware.CheckSelf(deleteAbsenceRecurring)).Methods(http.MethodDelete)
 }
 
 // @Summary		Get Absence
-// @Description	Get absence of person in period
+// @Description	Get absence of person in period, at single meetings and in absence periods overlapping it
+// @Description	At /me the absence of the person linked to the user is used
 // @Tags			Person,Absence
 // @Accept			json
 // @Produce		json
@@ -38,10 +49,11 @@ func RegisterRoutes(mux *mux.Router) {
 // @Param			StartDate	query	string	true	"Start date/timestamp, Either English Date, or RFC3339"	Example("2023-01-21", "2023-01-21T00:00:00+00:00")
 // @Param			EndDate		query	string	true	"End date/timestamp, Either English Date, or RFC3339"	Example("2023-01-21", "2023-01-21T00:00:00+00:00")
 // @Security		ApiKeyAuth
-// @Success		200	{array}	dbModel.Meeting
+// @Success		200	{object}	apimodel.PersonAbsences
 // @Failure		400
 // @Failure		401
 // @Router			/person/{PersonId}/absence [GET]
+// @Router			/me/absence [GET]
 func getAbsence(w http.ResponseWriter, r *http.Request) {
 	const funcName = packageName + ".getAbsence"
 	var id *int
@@ -60,7 +72,12 @@ func getAbsence(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	data, err := absence.GetAbsencePerson(uint(*id), generalmodel.Period{StartDate: startDate, EndDate: endDate})
+	period := generalmodel.Period{StartDate: startDate, EndDate: endDate}
+	var data apimodel.PersonAbsences
+	data.Meetings, err = absence.GetAbsencePerson(uint(*id), period)
+	if err == nil {
+		data.Periods, err = absence.GetAbsencePeriods(middleware.GetTx(r.Context()), uint(*id), period)
+	}
 	if err != nil {
 		switch err {
 		case gorm.ErrRecordNotFound:
@@ -76,24 +93,26 @@ func getAbsence(w http.ResponseWriter, r *http.Request) {
 }
 
 // @Summary		Add Absence
-// @Description	Add absence of person in period
+// @Description	Add absence of person at meetings and in periods of days, e.g. a vacation.
+// @Description	A plain array is read as IDs of meetings
+// @Description	At /me the absence of the person linked to the user is used
 // @Tags			Person,Absence
 // @Accept			json
 // @Produce		json
-// @Param			PersonId	path	int		true	"ID of person"
-// @Param			Absence		body	[]uint	true	"ID of meetings where person is absent"
+// @Param			PersonId	path	int						true	"ID of person"
+// @Param			Absence		body	apimodel.AbsenceRequest	true	"ID of meetings and periods where person is absent"
 // @Security		ApiKeyAuth
-// @Success		200
-// @Success		201
-// @Failure		400
+// @Success		201	{object}	apimodel.AbsenceRequest
+// @Failure		400	{object}	apimodel.Result
 // @Failure		401
 // @Router			/person/{PersonId}/absence [POST]
+// @Router			/me/absence [POST]
 func addAbsence(w http.ResponseWriter, r *http.Request) {
 	const funcName = packageName + ".addAbsence"
-	var absences []uint
+	var absences apimodel.AbsenceRequest
 	var absencePerson []dbModel.PersonAbsence
 	if err := json.NewDecoder(r.Body).Decode(&absences); err != nil {
-		apihelper.ResponseBadRequest(w, apimodel.Result{Result: "meetings id not valid"}, err)
+		apihelper.ResponseBadRequest(w, apimodel.Result{Result: "absence not valid"}, err)
 		return
 	}
 
@@ -102,42 +121,58 @@ func addAbsence(w http.ResponseWriter, r *http.Request) {
 		apihelper.ResponseBadRequest(w, apimodel.Result{Result: "id not valid"}, err)
 		return
 	}
-	for _, ab := range absences {
+	for _, ab := range absences.Meetings {
 		absencePerson = append(absencePerson,
 			dbModel.PersonAbsence{PersonID: uint(*id), MeetingID: ab})
 	}
+	for i := range absences.Periods {
+		absences.Periods[i].PersonID = uint(*id)
+	}
 
-	err = absence.AddAbsence(absencePerson)
-	if err != nil {
-		switch err {
-		case gorm.ErrEmptySlice, gorm.ErrInvalidData, gorm.ErrRecordNotFound:
-			w.WriteHeader(http.StatusBadRequest)
-		default:
-			apihelper.InternalError(w, err)
+	switch {
+	case len(absencePerson) == 0 && len(absences.Periods) == 0:
+		err = gorm.ErrEmptySlice
+	case len(absences.Periods) == 0:
+		err = absence.AddAbsence(absencePerson)
+	default:
+		absences.Periods, err = absence.AddAbsencePeriods(middleware.GetTx(r.Context()), absences.Periods)
+		if err == nil && len(absencePerson) > 0 {
+			err = absence.AddAbsence(absencePerson)
 		}
-	} else {
+	}
+	switch err {
+	case nil:
 		apihelper.ResponseJSON(w, absences, http.StatusCreated)
+	case errors.ErrInvalidAbsencePeriod:
+		apihelper.ResponseBadRequest(w, apimodel.Result{Result: "absence not valid", Error: err.Error()}, err)
+	case gorm.ErrEmptySlice, gorm.ErrInvalidData, gorm.ErrRecordNotFound:
+		w.WriteHeader(http.StatusBadRequest)
+	default:
+		apihelper.InternalError(w, err)
 	}
 }
 
 // @Summary		Delete Absence
-// @Description	Delete absence of person in period
+// @Description	Delete absence of person at meetings and absence periods, periods are identified by their ID.
+// @Description	A plain array is read as IDs of meetings
+// @Description	At /me the absence of the person linked to the user is used
 // @Tags			Person,Absence
 // @Accept			json
 // @Produce		json
-// @Param			PersonId	path	int		true	"ID of person"
-// @Param			Absence		body	[]uint	true	"ID of meeting where person is no longer absent"
+// @Param			PersonId	path	int						true	"ID of person"
+// @Param			Absence		body	apimodel.AbsenceRequest	true	"ID of meetings and periods where person is no longer absent"
 // @Security		ApiKeyAuth
 // @Success		200
 // @Failure		400
 // @Failure		401
 // @Router			/person/{PersonId}/absence [DELETE]
+// @Router			/me/absence [DELETE]
 func deleteAbsence(w http.ResponseWriter, r *http.Request) {
 	const funcName = packageName + ".deleteAbsence"
-	var absences []uint
+	var absences apimodel.AbsenceRequest
 	var absencePerson []dbModel.PersonAbsence
 	if err := json.NewDecoder(r.Body).Decode(&absences); err != nil {
-		apihelper.ResponseBadRequest(w, apimodel.Result{Result: "meetings id not valid"}, err)
+		apihelper.ResponseBadRequest(w, apimodel.Result{Result: "absence not valid"}, err)
 		return
 	}
 
@@ -146,15 +181,24 @@ func deleteAbsence(w http.ResponseWriter, r *http.Request) {
 		apihelper.ResponseBadRequest(w, apimodel.Result{Result: "people id not valid"}, err)
 		return
 	}
-	for _, ab := range absences {
+	for _, ab := range absences.Meetings {
 		absencePerson = append(absencePerson,
 			dbModel.PersonAbsence{PersonID: uint(*id), MeetingID: ab})
 	}
+	periodIDs := make([]uint, 0, len(absences.Periods))
+	for _, period := range absences.Periods {
+		periodIDs = append(periodIDs, period.ID)
+	}
 
-	err = absence.DeleteAbsence(absencePerson)
+	if len(periodIDs) > 0 {
+		err = absence.DeleteAbsencePeriods(middleware.GetTx(r.Context()), uint(*id), periodIDs)
+	}
+	if err == nil {
+		err = absence.DeleteAbsence(absencePerson)
+	}
 	if err != nil {
 		switch err {
-		case gorm.ErrEmptySlice, gorm.ErrInvalidData, gorm.ErrRecordNotFound:
+		case gorm.ErrEmptySlice, gorm.ErrInvalidData, gorm.ErrRecordNotFound, errors.ErrIDNotSet:
 			w.WriteHeader(http.StatusBadRequest)
 		default:
 			apihelper.InternalError(w, err)
@@ -166,6 +210,7 @@ func deleteAbsence(w http.ResponseWriter, r *http.Request) {
 
 // @Summary		Get Absence
 // @Description	Get recurring absence of person
+// @Description	At /me the absence of the person linked to the user is used
 // @Tags			Person,Absence
 // @Accept			json
 // @Produce		json
@@ -175,6 +220,7 @@ func deleteAbsence(w http.ResponseWriter, r *http.Request) {
 // @Failure		400
 // @Failure		401
 // @Router			/person/{PersonId}/absencerecurring [GET]
+// @Router			/me/absencerecurring [GET]
 func getAbsenceRecurring(w http.ResponseWriter, r *http.Request) {
 	const funcName = packageName + ".getAbsenceRecurring"
 	var id *int
@@ -205,26 +251,28 @@ func getAbsenceRecurring(w http.ResponseWriter, r *http.Request) {
 }
 
 // @Summary		Add Absence
-// @Description	Add recurring absence to person
+// @Description	Add recurring absence to person, as rule of the RFC 5545 RRULE subset FREQ, INTERVAL, UNTIL, BYMONTH, BYWEEKNO, BYMONTHDAY and BYDAY.
+// @Description	Rules with interval need ValidFrom. Numbers are read as weekdays, 0 = Sunday
+// @Description	At /me the absence of the person linked to the user is used
 // @Tags			Person,Absence
 // @Accept			json
 // @Produce		json
-// @Param			PersonId	path	int		true	"ID of person"
-// @Param			Absence		body	[]int	true	"Weekdays where person is absent. 0 = Sunday"
+// @Param			PersonId	path	int								true	"ID of person"
+// @Param			Absence		body	apimodel.RecurringAbsenceRequest	true	"Recurring absences or weekdays where person is absent"
 // @Security		ApiKeyAuth
-// @Success		200
-// @Success		201
+// @Success		201	{array}	dbModel.PersonRecurringAbsence
 // @Failure		400
 // @Failure		401
 // @Router			/person/{PersonId}/absencerecurring [POST]
+// @Router			/me/absencerecurring [POST]
 func addAbsenceRecurring(w http.ResponseWriter, r *http.Request) {
 	const funcName = packageName + ".addAbsenceRecurring"
 
-	var absences []int
+	var absences apimodel.RecurringAbsenceRequest
 	if err := json.NewDecoder(r.Body).Decode(&absences); err != nil {
 		apihelper.ResponseError(w, *apimodel.GetInavalidRequestProblemDetails(
 			http.StatusBadRequest,
-			"Weekdays not correctly specified",
+			"Recurring absences not correctly specified",
 			"", nil))
 		return
 	}
@@ -240,12 +288,10 @@ func addAbsenceRecurring(w http.ResponseWriter, r *http.Request) {
 		))
 		return
 	}
-	for _, ab := range absences {
-		if ab < 0 || ab > 6 {
-			continue
-		}
-		absencePerson = append(absencePerson,
-			&dbModel.PersonRecurringAbsence{PersonID: uint(*id), Weekday: ab})
+	for i := range absences {
+		absences[i].ID = 0
+		absences[i].PersonID = uint(*id)
+		absencePerson = append(absencePerson, &absences[i])
 	}
 
 	db := database.DB.Begin()
@@ -255,6 +301,8 @@ func addAbsenceRecurring(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		db.Rollback()
 		switch err {
+		case errors.ErrInvalidRecurrenceRule, errors.ErrRecurringAbsenceExists:
+			apihelper.ResponseBadRequest(w, apimodel.Result{Result: "recurring absence not valid", Error: err.Error()}, err)
 		case gorm.ErrEmptySlice, gorm.ErrInvalidData, gorm.ErrRecordNotFound:
 			w.WriteHeader(http.StatusBadRequest)
 		default:
@@ -266,17 +314,19 @@ func addAbsenceRecurring(w http.ResponseWriter, r *http.Request) {
 }
 
 // @Summary		Delete Absence
-// @Description	Delete absence of person in period
+// @Description	Delete recurring absence of person, identified by ID or rule. Numbers are read as weekdays, 0 = Sunday
+// @Description	At /me the absence of the person linked to the user is used
 // @Tags			Person,Absence
 // @Accept			json
 // @Produce		json
-// @Param			PersonId	path	int		true	"ID of person"
-// @Param			Absence		body	[]uint	true	"Weekday where person is no longer absent"
+// @Param			PersonId	path	int								true	"ID of person"
+// @Param			Absence		body	apimodel.RecurringAbsenceRequest	true	"Recurring absences or weekdays where person is no longer absent"
 // @Security		ApiKeyAuth
 // @Success		200
 // @Failure		400
 // @Failure		401
 // @Router			/person/{PersonId}/absencerecurring [DELETE]
+// @Router			/me/absencerecurring [DELETE]
 func deleteAbsenceRecurring(w http.ResponseWriter, r *http.Request) {
 	const funcName = packageName + ".deleteAbsenceRecurring"
 
@@ -288,24 +338,22 @@ func deleteAbsenceRecurring(w http.ResponseWriter, r *http.Request) {
 			"", nil))
 		return
 	}
-	var absences []int
+	var absences apimodel.RecurringAbsenceRequest
 	if err := json.NewDecoder(r.Body).Decode(&absences); err != nil {
-		apihelper.ResponseBadRequest(w, apimodel.Result{Result: "meetings id not valid"}, err)
+		apihelper.ResponseBadRequest(w, apimodel.Result{Result: "recurring absences not valid"}, err)
 		return
 	}
-	var absencePerson []dbModel.PersonRecurringAbsence
-	for _, ab := range absences {
-		absencePerson = append(absencePerson,
-			dbModel.PersonRecurringAbsence{PersonID: uint(*idPerson), Weekday: ab})
+	for i := range absences {
+		absences[i].PersonID = uint(*idPerson)
 	}
 
 	db := database.DB.Begin()
 	defer db.Commit()
-	err = absence.DeleteRecurringAbsence(absencePerson, db)
+	err = absence.DeleteRecurringAbsence(absences, db)
 	if err != nil {
 		db.Rollback()
 		switch err {
-		case gorm.ErrEmptySlice, gorm.ErrInvalidData, gorm.ErrRecordNotFound:
+		case gorm.ErrEmptySlice, gorm.ErrInvalidData, gorm.ErrRecordNotFound, errors.ErrIDNotSet, errors.ErrInvalidRecurrenceRule:
 			w.WriteHeader(http.StatusBadRequest)
 		default:
 			apihelper.InternalError(w, err)
@@ -3,7 +3,9 @@ package absenceperson
 import (
 	"encoding/json"
 	"fmt"
+	"mpt_data/api/middleware"
 	"mpt_data/database"
+	"mpt_data/database/auth"
 	"mpt_data/helper/config"
 	apiModel "mpt_data/models/apimodel"
 	dbModel "mpt_data/models/dbmodel"
@@ -81,6 +83,57 @@ func TestAddAbsence(t *testing.T) {
 	}
 }
 
+func TestAddAbsencePeriod(t *testing.T) {
+	// Prepare
+	route := fmt.Sprintf("/api/v1/person/%d/absence", absenceT.PersonID)
+	var testcases = []struct {
+		name   string
+		data   interface{}
+		status int
+	}{
+		{
+			"succesfull",
+			apiModel.AbsenceRequest{Periods: []dbModel.PersonAbsencePeriod{{
+				From: time.Date(2006, 7, 1, 0, 0, 0, 0, time.UTC),
+				To:   time.Date(2006, 7, 21, 0, 0, 0, 0, time.UTC),
+				Note: "vacation",
+			}}},
+			http.StatusCreated,
+		},
+		{
+			"to before from",
+			apiModel.AbsenceRequest{Periods: []dbModel.PersonAbsencePeriod{{
+				From: time.Date(2006, 7, 21, 0, 0, 0, 0, time.UTC),
+				To:   time.Date(2006, 7, 1, 0, 0, 0, 0, time.UTC),
+			}}},
+			http.StatusBadRequest,
+		},
+		{
+			"empty",
+			apiModel.AbsenceRequest{},
+			http.StatusBadRequest,
+		},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			// Act
+			response := api_test.DoRequest(t, api_test.RequestData{
+				Data:   testcase.data,
+				Route:  route,
+				Method: http.MethodPost,
+				Router: addAbsence,
+				Path:   apiModel.PersonAbsence,
+			})
+			// Assert
+			if status := response.Code; status != testcase.status {
+				t.Errorf("expected status code %d, got %d", testcase.status, status)
+				t.Log(response.Body)
+			}
+		})
+	}
+}
+
 func TestGetAbsences(t *testing.T) {
 	// Prepare
 	var meeting dbModel.PersonAbsence
@@ -95,7 +148,7 @@ func TestGetAbsences(t *testing.T) {
 	var testcases = []struct {
 		name     string
 		data     api_test.RequestData
-		response []dbModel.Meeting
+		response apiModel.PersonAbsences
 		err      error
 	}{
 		{
@@ -111,7 +164,7 @@ func TestGetAbsences(t *testing.T) {
 				Router: getAbsence,
 				Path:   apiModel.PersonAbsence,
 			},
-			[]dbModel.Meeting{ab},
+			apiModel.PersonAbsences{Meetings: []dbModel.Meeting{ab}, Periods: []dbModel.PersonAbsencePeriod{}},
 			nil,
 		},
 	}
@@ -172,3 +225,119 @@ func TestDeleteAbsence(t *testing.T) {
 		})
 	}
 }
+
+func TestMeAbsence(t *testing.T) {
+	// Prepare
+	person := dbModel.Person{GivenName: "Anna", LastName: "Self"}
+	if err := database.DB.Create(&person).Error; err != nil {
+		t.Fatalf("test preparation failed: %v", err)
+	}
+	self, err := auth.RegisterUser(database.DB, apiModel.User{Username: "anna.self", Password: "secret", Role: dbModel.RoleSelf, PersonID: &person.ID})
+	if err != nil {
+		t.Fatalf("test preparation failed: %v", err)
+	}
+	unlinked, err := auth.RegisterUser(database.DB, apiModel.User{Username: "admin.self", Password: "secret", Role: dbModel.RoleAdmin})
+	if err != nil {
+		t.Fatalf("test preparation failed: %v", err)
+	}
+	t.Cleanup(func() {
+		database.DB.Unscoped().Delete(&dbModel.User{}, []uint{self.ID, unlinked.ID})
+		database.DB.Unscoped().Delete(&person)
+	})
+	selfToken, err := auth.Login(apiModel.UserLogin{Username: "anna.self", Password: "secret"})
+	if err != nil {
+		t.Fatalf("test preparation failed: %v", err)
+	}
+	unlinkedToken, err := auth.Login(apiModel.UserLogin{Username: "admin.self", Password: "secret"})
+	if err != nil {
+		t.Fatalf("test preparation failed: %v", err)
+	}
+	query := fmt.Sprintf("?StartDate=%s&EndDate=%s", time.Now().AddDate(0, 0, -1).Format(time.DateOnly), time.Now().AddDate(0, 0, 1).Format(time.DateOnly))
+
+	var testcases = []struct {
+		name       string
+		data       api_test.RequestData
+		statusCode int
+	}{
+		{
+			"own absence",
+			api_test.RequestData{
+				Route:  apiModel.MeAbsenceHref + query,
+				Method: http.MethodGet,
+				Router: middleware.CheckSelf(getAbsence),
+				Path:   apiModel.MeAbsenceHref,
+				Header: map[string]string{"Authorization": "Bearer " + selfToken},
+			},
+			http.StatusOK,
+		},
+		{
+			"not linked",
+			api_test.RequestData{
+				Route:  apiModel.MeAbsenceHref + query,
+				Method: http.MethodGet,
+				Router: middleware.CheckSelf(getAbsence),
+				Path:   apiModel.MeAbsenceHref,
+				Header: map[string]string{"Authorization": "Bearer " + unlinkedToken},
+			},
+			http.StatusForbidden,
+		},
+		{
+			"without token",
+			api_test.RequestData{
+				Route:  apiModel.MeAbsenceHref + query,
+				Method: http.MethodGet,
+				Router: middleware.CheckSelf(getAbsence),
+				Path:   apiModel.MeAbsenceHref,
+			},
+			http.StatusUnauthorized,
+		},
+		{
+			"other person",
+			api_test.RequestData{
+				Route:  fmt.Sprintf("/api/v1/person/%d/absence%s", absenceT.PersonID, query),
+				Method: http.MethodGet,
+				Router: middleware.CheckAuthentication(getAbsence),
+				Path:   apiModel.PersonAbsence,
+				Header: map[string]string{"Authorization": "Bearer " + selfToken},
+			},
+			http.StatusForbidden,
+		},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			// Act
+			response := api_test.DoRequest(t, testcase.data)
+			// Assert
+			if response.Code != testcase.statusCode {
+				t.Errorf("expected status code %d, got %d", testcase.statusCode, response.Code)
+				t.Log(response.Body)
+			}
+		})
+	}
+
+	t.Run("scoped to own person", func(t *testing.T) {
+		// Act
+		countBefore := database_test.CountEntries(&dbModel.PersonAbsence{})
+		response := api_test.DoRequest(t, api_test.RequestData{
+			Data:   []uint{meetingT.ID},
+			Route:  apiModel.MeAbsenceHref,
+			Method: http.MethodPost,
+			Router: middleware.CheckSelf(addAbsence),
+			Path:   apiModel.MeAbsenceHref,
+			Header: map[string]string{"Authorization": "Bearer " + selfToken},
+		})
+		t.Cleanup(func() {
+			database.DB.Unscoped().Where("person_id = ?", person.ID).Delete(&dbModel.PersonAbsence{})
+		})
+		// Assert
+		if response.Code != http.StatusCreated {
+			t.Fatalf("expected status code %d, got %d", http.StatusCreated, response.Code)
+		}
+		var count int64
+		database.DB.Model(&dbModel.PersonAbsence{}).Where("person_id = ?", person.ID).Count(&count)
+		if count != 1 || database_test.CountEntries(&dbModel.PersonAbsence{})-countBefore != 1 {
+			t.Errorf("expected 1 absence of person %d, got %d", person.ID, count)
+		}
+	})
+}
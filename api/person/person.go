@@ -10,6 +10,7 @@ import (
 	apiModel "mpt_data/models/apimodel"
 	dbModel "mpt_data/models/dbmodel"
 	"net/http"
+	"time"
 
 	"github.com/gorilla/mux"
 )
@@ -28,33 +29,68 @@ func RegisterRoutes(mux *mux.Router) {
 	mux.HandleFunc(apiModel.PersonHrefTask, middleware.CheckAuthentication(getTaskForPerson)).Methods(http.MethodGet)
 	mux.HandleFunc(apiModel.PersonHrefTask, middleware.CheckAuthentication(addTaskToPerson)).Methods(http.MethodPost)
 	mux.HandleFunc(apiModel.PersonHrefTask, middleware.CheckAuthentication(deleteTaskFromPerson)).Methods(http.MethodDelete)
+	mux.HandleFunc(apiModel.PersonHrefTask, middleware.CheckAuthentication(updateTaskLimitOfPerson)).Methods(http.MethodPut)
+	mux.HandleFunc(apiModel.MeTasksHref, middleware.CheckSelf(getTaskForPerson)).Methods(http.MethodGet)
+
+	// relation.go
+	mux.HandleFunc(apiModel.PersonHrefRelation, middleware.CheckAuthentication(getRelationOfPerson)).Methods(http.MethodGet)
+	mux.HandleFunc(apiModel.PersonHrefRelation, middleware.CheckAuthentication(addRelationToPerson)).Methods(http.MethodPost)
+	mux.HandleFunc(apiModel.PersonHrefRelationWithID, middleware.CheckAuthentication(updateRelationOfPerson)).Methods(http.MethodPut)
+	mux.HandleFunc(apiModel.PersonHrefRelationWithID, middleware.CheckAuthentication(deleteRelationFromPerson)).Methods(http.MethodDelete)
+
+	// preference.go
+	mux.HandleFunc(apiModel.PersonHrefPreference, middleware.CheckAuthentication(getPreferenceOfPerson)).Methods(http.MethodGet)
+	mux.HandleFunc(apiModel.PersonHrefPreference, middleware.CheckAuthentication(addPreferenceToPerson)).Methods(http.MethodPost)
+	mux.HandleFunc(apiModel.PersonHrefPreferenceWithID, middleware.CheckAuthentication(updatePreferenceOfPerson)).Methods(http.MethodPut)
+	mux.HandleFunc(apiModel.PersonHrefPreferenceWithID, middleware.CheckAuthentication(deletePreferenceFromPerson)).Methods(http.MethodDelete)
 }
 
 // @Summary		Get Person
 // @Description	Get all Persons
+// @Description	Email, Phone and Notes are only returned to the roles of the config API.ContactRoles
+// @Description	DisplayName tells people with the same name apart, formatted by the config Names.Duplicate
 // @Tags			Person
 // @Accept			json
 // @Produce		json
+// @Param			status	query	string	false	"Status of the people today, all people if not set"	Enums(active, inactive)
+// @Param			q		query	string	false	"Names to search, every word is a prefix of a word of the names"
+// @Param			exact	query	bool	false	"q is the given name, the last name or the full name"
 // @Security		ApiKeyAuth
 // @Success		200	{array}	dbModel.Person
-// @Failure		400
+// @Failure		400	{object}	apiModel.Result
 // @Failure		401
 // @Router			/person [GET]
 func getPerson(w http.ResponseWriter, r *http.Request) {
 	const funcName = packageName + ".getPerson"
 
 	tx := middleware.GetTx(r.Context())
-	persons, err := person.GetPerson(tx)
+	queryParams := r.URL.Query()
+	filter := apiModel.PersonFilter{
+		Status: queryParams.Get("status"),
+		Query:  queryParams.Get("q"),
+		Exact:  queryParams.Get("exact") == "true",
+	}
+	persons, err := person.GetPersonWithFilter(tx, filter, time.Now())
 
-	if err != nil {
+	switch err {
+	case nil:
+	case errors.ErrInvalidPersonStatus:
+		apihelper.ResponseBadRequest(w, apiModel.Result{Result: "failed to load people", Error: err.Error()}, err)
+		return
+	default:
 		apihelper.InternalError(w, err)
 		return
 	}
+	showContact := middleware.ContactVisible(r.Context())
+	for i := range persons {
+		persons[i].ShowContact(showContact)
+	}
 	apihelper.ResponseJSON(w, persons)
 }
 
 // @Summary		Add Person
 // @Description	Add Person
+// @Description	Email, Phone and Notes are ignored for roles, which may not see contact data
 // @Tags			Person
 // @Accept			json
 // @Produce		json
@@ -76,13 +112,19 @@ func addPerson(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	showContact := middleware.ContactVisible(r.Context())
+	if !showContact {
+		personIn.Email, personIn.Phone, personIn.Notes = "", "", ""
+	}
+
 	tx := middleware.GetTx(r.Context())
 
 	err := person.AddPerson(tx, &personIn)
 	switch err {
 	case nil:
+		personIn.ShowContact(showContact)
 		apihelper.ResponseJSON(w, personIn, http.StatusCreated)
-	case errors.ErrPersonMissingName:
+	case errors.ErrPersonMissingName, errors.ErrPersonInvalidEmail, errors.ErrPersonInvalidPhone, errors.ErrPersonInvalidActivity, errors.ErrPersonInvalidBirthYear:
 		apihelper.ResponseBadRequest(
 			w, apiModel.Result{
 				Result: "failed to store data",
@@ -102,6 +144,7 @@ func addPerson(w http.ResponseWriter, r *http.Request) {
 //
 //	@Summary		Delete Person
 //	@Description	Delete one person with its details
+//	@Description	People assigned in a plan are not deleted, set Active to false instead to keep the history
 //	@Tags			Person
 //	@Accept			json
 //	@Produce		json
@@ -129,7 +172,16 @@ func deletePerson(w http.ResponseWriter, r *http.Request) {
 
 	tx := middleware.GetTx(r.Context())
 
-	if err := person.DeletePerson(tx, persons); err != nil {
+	switch err := person.DeletePerson(tx, persons); err {
+	case nil:
+	case errors.ErrPersonInPlan:
+		apihelper.ResponseBadRequest(w,
+			apiModel.Result{
+				Result: "failed to delete person",
+				Error:  err.Error(),
+			}, err)
+		return
+	default:
 		apihelper.ResponseJSON(
 			w, apiModel.Result{
 				Result: "failed to delete person",
@@ -146,6 +198,7 @@ func deletePerson(w http.ResponseWriter, r *http.Request) {
 //
 //	@Summary		Update Person
 //	@Description	Update a person
+//	@Description	Email, Phone and Notes are kept for roles, which may not see contact data
 //	@Tags			Person
 //	@Accept			json
 //	@Produce		json
@@ -177,11 +230,22 @@ func updatePerson(w http.ResponseWriter, r *http.Request) {
 
 	tx := middleware.GetTx(r.Context())
 
+	showContact := middleware.ContactVisible(r.Context())
+	if !showContact {
+		stored, err := person.GetPersonWithID(tx, personIn.ID)
+		if err != nil {
+			apihelper.ResponseBadRequest(w, apiModel.Result{Result: "person not updated", Error: "id not valid"}, err)
+			return
+		}
+		personIn.Email, personIn.Phone, personIn.Notes = stored.Email, stored.Phone, stored.Notes
+	}
+
 	err = person.UpdatePerson(tx, &personIn)
 	switch err {
 	case nil:
+		personIn.ShowContact(showContact)
 		apihelper.ResponseJSON(w, personIn, http.StatusOK)
-	case errors.ErrPersonMissingName:
+	case errors.ErrPersonMissingName, errors.ErrPersonInvalidEmail, errors.ErrPersonInvalidPhone, errors.ErrPersonInvalidActivity, errors.ErrPersonInvalidBirthYear:
 		apihelper.ResponseBadRequest(
 			w, apiModel.Result{
 				Result: "failed to store data",
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"mpt_data/database"
 	"mpt_data/database/person"
+	"mpt_data/helper/config"
 	"mpt_data/helper/errors"
 	"mpt_data/models/apimodel"
 	apiModel "mpt_data/models/apimodel"
@@ -321,3 +322,51 @@ func TestUpdatePerson(t *testing.T) {
 		})
 	}
 }
+
+func TestPersonContactVisible(t *testing.T) {
+	// Prepare
+	contact := dbModel.Person{GivenName: "Max", LastName: "Kontakt", Email: "max@example.org", Phone: "0123 456789", Notes: "key holder"}
+	if err := database.DB.Create(&contact).Error; err != nil {
+		t.Fatalf("test preparation failed: %v", err)
+	}
+	t.Cleanup(func() {
+		database.DB.Unscoped().Delete(&contact)
+		config.Config.API.ContactRoles = nil
+	})
+
+	var testcases = []struct {
+		name     string
+		roles    []string
+		expected string
+	}{
+		{"default admin", nil, contact.Email},
+		{"admin configured", []string{dbModel.RoleAdmin, dbModel.RoleSelf}, contact.Email},
+		{"admin not configured", []string{dbModel.RoleSelf}, ""},
+	}
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			// Prepare
+			config.Config.API.ContactRoles = testcase.roles
+			// Act
+			response := api_test.DoRequest(t, api_test.RequestData{
+				Route:  apiModel.PersonHref,
+				Method: http.MethodGet,
+				Router: getPerson,
+				Path:   apiModel.PersonHref,
+			})
+			// Assert
+			if response.Code != http.StatusOK {
+				t.Fatalf("expected %d, got %d", http.StatusOK, response.Code)
+			}
+			var people []dbModel.Person
+			if err := json.NewDecoder(response.Body).Decode(&people); err != nil {
+				t.Fatalf("decoding result failed: %v", err)
+			}
+			for _, person := range people {
+				if person.ID == contact.ID && person.Email != testcase.expected {
+					t.Errorf("expected email %q, got %q", testcase.expected, person.Email)
+				}
+			}
+		})
+	}
+}
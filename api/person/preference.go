@@ -0,0 +1,167 @@
+package person
+
+import (
+	"encoding/json"
+	"mpt_data/api/apihelper"
+	"mpt_data/api/middleware"
+	"mpt_data/database/person"
+	"mpt_data/helper/errors"
+	apiModel "mpt_data/models/apimodel"
+	dbModel "mpt_data/models/dbmodel"
+	"net/http"
+
+	"gorm.io/gorm"
+)
+
+// getPreferenceOfPerson loads all preferences of a person
+//
+//	@Summary		Get Persons Preferences
+//	@Description	Get all preferences of a person for tasks and weekdays
+//	@Tags			Person
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path	int	true	"ID of Person"
+//	@Security		ApiKeyAuth
+//	@Success		200 {array} dbModel.PersonPreference
+//	@Failure		400
+//	@Failure		401
+//	@Router			/person/{id}/preference [GET]
+func getPreferenceOfPerson(w http.ResponseWriter, r *http.Request) {
+	const funcName = packageName + ".getPreferenceOfPerson"
+
+	id, err := apihelper.ExtractIntFromURL(r, "id")
+	if err != nil || id <= 0 {
+		apihelper.ResponseBadRequest(w, apiModel.Result{Result: "id not valid"}, err)
+		return
+	}
+
+	tx := middleware.GetTx(r.Context())
+	preferences, err := person.GetPreferenceOfPerson(tx, uint(id))
+	if err != nil {
+		apihelper.InternalError(w, err)
+		return
+	}
+	apihelper.ResponseJSON(w, preferences)
+}
+
+// addPreferenceToPerson adds a preference for a task or a weekday to a person
+//
+//	@Summary		Add Preference to Person
+//	@Description	Add a soft wish for a TaskDetail or a weekday, which changes the order of available people when a plan is created.
+//	@Description	Either TaskDetailID or Weekday (0 for sunday to 6 for saturday) must be set. Weight is one of prefers, neutral or dislikes
+//	@Tags			Person
+//	@Accept			json
+//	@Produce		json
+//	@Param			id			path	int							true	"ID of Person"
+//	@Param			Preference	body	dbModel.PersonPreference	true	"TaskDetailID or Weekday and Weight of the preference"
+//	@Security		ApiKeyAuth
+//	@Success		201 {object} dbModel.PersonPreference
+//	@Failure		400	{object}	apiModel.Result
+//	@Failure		401
+//	@Router			/person/{id}/preference [POST]
+func addPreferenceToPerson(w http.ResponseWriter, r *http.Request) {
+	const funcName = packageName + ".addPreferenceToPerson"
+
+	id, err := apihelper.ExtractIntFromURL(r, "id")
+	if err != nil || id <= 0 {
+		apihelper.ResponseBadRequest(w, apiModel.Result{Result: "id not valid"}, err)
+		return
+	}
+
+	var preference dbModel.PersonPreference
+	if err := json.NewDecoder(r.Body).Decode(&preference); err != nil {
+		apihelper.ResponseBadRequest(w, apiModel.Result{Result: "failed to decode request body"}, err)
+		return
+	}
+	preference.ID = 0
+	preference.PersonID = uint(id)
+
+	tx := middleware.GetTx(r.Context())
+	preference, err = person.AddPreferenceToPerson(tx, preference)
+	switch err {
+	case nil:
+		apihelper.ResponseJSON(w, preference, http.StatusCreated)
+	case errors.ErrIDNotSet, errors.ErrInvalidPreference, errors.ErrPreferenceExists, gorm.ErrRecordNotFound:
+		apihelper.ResponseBadRequest(w, apiModel.Result{Result: "preference not created", Error: err.Error()}, err)
+	default:
+		apihelper.InternalError(w, err)
+	}
+}
+
+// updatePreferenceOfPerson changes the weight of a preference
+//
+//	@Summary		Update Persons Preference
+//	@Description	Change the weight of a preference of a person
+//	@Tags			Person
+//	@Accept			json
+//	@Produce		json
+//	@Param			id				path	int							true	"ID of Person"
+//	@Param			preferenceId	path	int							true	"ID of Preference"
+//	@Param			Preference		body	dbModel.PersonPreference	true	"Weight of the preference"
+//	@Security		ApiKeyAuth
+//	@Success		200 {object} dbModel.PersonPreference
+//	@Failure		400	{object}	apiModel.Result
+//	@Failure		401
+//	@Router			/person/{id}/preference/{preferenceId} [PUT]
+func updatePreferenceOfPerson(w http.ResponseWriter, r *http.Request) {
+	const funcName = packageName + ".updatePreferenceOfPerson"
+
+	id, err := apihelper.ExtractIntFromURL(r, "id")
+	preferenceID, err2 := apihelper.ExtractIntFromURL(r, "preferenceId")
+	if err != nil || err2 != nil || id <= 0 || preferenceID <= 0 {
+		apihelper.ResponseBadRequest(w, apiModel.Result{Result: "id not valid"}, err)
+		return
+	}
+
+	var preferenceIn dbModel.PersonPreference
+	if err := json.NewDecoder(r.Body).Decode(&preferenceIn); err != nil {
+		apihelper.ResponseBadRequest(w, apiModel.Result{Result: "failed to decode request body"}, err)
+		return
+	}
+
+	tx := middleware.GetTx(r.Context())
+	preference, err := person.UpdatePreferenceOfPerson(tx, uint(id), uint(preferenceID), preferenceIn.Weight)
+	switch err {
+	case nil:
+		apihelper.ResponseJSON(w, preference)
+	case errors.ErrIDNotSet, errors.ErrInvalidPreference, gorm.ErrRecordNotFound:
+		apihelper.ResponseBadRequest(w, apiModel.Result{Result: "preference not updated", Error: err.Error()}, err)
+	default:
+		apihelper.InternalError(w, err)
+	}
+}
+
+// deletePreferenceFromPerson deletes a preference of a person
+//
+//	@Summary		Delete Persons Preference
+//	@Description	Delete a preference of a person
+//	@Tags			Person
+//	@Accept			json
+//	@Produce		json
+//	@Param			id				path	int	true	"ID of Person"
+//	@Param			preferenceId	path	int	true	"ID of Preference"
+//	@Security		ApiKeyAuth
+//	@Success		200
+//	@Failure		400	{object}	apiModel.Result
+//	@Failure		401
+//	@Router			/person/{id}/preference/{preferenceId} [DELETE]
+func deletePreferenceFromPerson(w http.ResponseWriter, r *http.Request) {
+	const funcName = packageName + ".deletePreferenceFromPerson"
+
+	id, err := apihelper.ExtractIntFromURL(r, "id")
+	preferenceID, err2 := apihelper.ExtractIntFromURL(r, "preferenceId")
+	if err != nil || err2 != nil || id <= 0 || preferenceID <= 0 {
+		apihelper.ResponseBadRequest(w, apiModel.Result{Result: "id not valid"}, err)
+		return
+	}
+
+	tx := middleware.GetTx(r.Context())
+	switch err := person.DeletePreferenceFromPerson(tx, uint(id), uint(preferenceID)); err {
+	case nil:
+		w.WriteHeader(http.StatusOK)
+	case errors.ErrIDNotSet, gorm.ErrRecordNotFound:
+		apihelper.ResponseBadRequest(w, apiModel.Result{Result: "preference not deleted", Error: err.Error()}, err)
+	default:
+		apihelper.InternalError(w, err)
+	}
+}
@@ -0,0 +1,167 @@
+package person
+
+import (
+	"encoding/json"
+	"mpt_data/api/apihelper"
+	"mpt_data/api/middleware"
+	"mpt_data/database/person"
+	"mpt_data/helper/errors"
+	apiModel "mpt_data/models/apimodel"
+	dbModel "mpt_data/models/dbmodel"
+	"net/http"
+
+	"gorm.io/gorm"
+)
+
+// getRelationOfPerson loads all relations of a person
+//
+//	@Summary		Get Persons Relations
+//	@Description	Get all relations, where the person is on either side
+//	@Tags			Person
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path	int	true	"ID of Person"
+//	@Security		ApiKeyAuth
+//	@Success		200 {array} dbModel.PersonRelation
+//	@Failure		400
+//	@Failure		401
+//	@Router			/person/{id}/relation [GET]
+func getRelationOfPerson(w http.ResponseWriter, r *http.Request) {
+	const funcName = packageName + ".getRelationOfPerson"
+
+	id, err := apihelper.ExtractIntFromURL(r, "id")
+	if err != nil || id <= 0 {
+		apihelper.ResponseBadRequest(w, apiModel.Result{Result: "id not valid"}, err)
+		return
+	}
+
+	tx := middleware.GetTx(r.Context())
+	relations, err := person.GetRelationOfPerson(tx, uint(id))
+	if err != nil {
+		apihelper.InternalError(w, err)
+		return
+	}
+	apihelper.ResponseJSON(w, relations)
+}
+
+// addRelationToPerson adds a relation from a person to another person
+//
+//	@Summary		Add Relation to Person
+//	@Description	Add a rule between two people, which is enforced when people are assigned to a plan.
+//	@Description	Type is one of together, apart or requires. requires only applies from the person to the related person
+//	@Tags			Person
+//	@Accept			json
+//	@Produce		json
+//	@Param			id			path	int						true	"ID of Person"
+//	@Param			Relation	body	dbModel.PersonRelation	true	"RelatedPersonID and Type of the relation"
+//	@Security		ApiKeyAuth
+//	@Success		201 {object} dbModel.PersonRelation
+//	@Failure		400	{object}	apiModel.Result
+//	@Failure		401
+//	@Router			/person/{id}/relation [POST]
+func addRelationToPerson(w http.ResponseWriter, r *http.Request) {
+	const funcName = packageName + ".addRelationToPerson"
+
+	id, err := apihelper.ExtractIntFromURL(r, "id")
+	if err != nil || id <= 0 {
+		apihelper.ResponseBadRequest(w, apiModel.Result{Result: "id not valid"}, err)
+		return
+	}
+
+	var relation dbModel.PersonRelation
+	if err := json.NewDecoder(r.Body).Decode(&relation); err != nil {
+		apihelper.ResponseBadRequest(w, apiModel.Result{Result: "failed to decode request body"}, err)
+		return
+	}
+	relation.ID = 0
+	relation.PersonID = uint(id)
+
+	tx := middleware.GetTx(r.Context())
+	relation, err = person.AddRelationToPerson(tx, relation)
+	switch err {
+	case nil:
+		apihelper.ResponseJSON(w, relation, http.StatusCreated)
+	case errors.ErrIDNotSet, errors.ErrInvalidRelation:
+		apihelper.ResponseBadRequest(w, apiModel.Result{Result: "relation not created", Error: err.Error()}, err)
+	default:
+		apihelper.InternalError(w, err)
+	}
+}
+
+// updateRelationOfPerson changes the type of a relation
+//
+//	@Summary		Update Persons Relation
+//	@Description	Change the type of a relation of a person
+//	@Tags			Person
+//	@Accept			json
+//	@Produce		json
+//	@Param			id			path	int						true	"ID of Person"
+//	@Param			relationId	path	int						true	"ID of Relation"
+//	@Param			Relation	body	dbModel.PersonRelation	true	"Type of the relation"
+//	@Security		ApiKeyAuth
+//	@Success		200 {object} dbModel.PersonRelation
+//	@Failure		400	{object}	apiModel.Result
+//	@Failure		401
+//	@Router			/person/{id}/relation/{relationId} [PUT]
+func updateRelationOfPerson(w http.ResponseWriter, r *http.Request) {
+	const funcName = packageName + ".updateRelationOfPerson"
+
+	id, err := apihelper.ExtractIntFromURL(r, "id")
+	relationID, err2 := apihelper.ExtractIntFromURL(r, "relationId")
+	if err != nil || err2 != nil || id <= 0 || relationID <= 0 {
+		apihelper.ResponseBadRequest(w, apiModel.Result{Result: "id not valid"}, err)
+		return
+	}
+
+	var relationIn dbModel.PersonRelation
+	if err := json.NewDecoder(r.Body).Decode(&relationIn); err != nil {
+		apihelper.ResponseBadRequest(w, apiModel.Result{Result: "failed to decode request body"}, err)
+		return
+	}
+
+	tx := middleware.GetTx(r.Context())
+	relation, err := person.UpdateRelationOfPerson(tx, uint(id), uint(relationID), relationIn.Type)
+	switch err {
+	case nil:
+		apihelper.ResponseJSON(w, relation)
+	case errors.ErrIDNotSet, errors.ErrInvalidRelation, gorm.ErrRecordNotFound:
+		apihelper.ResponseBadRequest(w, apiModel.Result{Result: "relation not updated", Error: err.Error()}, err)
+	default:
+		apihelper.InternalError(w, err)
+	}
+}
+
+// deleteRelationFromPerson deletes a relation of a person
+//
+//	@Summary		Delete Persons Relation
+//	@Description	Delete a relation of a person
+//	@Tags			Person
+//	@Accept			json
+//	@Produce		json
+//	@Param			id			path	int	true	"ID of Person"
+//	@Param			relationId	path	int	true	"ID of Relation"
+//	@Security		ApiKeyAuth
+//	@Success		200
+//	@Failure		400	{object}	apiModel.Result
+//	@Failure		401
+//	@Router			/person/{id}/relation/{relationId} [DELETE]
+func deleteRelationFromPerson(w http.ResponseWriter, r *http.Request) {
+	const funcName = packageName + ".deleteRelationFromPerson"
+
+	id, err := apihelper.ExtractIntFromURL(r, "id")
+	relationID, err2 := apihelper.ExtractIntFromURL(r, "relationId")
+	if err != nil || err2 != nil || id <= 0 || relationID <= 0 {
+		apihelper.ResponseBadRequest(w, apiModel.Result{Result: "id not valid"}, err)
+		return
+	}
+
+	tx := middleware.GetTx(r.Context())
+	switch err := person.DeleteRelationFromPerson(tx, uint(id), uint(relationID)); err {
+	case nil:
+		w.WriteHeader(http.StatusOK)
+	case errors.ErrIDNotSet, gorm.ErrRecordNotFound:
+		apihelper.ResponseBadRequest(w, apiModel.Result{Result: "relation not deleted", Error: err.Error()}, err)
+	default:
+		apihelper.InternalError(w, err)
+	}
+}
@@ -3,7 +3,9 @@ package person
 import (
 	"encoding/json"
 	"mpt_data/api/apihelper"
+	"mpt_data/api/middleware"
 	"mpt_data/database/person"
+	"mpt_data/helper/errors"
 	apiModel "mpt_data/models/apimodel"
 	dbModel "mpt_data/models/dbmodel"
 	"net/http"
@@ -12,6 +14,7 @@ import (
 // @Summary		Get Person-Task
 // @Description	Get Tasks of Person(s)
 // @Description	ID of Person must always be set, 0 to load all persons with their tasks
+// @Description	At /me/tasks the tasks of the person linked to the user are loaded
 // @Tags			Person,Task
 // @Accept			json
 // @Produce		json
@@ -22,6 +25,7 @@ import (
 // @Failure		401
 // @Failure		500	"eg. loading failed due to any error"
 // @Router			/person/{id}/task [GET]
+// @Router			/me/tasks [GET]
 func getTaskForPerson(w http.ResponseWriter, r *http.Request) {
 	const funcName = packageName + ".getTaskForPerson"
 	var (
@@ -150,3 +154,48 @@ func deleteTaskFromPerson(w http.ResponseWriter, r *http.Request) {
 
 	w.WriteHeader(http.StatusOK)
 }
+
+// updateTaskLimitOfPerson sets the maximum number of assignments per period for tasks of a person
+//
+//	@Summary		Update Persons Task Limits
+//	@Description	Set the maximum number of assignments per planning period for tasks of a person, 0 for unlimited
+//	@Tags			Person,Task
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path	int							true	"ID of Person"
+//	@Param			Limits	body	[]apiModel.PersonTaskLimit	true	"Maximum assignments per Task-Detail"
+//	@Security		ApiKeyAuth
+//	@Success		200 {array} dbModel.PersonTask
+//	@Failure		400	{object}	apiModel.Result
+//	@Failure		401
+//	@Router			/person/{id}/task [PUT]
+func updateTaskLimitOfPerson(w http.ResponseWriter, r *http.Request) {
+	const funcName = packageName + ".updateTaskLimitOfPerson"
+
+	id, err := apihelper.ExtractIntFromURL(r, "id")
+	if err != nil {
+		apihelper.ResponseJSON(w, apiModel.Result{Result: "invalid ID: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	if id <= 0 {
+		apihelper.ResponseJSON(w, apiModel.Result{Result: "ID must be greater than 0"}, http.StatusBadRequest)
+		return
+	}
+
+	var limits []apiModel.PersonTaskLimit
+	if err := json.NewDecoder(r.Body).Decode(&limits); err != nil {
+		apihelper.ResponseBadRequest(w, apiModel.Result{Result: "failed to decode request body"}, err)
+		return
+	}
+
+	tx := middleware.GetTx(r.Context())
+	personTasks, err := person.UpdateTaskLimitOfPerson(tx, uint(id), limits)
+	switch err {
+	case nil:
+		apihelper.ResponseJSON(w, personTasks)
+	case errors.ErrIDNotSet, errors.ErrTaskForPersonNotAllowed:
+		apihelper.ResponseBadRequest(w, apiModel.Result{Result: "failed to update limits", Error: err.Error()}, err)
+	default:
+		apihelper.InternalError(w, err)
+	}
+}
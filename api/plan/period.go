@@ -0,0 +1,147 @@
+package plan
+
+import (
+	"encoding/json"
+	"mpt_data/api/apihelper"
+	"mpt_data/api/middleware"
+	"mpt_data/database/plan"
+	"mpt_data/helper"
+	"mpt_data/helper/errors"
+	apiModel "mpt_data/models/apimodel"
+	dbModel "mpt_data/models/dbmodel"
+	generalmodel "mpt_data/models/general"
+	"net/http"
+
+	"gorm.io/gorm"
+)
+
+// @Summary		Get Plan Periods
+// @Description	Get all plan periods overlapping with a period
+// @Tags			Plan
+// @Accept			json
+// @Produce		json
+// @Param			StartDate	query	string	true	"Start date/timestamp, Either English Date, or RFC3339"	Example("2023-01-21", "2023-01-21T00:00:00+00:00")
+// @Param			EndDate		query	string	true	"End date/timestamp, Either English Date, or RFC3339"	Example("2023-01-21", "2023-01-21T00:00:00+00:00")
+// @Security		ApiKeyAuth
+// @Success		200	{array}		dbModel.PlanPeriod
+// @Failure		400	{object}	apiModel.Result
+// @Failure		401
+// @Router			/plan/period [GET]
+func getPlanPeriods(w http.ResponseWriter, r *http.Request) {
+	queryParams := r.URL.Query()
+
+	startDate, err := helper.ParseTime(queryParams.Get("StartDate"))
+	endDate, err2 := helper.ParseTime(queryParams.Get("EndDate"))
+	if err != nil {
+		apihelper.ResponseBadRequest(w, apiModel.Result{Result: "could not parse StartDate and/or EndDate"}, err)
+		return
+	}
+	if err2 != nil {
+		apihelper.ResponseBadRequest(w, apiModel.Result{Result: "could not parse StartDate and/or EndDate"}, err2)
+		return
+	}
+
+	tx := middleware.GetTx(r.Context())
+	periods, err := plan.GetPlanPeriods(tx, generalmodel.Period{StartDate: startDate, EndDate: endDate})
+	if err != nil {
+		apihelper.InternalError(w, err)
+		return
+	}
+	apihelper.ResponseJSON(w, periods)
+}
+
+// @Summary		Add Plan Period
+// @Description	Add a plan period in state draft, it must not overlap with other plan periods
+// @Tags			Plan
+// @Accept			json
+// @Produce		json
+// @Param			period	body	dbModel.PlanPeriod	true	"StartDate and EndDate of the plan period"
+// @Security		ApiKeyAuth
+// @Success		201	{object}	dbModel.PlanPeriod
+// @Failure		400	{object}	apiModel.Result
+// @Failure		401
+// @Router			/plan/period [POST]
+func addPlanPeriod(w http.ResponseWriter, r *http.Request) {
+	var period dbModel.PlanPeriod
+	if err := json.NewDecoder(r.Body).Decode(&period); err != nil {
+		apihelper.ResponseBadRequest(w, apiModel.Result{Result: "error in request body"}, err)
+		return
+	}
+
+	tx := middleware.GetTx(r.Context())
+	switch err := plan.AddPlanPeriod(tx, &period); err {
+	case nil:
+		apihelper.ResponseJSON(w, period, http.StatusCreated)
+	case errors.ErrInvalidPlanPeriod, errors.ErrPlanPeriodOverlap:
+		apihelper.ResponseBadRequest(w, apiModel.Result{Result: "plan period not created", Error: err.Error()}, err)
+	default:
+		apihelper.InternalError(w, err)
+	}
+}
+
+// @Summary		Update Plan Period
+// @Description	Change the state of a plan period to draft, published or archived
+// @Description	Published and archived plans are only changed if forced, the state of archived plan periods can not be changed
+// @Tags			Plan
+// @Accept			json
+// @Produce		json
+// @Param			id		path	int					true	"ID of plan period"
+// @Param			period	body	dbModel.PlanPeriod	true	"State of the plan period"
+// @Security		ApiKeyAuth
+// @Success		200	{object}	dbModel.PlanPeriod
+// @Failure		400	{object}	apiModel.Result
+// @Failure		401
+// @Router			/plan/period/{id} [PUT]
+func updatePlanPeriod(w http.ResponseWriter, r *http.Request) {
+	id, err := apihelper.ExtractIntFromURL(r, "id")
+	if err != nil || id <= 0 {
+		apihelper.ResponseBadRequest(w, apiModel.Result{Result: "id not correctly set"}, err)
+		return
+	}
+
+	var periodIn dbModel.PlanPeriod
+	if err := json.NewDecoder(r.Body).Decode(&periodIn); err != nil {
+		apihelper.ResponseBadRequest(w, apiModel.Result{Result: "error in request body"}, err)
+		return
+	}
+
+	tx := middleware.GetTx(r.Context())
+	period, err := plan.UpdatePlanPeriodState(tx, uint(id), periodIn.State)
+	switch err {
+	case nil:
+		apihelper.ResponseJSON(w, period)
+	case errors.ErrInvalidPlanPeriod, errors.ErrPlanPeriodArchived, gorm.ErrRecordNotFound:
+		apihelper.ResponseBadRequest(w, apiModel.Result{Result: "plan period not updated", Error: err.Error()}, err)
+	default:
+		apihelper.InternalError(w, err)
+	}
+}
+
+// @Summary		Delete Plan Period
+// @Description	Delete a plan period, the plan itself is not changed
+// @Tags			Plan
+// @Accept			json
+// @Produce		json
+// @Param			id	path	int	true	"ID of plan period"
+// @Security		ApiKeyAuth
+// @Success		200
+// @Failure		400	{object}	apiModel.Result
+// @Failure		401
+// @Router			/plan/period/{id} [DELETE]
+func deletePlanPeriod(w http.ResponseWriter, r *http.Request) {
+	id, err := apihelper.ExtractIntFromURL(r, "id")
+	if err != nil || id <= 0 {
+		apihelper.ResponseBadRequest(w, apiModel.Result{Result: "id not correctly set"}, err)
+		return
+	}
+
+	tx := middleware.GetTx(r.Context())
+	switch err := plan.DeletePlanPeriod(tx, uint(id)); err {
+	case nil:
+		w.WriteHeader(http.StatusOK)
+	case gorm.ErrRecordNotFound:
+		apihelper.ResponseBadRequest(w, apiModel.Result{Result: "plan period not deleted", Error: err.Error()}, err)
+	default:
+		apihelper.InternalError(w, err)
+	}
+}
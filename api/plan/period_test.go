@@ -0,0 +1,191 @@
+package plan
+
+import (
+	"fmt"
+	"mpt_data/database"
+	apiModel "mpt_data/models/apimodel"
+	dbModel "mpt_data/models/dbmodel"
+	generalmodel "mpt_data/models/general"
+	api_test "mpt_data/test/api"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestAddPlanPeriod(t *testing.T) {
+	// Prepare
+	existing := dbModel.PlanPeriod{
+		Period: generalmodel.Period{
+			StartDate: time.Date(2002, 1, 1, 0, 0, 0, 0, time.UTC),
+			EndDate:   time.Date(2002, 1, 31, 0, 0, 0, 0, time.UTC),
+		},
+		State: dbModel.PlanPeriodDraft,
+	}
+	if err := database.DB.Create(&existing).Error; err != nil {
+		t.Skipf("test preparation failed: %v", err)
+	}
+	t.Cleanup(func() {
+		database.DB.Unscoped().Delete(&existing)
+	})
+
+	var testcases = []struct {
+		name       string
+		period     dbModel.PlanPeriod
+		statusCode int
+	}{
+		{
+			"add",
+			dbModel.PlanPeriod{Period: generalmodel.Period{
+				StartDate: time.Date(2002, 2, 1, 0, 0, 0, 0, time.UTC),
+				EndDate:   time.Date(2002, 2, 28, 0, 0, 0, 0, time.UTC),
+			}},
+			http.StatusCreated,
+		},
+		{
+			"overlap",
+			dbModel.PlanPeriod{Period: generalmodel.Period{
+				StartDate: time.Date(2002, 1, 15, 0, 0, 0, 0, time.UTC),
+				EndDate:   time.Date(2002, 2, 15, 0, 0, 0, 0, time.UTC),
+			}},
+			http.StatusBadRequest,
+		},
+		{
+			"end before start",
+			dbModel.PlanPeriod{Period: generalmodel.Period{
+				StartDate: time.Date(2002, 3, 31, 0, 0, 0, 0, time.UTC),
+				EndDate:   time.Date(2002, 3, 1, 0, 0, 0, 0, time.UTC),
+			}},
+			http.StatusBadRequest,
+		},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			// Act
+			response := api_test.DoRequest(t, api_test.RequestData{
+				Data:   testcase.period,
+				Route:  apiModel.PlanPeriodHref,
+				Method: http.MethodPost,
+				Router: addPlanPeriod,
+				Path:   apiModel.PlanPeriodHref,
+			})
+			// Assert
+			if response.Code != testcase.statusCode {
+				t.Errorf("expected status code %d, got %d", testcase.statusCode, response.Code)
+				t.Logf("Body: %s", response.Body)
+			}
+		})
+	}
+}
+
+func TestUpdatePlanPeriod(t *testing.T) {
+	// Prepare
+	periods := []dbModel.PlanPeriod{
+		{
+			Period: generalmodel.Period{
+				StartDate: time.Date(2002, 4, 1, 0, 0, 0, 0, time.UTC),
+				EndDate:   time.Date(2002, 4, 30, 0, 0, 0, 0, time.UTC),
+			},
+			State: dbModel.PlanPeriodDraft,
+		},
+		{
+			Period: generalmodel.Period{
+				StartDate: time.Date(2002, 5, 1, 0, 0, 0, 0, time.UTC),
+				EndDate:   time.Date(2002, 5, 31, 0, 0, 0, 0, time.UTC),
+			},
+			State: dbModel.PlanPeriodArchived,
+		},
+	}
+	if err := database.DB.Create(&periods).Error; err != nil {
+		t.Skipf("test preparation failed: %v", err)
+	}
+	t.Cleanup(func() {
+		database.DB.Unscoped().Delete(&periods)
+	})
+	route := func(id uint) string {
+		return fmt.Sprintf("%s/%d", apiModel.PlanPeriodHref, id)
+	}
+
+	var testcases = []struct {
+		name       string
+		id         uint
+		state      string
+		statusCode int
+	}{
+		{"publish", periods[0].ID, dbModel.PlanPeriodPublished, http.StatusOK},
+		{"unknown state", periods[0].ID, "bla", http.StatusBadRequest},
+		{"archived", periods[1].ID, dbModel.PlanPeriodDraft, http.StatusBadRequest},
+		{"not found", 0xFFFFFF, dbModel.PlanPeriodPublished, http.StatusBadRequest},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			// Act
+			response := api_test.DoRequest(t, api_test.RequestData{
+				Data:   dbModel.PlanPeriod{State: testcase.state},
+				Route:  route(testcase.id),
+				Method: http.MethodPut,
+				Router: updatePlanPeriod,
+				Path:   apiModel.PlanPeriodHrefWithID,
+			})
+			// Assert
+			if response.Code != testcase.statusCode {
+				t.Errorf("expected status code %d, got %d", testcase.statusCode, response.Code)
+				t.Logf("Body: %s", response.Body)
+			}
+		})
+	}
+}
+
+func TestAddPlanPublished(t *testing.T) {
+	// Prepare
+	meeting := dbModel.Meeting{Date: time.Date(2002, 6, 2, 0, 0, 0, 0, time.UTC)}
+	if err := database.DB.Create(&meeting).Error; err != nil {
+		t.Skipf("test preparation failed: %v", err)
+	}
+	period := dbModel.PlanPeriod{
+		Period: generalmodel.Period{
+			StartDate: time.Date(2002, 6, 1, 0, 0, 0, 0, time.UTC),
+			EndDate:   time.Date(2002, 6, 30, 0, 0, 0, 0, time.UTC),
+		},
+		State: dbModel.PlanPeriodPublished,
+	}
+	if err := database.DB.Create(&period).Error; err != nil {
+		t.Skipf("test preparation failed: %v", err)
+	}
+	t.Cleanup(func() {
+		database.DB.Unscoped().Delete(&period)
+		database.DB.Unscoped().Delete(&meeting)
+	})
+	route := func(query string) string {
+		return fmt.Sprintf("%s?StartDate=2002-06-01&EndDate=2002-06-30%s", apiModel.PlanHref, query)
+	}
+
+	var testcases = []struct {
+		name       string
+		query      string
+		statusCode int
+	}{
+		{"not forced", "", http.StatusConflict},
+		{"forced", "&force=true", http.StatusOK},
+		{"dry run", "&dryRun=true", http.StatusOK},
+		{"invalid force", "&force=bla", http.StatusBadRequest},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			// Act
+			response := api_test.DoRequest(t, api_test.RequestData{
+				Route:  route(testcase.query),
+				Method: http.MethodPost,
+				Router: addPlan,
+				Path:   apiModel.PlanHref,
+			})
+			// Assert
+			if response.Code != testcase.statusCode {
+				t.Errorf("expected status code %d, got %d", testcase.statusCode, response.Code)
+				t.Logf("Body: %s", response.Body)
+			}
+		})
+	}
+}
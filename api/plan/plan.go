@@ -12,6 +12,7 @@ import (
 	dbModel "mpt_data/models/dbmodel"
 	generalmodel "mpt_data/models/general"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/gorilla/mux"
@@ -22,9 +23,30 @@ import (
 // RegisterRoutes adds all routes to a mux.Router
 func RegisterRoutes(mux *mux.Router) {
 	mux.HandleFunc(apiModel.PlanHref, middleware.CheckAuthentication(getPlan)).Methods(http.MethodGet)
+	mux.HandleFunc(apiModel.PlanHrefConflicts, middleware.CheckAuthentication(getPlanConflicts)).Methods(http.MethodGet)
+	mux.HandleFunc(apiModel.PlanHrefChanges, middleware.CheckAuthentication(getPlanChanges)).Methods(http.MethodGet)
+	mux.HandleFunc(apiModel.PersonHrefPlan, middleware.CheckAuthentication(getPlanOfPerson)).Methods(http.MethodGet)
+	mux.HandleFunc(apiModel.MePlanHref, middleware.CheckSelf(getPlanOfPerson)).Methods(http.MethodGet)
+
+	// period.go
+	mux.HandleFunc(apiModel.PlanPeriodHref, middleware.CheckAuthentication(getPlanPeriods)).Methods(http.MethodGet)
+	mux.HandleFunc(apiModel.PlanPeriodHref, middleware.CheckAuthentication(addPlanPeriod)).Methods(http.MethodPost)
+	mux.HandleFunc(apiModel.PlanPeriodHrefWithID, middleware.CheckAuthentication(updatePlanPeriod)).Methods(http.MethodPut)
+	mux.HandleFunc(apiModel.PlanPeriodHrefWithID, middleware.CheckAuthentication(deletePlanPeriod)).Methods(http.MethodDelete)
+
+	// swap.go
+	mux.HandleFunc(apiModel.PlanSwapHref, middleware.CheckAuthentication(getSwapRequests)).Methods(http.MethodGet)
+	mux.HandleFunc(apiModel.PlanSwapHref, middleware.CheckAuthentication(offerSwap)).Methods(http.MethodPost)
+	mux.HandleFunc(apiModel.PlanSwapHrefAccept, middleware.CheckAuthentication(acceptSwap)).Methods(http.MethodPut)
+	mux.HandleFunc(apiModel.PlanSwapHrefApprove, middleware.CheckAuthentication(approveSwap)).Methods(http.MethodPut)
+	mux.HandleFunc(apiModel.PlanSwapHrefReject, middleware.CheckAuthentication(rejectSwap)).Methods(http.MethodPut)
+
 	mux.HandleFunc(apiModel.PlanHrefWithID, middleware.CheckAuthentication(getPlanWithID)).Methods(http.MethodGet)
 	mux.HandleFunc(apiModel.PlanHrefWithIDPeople, middleware.CheckAuthentication(getPersonPlan)).Methods(http.MethodGet)
+	mux.HandleFunc(apiModel.PlanHrefWithIDHistory, middleware.CheckAuthentication(getPlanHistory)).Methods(http.MethodGet)
 	mux.HandleFunc(apiModel.PlanHref, middleware.CheckAuthentication(addPlan)).Methods(http.MethodPost)
+	mux.HandleFunc(apiModel.PlanHrefSolve, middleware.CheckAuthentication(solvePlan)).Methods(http.MethodPost)
+	mux.HandleFunc(apiModel.PlanHrefFill, middleware.CheckAuthentication(fillPlan)).Methods(http.MethodPost)
 	mux.HandleFunc(apiModel.PlanHrefWithID, middleware.CheckAuthentication(updatePlan)).Methods(http.MethodPut)
 }
 
@@ -80,6 +102,88 @@ func getPlanJSON(w http.ResponseWriter, _ *http.Request, startDate time.Time, en
 	apihelper.ResponseJSON(w, plan)
 }
 
+// @Summary		Get Plan of Person
+// @Description	Get all plan items of a person in a period, at /me/plan of the person linked to the user
+// @Tags			Plan,Person
+// @Accept			json
+// @Produce		json
+// @Param			id			path	int		true	"ID of person"
+// @Param			StartDate	query	string	true	"Start date/timestamp, Either English Date, or RFC3339"	Example("2023-01-21", "2023-01-21T00:00:00+00:00")
+// @Param			EndDate		query	string	true	"End date/timestamp, Either English Date, or RFC3339"	Example("2023-01-21", "2023-01-21T00:00:00+00:00")
+// @Security		ApiKeyAuth
+// @Success		200	{array}		dbModel.Plan
+// @Failure		400	{object}	apiModel.Result
+// @Failure		401
+// @Failure		403
+// @Router			/person/{id}/plan [GET]
+// @Router			/me/plan [GET]
+func getPlanOfPerson(w http.ResponseWriter, r *http.Request) {
+	id, err := apihelper.ExtractIntFromURL(r, "id")
+	if err != nil || id <= 0 {
+		apihelper.ResponseBadRequest(w, apiModel.Result{Result: "id not correctly set"}, err)
+		return
+	}
+
+	queryParams := r.URL.Query()
+	startDate, err := helper.ParseTime(queryParams.Get("StartDate"))
+	endDate, err2 := helper.ParseTime(queryParams.Get("EndDate"))
+	if err != nil {
+		apihelper.ResponseBadRequest(w, apiModel.Result{Result: "could not parse StartDate and/or EndDate"}, err)
+		return
+	}
+	if err2 != nil {
+		apihelper.ResponseBadRequest(w, apiModel.Result{Result: "could not parse StartDate and/or EndDate"}, err2)
+		return
+	}
+
+	tx := middleware.GetTx(r.Context())
+	plans, err := plan.GetPlanOfPerson(tx, uint(id), generalmodel.Period{StartDate: startDate, EndDate: endDate})
+	if err != nil {
+		apihelper.InternalError(w, err)
+		return
+	}
+	apihelper.ResponseJSON(w, plans)
+}
+
+// @Summary		Get Plan Conflicts
+// @Description	Get all plan items in a period, whose person is absent, not qualified for the task, assigned twice at the meeting or deleted
+// @Description	Reason is one of absent, recurring_absent, not_qualified, double_booked, person_deleted, inactive
+// @Tags			Plan
+// @Accept			json
+// @Produce		json
+// @Param			StartDate	query	string	true	"Start date/timestamp, Either English Date, or RFC3339"	Example("2023-01-21", "2023-01-21T00:00:00+00:00")
+// @Param			EndDate		query	string	true	"End date/timestamp, Either English Date, or RFC3339"	Example("2023-01-21", "2023-01-21T00:00:00+00:00")
+// @Security		ApiKeyAuth
+// @Success		200	{array}		apiModel.PlanConflict
+// @Failure		400	{object}	apiModel.Result
+// @Failure		401
+// @Router			/plan/conflicts [GET]
+func getPlanConflicts(w http.ResponseWriter, r *http.Request) {
+	queryParams := r.URL.Query()
+
+	startDate, err := helper.ParseTime(queryParams.Get("StartDate"))
+	endDate, err2 := helper.ParseTime(queryParams.Get("EndDate"))
+	if err != nil {
+		apihelper.ResponseBadRequest(w, apiModel.Result{Result: "could not parse StartDate and/or EndDate"}, err)
+		return
+	}
+	if err2 != nil {
+		apihelper.ResponseBadRequest(w, apiModel.Result{Result: "could not parse StartDate and/or EndDate"}, err2)
+		return
+	}
+
+	tx := middleware.GetTx(r.Context())
+	conflicts, err := plan.GetConflicts(tx, generalmodel.Period{StartDate: startDate, EndDate: endDate})
+	if err != nil {
+		apihelper.InternalError(w, err)
+		return
+	}
+	if conflicts == nil {
+		conflicts = []apiModel.PlanConflict{}
+	}
+	apihelper.ResponseJSON(w, conflicts)
+}
+
 // @Summary		Get Plan with ID
 // @Description	Get Plan for a specific planId
 // @Tags			Plan
@@ -148,10 +252,15 @@ func getPersonPlan(w http.ResponseWriter, r *http.Request) {
 // @Produce		json
 // @Param			StartDate	query	string	true	"Start date/timestamp, Either English Date, or RFC3339"	Example("2023-01-21", "2023-01-21T00:00:00+00:00")
 // @Param			EndDate		query	string	true	"End date/timestamp, Either English Date, or RFC3339"	Example("2023-01-21", "2023-01-21T00:00:00+00:00")
+// @Param			strategy	query	string	false	"Assignment strategy, if not set the configured strategy is used"	Enums(leastloaded, roundrobin, weightedrandom)
+// @Param			dryRun		query	bool	false	"Only compute the plan without storing it, returns apiModel.PlanResult"
+// @Param			force		query	bool	false	"Change the plan, even if it is published"
 // @Security		ApiKeyAuth
 // @Success		201	{array}		dbModel.Plan
+// @Success		200	{object}	apiModel.PlanResult	"if dryRun is set"
 // @Failure		400	{object}	apiModel.Result
 // @Failure		401
+// @Failure		409	{object}	apiModel.Result	"plan is published"
 // @Router			/plan [POST]
 func addPlan(w http.ResponseWriter, r *http.Request) {
 	queryParams := r.URL.Query()
@@ -167,31 +276,173 @@ func addPlan(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	plan, err := plan.CreatePlanData(database.DB, generalmodel.Period{StartDate: startDate, EndDate: endDate})
+	strategy, err := plan.GetStrategy(queryParams.Get("strategy"))
+	if err != nil {
+		apihelper.ResponseBadRequest(w, apiModel.Result{Result: "strategy not valid", Error: err.Error()}, err)
+		return
+	}
+
+	dryRun, err := parseBool(queryParams.Get("dryRun"))
+	if err != nil {
+		apihelper.ResponseBadRequest(w, apiModel.Result{Result: "could not parse dryRun"}, err)
+		return
+	}
+	force, err := parseBool(queryParams.Get("force"))
+	if err != nil {
+		apihelper.ResponseBadRequest(w, apiModel.Result{Result: "could not parse force"}, err)
+		return
+	}
+
+	tx := middleware.GetTx(r.Context())
+	period := generalmodel.Period{StartDate: startDate, EndDate: endDate}
+	if dryRun {
+		result, err := plan.PreviewPlanData(tx, period, strategy, apihelper.UserIDFromRequest(r))
+		if err != nil {
+			apihelper.InternalError(w, err)
+			return
+		}
+		apihelper.ResponseJSON(w, result)
+		return
+	}
+
+	data, err := plan.CreatePlanData(tx, period, strategy, force, apihelper.UserIDFromRequest(r))
+	if err == errors.ErrPlanPublished {
+		responsePublished(w, err)
+		return
+	}
 	if err != nil {
 		apihelper.InternalError(w, err)
 		return
 	}
+	apihelper.ResponseJSON(w, data)
+}
 
-	apihelper.ResponseJSON(w, plan)
+// @Summary		Fill Plan
+// @Description	Assign people to all plan items without a person in a period, plan items with a person are not changed
+// @Description	Returns the plan items that got a person
+// @Tags			Plan
+// @Accept			json
+// @Produce		json
+// @Param			StartDate	query	string	true	"Start date/timestamp, Either English Date, or RFC3339"	Example("2023-01-21", "2023-01-21T00:00:00+00:00")
+// @Param			EndDate		query	string	true	"End date/timestamp, Either English Date, or RFC3339"	Example("2023-01-21", "2023-01-21T00:00:00+00:00")
+// @Param			strategy	query	string	false	"Assignment strategy, if not set the configured strategy is used"	Enums(leastloaded, roundrobin, weightedrandom)
+// @Param			force		query	bool	false	"Change the plan, even if it is published"
+// @Security		ApiKeyAuth
+// @Success		200	{array}		dbModel.Plan
+// @Failure		400	{object}	apiModel.Result
+// @Failure		401
+// @Failure		409	{object}	apiModel.Result	"plan is published"
+// @Router			/plan/fill [POST]
+func fillPlan(w http.ResponseWriter, r *http.Request) {
+	queryParams := r.URL.Query()
+
+	startDate, err := helper.ParseTime(queryParams.Get("StartDate"))
+	endDate, err2 := helper.ParseTime(queryParams.Get("EndDate"))
+	if err != nil {
+		apihelper.ResponseBadRequest(w, apiModel.Result{Result: "could not parse StartDate and/or EndDate"}, err)
+		return
+	}
+	if err2 != nil {
+		apihelper.ResponseBadRequest(w, apiModel.Result{Result: "could not parse StartDate and/or EndDate"}, err2)
+		return
+	}
+
+	strategy, err := plan.GetStrategy(queryParams.Get("strategy"))
+	if err != nil {
+		apihelper.ResponseBadRequest(w, apiModel.Result{Result: "strategy not valid", Error: err.Error()}, err)
+		return
+	}
+
+	force, err := parseBool(queryParams.Get("force"))
+	if err != nil {
+		apihelper.ResponseBadRequest(w, apiModel.Result{Result: "could not parse force"}, err)
+		return
+	}
+
+	tx := middleware.GetTx(r.Context())
+	changed, err := plan.FillPlanData(tx, generalmodel.Period{StartDate: startDate, EndDate: endDate}, strategy, force, apihelper.UserIDFromRequest(r))
+	if err == errors.ErrPlanPublished {
+		responsePublished(w, err)
+		return
+	}
+	if err != nil {
+		apihelper.InternalError(w, err)
+		return
+	}
+
+	apihelper.ResponseJSON(w, changed)
+}
+
+// @Summary		Solve Plan
+// @Description	Create Plan for a period and assign all slots together
+// @Description	Minimises unfilled slots first and then the imbalance of load between people
+// @Description	Complete is false, if not all slots could be filled
+// @Tags			Plan
+// @Accept			json
+// @Produce		json
+// @Param			StartDate	query	string	true	"Start date/timestamp, Either English Date, or RFC3339"	Example("2023-01-21", "2023-01-21T00:00:00+00:00")
+// @Param			EndDate		query	string	true	"End date/timestamp, Either English Date, or RFC3339"	Example("2023-01-21", "2023-01-21T00:00:00+00:00")
+// @Param			force		query	bool	false	"Change the plan, even if it is published"
+// @Security		ApiKeyAuth
+// @Success		201	{object}	apiModel.PlanResult
+// @Failure		400	{object}	apiModel.Result
+// @Failure		401
+// @Failure		409	{object}	apiModel.Result	"plan is published"
+// @Router			/plan/solve [POST]
+func solvePlan(w http.ResponseWriter, r *http.Request) {
+	queryParams := r.URL.Query()
+
+	startDate, err := helper.ParseTime(queryParams.Get("StartDate"))
+	endDate, err2 := helper.ParseTime(queryParams.Get("EndDate"))
+	if err != nil {
+		apihelper.ResponseBadRequest(w, apiModel.Result{Result: "could not parse StartDate and/or EndDate"}, err)
+		return
+	}
+	if err2 != nil {
+		apihelper.ResponseBadRequest(w, apiModel.Result{Result: "could not parse StartDate and/or EndDate"}, err2)
+		return
+	}
+
+	force, err := parseBool(queryParams.Get("force"))
+	if err != nil {
+		apihelper.ResponseBadRequest(w, apiModel.Result{Result: "could not parse force"}, err)
+		return
+	}
+
+	tx := middleware.GetTx(r.Context())
+	result, err := plan.SolvePlanData(tx, generalmodel.Period{StartDate: startDate, EndDate: endDate}, force)
+	if err == errors.ErrPlanPublished {
+		responsePublished(w, err)
+		return
+	}
+	if err != nil {
+		apihelper.InternalError(w, err)
+		return
+	}
+
+	apihelper.ResponseJSON(w, result, http.StatusCreated)
 }
 
 // @Summary		Update a Plan Element
-// @Description	Update Person for one task and meeting
+// @Description	Update Person for one task and meeting, the change is recorded in the history of the plan element
 // @Tags			Plan
 // @Accept			json
 // @Produce		json
 // @Param			id		path	int					true	"ID of plan item"
-// @Param			person	body	plan.updatePlan.p	true	"ID of Person"
+// @Param			person	body	plan.updatePlan.p	true	"ID of Person and optional reason"
+// @Param			force	query	bool				false	"Change the plan, even if it is published"
 // @Security		ApiKeyAuth
 // @Success		200
 // @Failure		400	{object}	apiModel.Result
 // @Failure		401
+// @Failure		409	{object}	apiModel.Result	"plan is published"
 // @Router			/plan/{id} [PUT]
 func updatePlan(w http.ResponseWriter, r *http.Request) {
 
 	type p struct {
 		ID uint
+		// optional reason of the change, stored in the history of the plan element
+		Reason string
 	}
 	var person p
 	if err := json.NewDecoder(r.Body).Decode(&person); err != nil {
@@ -209,13 +460,36 @@ func updatePlan(w http.ResponseWriter, r *http.Request) {
 	database.DB.First(&planData, "id = ?", uint(*id))
 	planData.PersonID = person.ID
 
-	err = plan.UpdatePlanElement(planData)
+	force, err := parseBool(r.URL.Query().Get("force"))
+	if err != nil {
+		apihelper.ResponseBadRequest(w, apiModel.Result{Result: "could not parse force"}, err)
+		return
+	}
+
+	err = plan.UpdatePlanElement(planData, force, apihelper.UserIDFromRequest(r), person.Reason)
 	switch err {
 	case gorm.ErrRecordNotFound, errors.ErrTaskForPersonNotAllowed:
 		w.WriteHeader(http.StatusBadRequest)
+	case errors.ErrAssignmentCapExceeded, errors.ErrRelationViolated:
+		apihelper.ResponseBadRequest(w, apiModel.Result{Result: "person not assigned", Error: err.Error()}, err)
+	case errors.ErrPlanPublished:
+		responsePublished(w, err)
 	case nil:
 		w.WriteHeader(http.StatusOK)
 	default:
 		apihelper.InternalError(w, err)
 	}
 }
+
+// parseBool parses an optional boolean query parameter, empty is false
+func parseBool(value string) (bool, error) {
+	if value == "" {
+		return false, nil
+	}
+	return strconv.ParseBool(value)
+}
+
+// responsePublished sends StatusConflict, because the plan is published and the change was not forced
+func responsePublished(w http.ResponseWriter, err error) {
+	apihelper.ResponseJSON(w, apiModel.Result{Result: "plan is published, set force to change it", Error: err.Error()}, http.StatusConflict)
+}
@@ -0,0 +1,211 @@
+package plan
+
+import (
+	"encoding/json"
+	"fmt"
+	"mpt_data/database"
+	apiModel "mpt_data/models/apimodel"
+	dbModel "mpt_data/models/dbmodel"
+	api_test "mpt_data/test/api"
+	"mpt_data/test/vars"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestMain(m *testing.M) {
+	vars.PrepareConfig()
+	m.Run()
+}
+
+func TestAddPlan(t *testing.T) {
+	// Prepare
+	meeting := dbModel.Meeting{Date: time.Date(2001, 1, 7, 0, 0, 0, 0, time.UTC)}
+	if err := database.DB.Create(&meeting).Error; err != nil {
+		t.Skipf("test preparation failed: %v", err)
+	}
+	t.Cleanup(func() {
+		database.DB.Unscoped().Delete(&meeting)
+	})
+	route := func(query string) string {
+		return fmt.Sprintf("%s?StartDate=2001-01-01&EndDate=2001-01-31%s", apiModel.PlanHref, query)
+	}
+
+	var testcases = []struct {
+		name       string
+		data       api_test.RequestData
+		statusCode int
+		dryRun     bool
+	}{
+		{
+			"dry run",
+			api_test.RequestData{
+				Route:  route("&dryRun=true"),
+				Method: http.MethodPost,
+				Router: addPlan,
+				Path:   apiModel.PlanHref,
+			},
+			http.StatusOK,
+			true,
+		},
+		{
+			"invalid dry run",
+			api_test.RequestData{
+				Route:  route("&dryRun=bla"),
+				Method: http.MethodPost,
+				Router: addPlan,
+				Path:   apiModel.PlanHref,
+			},
+			http.StatusBadRequest,
+			false,
+		},
+		{
+			"unknown strategy",
+			api_test.RequestData{
+				Route:  route("&strategy=bla"),
+				Method: http.MethodPost,
+				Router: addPlan,
+				Path:   apiModel.PlanHref,
+			},
+			http.StatusBadRequest,
+			false,
+		},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			// Act
+			response := api_test.DoRequest(t, testcase.data)
+			// Assert
+			if response.Code != testcase.statusCode {
+				t.Errorf("expected status code %d, got %d", testcase.statusCode, response.Code)
+				t.Logf("Body: %s", response.Body)
+			}
+			if testcase.dryRun {
+				var result apiModel.PlanResult
+				if err := json.NewDecoder(response.Body).Decode(&result); err != nil {
+					t.Errorf("expected plan result, got %v", err)
+				}
+				var count int64
+				database.DB.Model(&dbModel.Plan{}).Where("meeting_id = ?", meeting.ID).Count(&count)
+				if count != 0 {
+					t.Errorf("expected no plan stored, got %d", count)
+				}
+			}
+		})
+	}
+}
+
+func TestFillPlan(t *testing.T) {
+	route := func(query string) string {
+		return fmt.Sprintf("%s?StartDate=2001-01-01&EndDate=2001-01-31%s", apiModel.PlanHrefFill, query)
+	}
+
+	var testcases = []struct {
+		name       string
+		data       api_test.RequestData
+		statusCode int
+	}{
+		{
+			"fill",
+			api_test.RequestData{
+				Route:  route(""),
+				Method: http.MethodPost,
+				Router: fillPlan,
+				Path:   apiModel.PlanHrefFill,
+			},
+			http.StatusOK,
+		},
+		{
+			"unknown strategy",
+			api_test.RequestData{
+				Route:  route("&strategy=bla"),
+				Method: http.MethodPost,
+				Router: fillPlan,
+				Path:   apiModel.PlanHrefFill,
+			},
+			http.StatusBadRequest,
+		},
+		{
+			"invalid period",
+			api_test.RequestData{
+				Route:  apiModel.PlanHrefFill + "?StartDate=bla",
+				Method: http.MethodPost,
+				Router: fillPlan,
+				Path:   apiModel.PlanHrefFill,
+			},
+			http.StatusBadRequest,
+		},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			// Act
+			response := api_test.DoRequest(t, testcase.data)
+			// Assert
+			if response.Code != testcase.statusCode {
+				t.Errorf("expected status code %d, got %d", testcase.statusCode, response.Code)
+				t.Logf("Body: %s", response.Body)
+			}
+		})
+	}
+}
+
+func TestGetPlanConflicts(t *testing.T) {
+	// Prepare
+	meeting := dbModel.Meeting{Date: time.Date(2002, 2, 3, 0, 0, 0, 0, time.UTC)}
+	activeUntil := time.Date(2002, 1, 31, 0, 0, 0, 0, time.UTC)
+	person := dbModel.Person{GivenName: "Max", LastName: "Maier", ActiveUntil: &activeUntil}
+	if err := database.DB.Create(&meeting).Error; err != nil {
+		t.Skipf("test preparation failed: %v", err)
+	}
+	if err := database.DB.Create(&person).Error; err != nil {
+		t.Skipf("test preparation failed: %v", err)
+	}
+	plans := []dbModel.Plan{
+		{MeetingID: meeting.ID, PersonID: person.ID, TaskDetailID: 1, Slot: 0},
+		{MeetingID: meeting.ID, PersonID: person.ID, TaskDetailID: 1, Slot: 1},
+	}
+	absence := dbModel.PersonAbsence{MeetingID: meeting.ID, PersonID: person.ID}
+	if err := database.DB.Create(&plans).Error; err != nil {
+		t.Skipf("test preparation failed: %v", err)
+	}
+	if err := database.DB.Create(&absence).Error; err != nil {
+		t.Skipf("test preparation failed: %v", err)
+	}
+	t.Cleanup(func() {
+		database.DB.Unscoped().Delete(&absence)
+		database.DB.Unscoped().Delete(&plans)
+		database.DB.Unscoped().Delete(&person)
+		database.DB.Unscoped().Delete(&meeting)
+	})
+
+	// Act
+	response := api_test.DoRequest(t, api_test.RequestData{
+		Route:  apiModel.PlanHrefConflicts + "?StartDate=2002-02-01&EndDate=2002-02-28",
+		Method: http.MethodGet,
+		Router: getPlanConflicts,
+		Path:   apiModel.PlanHrefConflicts,
+	})
+
+	// Assert
+	if response.Code != http.StatusOK {
+		t.Fatalf("expected status code %d, got %d", http.StatusOK, response.Code)
+	}
+	var conflicts []apiModel.PlanConflict
+	if err := json.NewDecoder(response.Body).Decode(&conflicts); err != nil {
+		t.Fatalf("expected conflicts, got %v", err)
+	}
+	reasons := make(map[string]int)
+	for _, conflict := range conflicts {
+		reasons[conflict.Reason]++
+	}
+	for _, reason := range []string{apiModel.ConflictAbsent, apiModel.ConflictNotQualified, apiModel.ConflictDoubleBooked, apiModel.ConflictInactive} {
+		if reasons[reason] != len(plans) {
+			t.Errorf("expected %d conflicts with reason %s, got %d", len(plans), reason, reasons[reason])
+		}
+	}
+	if len(conflicts) != 4*len(plans) {
+		t.Errorf("expected %d conflicts, got %v", 4*len(plans), conflicts)
+	}
+}
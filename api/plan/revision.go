@@ -0,0 +1,73 @@
+package plan
+
+import (
+	"mpt_data/api/apihelper"
+	"mpt_data/api/middleware"
+	"mpt_data/database/plan"
+	"mpt_data/helper"
+	apiModel "mpt_data/models/apimodel"
+	"net/http"
+	"time"
+)
+
+// @Summary		Get Plan History
+// @Description	Get all changes of the person of a plan element, oldest first
+// @Tags			Plan
+// @Accept			json
+// @Produce		json
+// @Param			id	path	int	true	"ID of plan item"
+// @Security		ApiKeyAuth
+// @Success		200	{array}		dbModel.PlanRevision
+// @Failure		400	{object}	apiModel.Result
+// @Failure		401
+// @Router			/plan/{id}/history [GET]
+func getPlanHistory(w http.ResponseWriter, r *http.Request) {
+	id, err := apihelper.ExtractIntFromURL(r, "id")
+	if err != nil || id <= 0 {
+		apihelper.ResponseBadRequest(w, apiModel.Result{Result: "id not correctly set"}, err)
+		return
+	}
+
+	tx := middleware.GetTx(r.Context())
+	revisions, err := plan.GetPlanHistory(tx, uint(id))
+	if err != nil {
+		apihelper.InternalError(w, err)
+		return
+	}
+	apihelper.ResponseJSON(w, revisions)
+}
+
+// @Summary		Get Plan Changes
+// @Description	Get all changes of plan elements since a time, oldest first.
+// @Description	If since is not set, the changes since the last publication of a plan period are loaded
+// @Tags			Plan
+// @Accept			json
+// @Produce		json
+// @Param			since	query	string	false	"Date/timestamp, Either English Date, or RFC3339"	Example("2023-01-21", "2023-01-21T00:00:00+00:00")
+// @Security		ApiKeyAuth
+// @Success		200	{array}		dbModel.PlanRevision
+// @Failure		400	{object}	apiModel.Result
+// @Failure		401
+// @Router			/plan/changes [GET]
+func getPlanChanges(w http.ResponseWriter, r *http.Request) {
+	tx := middleware.GetTx(r.Context())
+
+	var since time.Time
+	var err error
+	if value := r.URL.Query().Get("since"); value != "" {
+		if since, err = helper.ParseTime(value); err != nil {
+			apihelper.ResponseBadRequest(w, apiModel.Result{Result: "could not parse since"}, err)
+			return
+		}
+	} else if since, err = plan.LastPublication(tx); err != nil {
+		apihelper.InternalError(w, err)
+		return
+	}
+
+	revisions, err := plan.GetPlanChanges(tx, since)
+	if err != nil {
+		apihelper.InternalError(w, err)
+		return
+	}
+	apihelper.ResponseJSON(w, revisions)
+}
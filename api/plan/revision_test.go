@@ -0,0 +1,79 @@
+package plan
+
+import (
+	apiModel "mpt_data/models/apimodel"
+	api_test "mpt_data/test/api"
+	"net/http"
+	"testing"
+)
+
+func TestPlanRevisionRoutes(t *testing.T) {
+	var testcases = []struct {
+		name       string
+		data       api_test.RequestData
+		statusCode int
+	}{
+		{
+			"history",
+			api_test.RequestData{
+				Route:  apiModel.PlanHref + "/1/history",
+				Method: http.MethodGet,
+				Router: getPlanHistory,
+				Path:   apiModel.PlanHrefWithIDHistory,
+			},
+			http.StatusOK,
+		},
+		{
+			"history invalid id",
+			api_test.RequestData{
+				Route:  apiModel.PlanHref + "/bla/history",
+				Method: http.MethodGet,
+				Router: getPlanHistory,
+				Path:   apiModel.PlanHrefWithIDHistory,
+			},
+			http.StatusBadRequest,
+		},
+		{
+			"changes since",
+			api_test.RequestData{
+				Route:  apiModel.PlanHrefChanges + "?since=2001-01-01",
+				Method: http.MethodGet,
+				Router: getPlanChanges,
+				Path:   apiModel.PlanHrefChanges,
+			},
+			http.StatusOK,
+		},
+		{
+			"changes since last publication",
+			api_test.RequestData{
+				Route:  apiModel.PlanHrefChanges,
+				Method: http.MethodGet,
+				Router: getPlanChanges,
+				Path:   apiModel.PlanHrefChanges,
+			},
+			http.StatusOK,
+		},
+		{
+			"changes invalid since",
+			api_test.RequestData{
+				Route:  apiModel.PlanHrefChanges + "?since=bla",
+				Method: http.MethodGet,
+				Router: getPlanChanges,
+				Path:   apiModel.PlanHrefChanges,
+			},
+			http.StatusBadRequest,
+		},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			// Act
+			response := api_test.DoRequest(t, testcase.data)
+			// Assert
+			if response.Code != testcase.statusCode {
+				t.Errorf("expected status code %d, got %d", testcase.statusCode, response.Code)
+				t.Logf("Body: %s", response.Body)
+			}
+		})
+	}
+}
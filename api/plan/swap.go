@@ -0,0 +1,161 @@
+package plan
+
+import (
+	"encoding/json"
+	"mpt_data/api/apihelper"
+	"mpt_data/api/middleware"
+	"mpt_data/database/plan"
+	"mpt_data/helper/errors"
+	apiModel "mpt_data/models/apimodel"
+	dbModel "mpt_data/models/dbmodel"
+	"net/http"
+
+	"gorm.io/gorm"
+)
+
+// @Summary		Get Swap Requests
+// @Description	Get all swap requests, optionally only in one state
+// @Tags			Plan
+// @Accept			json
+// @Produce		json
+// @Param			state	query	string	false	"State of the swap requests"	Enums(offered, accepted, approved, rejected)
+// @Security		ApiKeyAuth
+// @Success		200	{array}	dbModel.SwapRequest
+// @Failure		401
+// @Router			/plan/swap [GET]
+func getSwapRequests(w http.ResponseWriter, r *http.Request) {
+	tx := middleware.GetTx(r.Context())
+	swaps, err := plan.GetSwapRequests(tx, r.URL.Query().Get("state"))
+	if err != nil {
+		apihelper.InternalError(w, err)
+		return
+	}
+	apihelper.ResponseJSON(w, swaps)
+}
+
+// @Summary		Offer Swap
+// @Description	Offer a plan element for a swap, the person assigned to it is the offering person
+// @Tags			Plan
+// @Accept			json
+// @Produce		json
+// @Param			swap	body	apiModel.SwapPlan	true	"ID of the offered plan element"
+// @Security		ApiKeyAuth
+// @Success		201	{object}	dbModel.SwapRequest
+// @Failure		400	{object}	apiModel.Result
+// @Failure		401
+// @Router			/plan/swap [POST]
+func offerSwap(w http.ResponseWriter, r *http.Request) {
+	var body apiModel.SwapPlan
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		apihelper.ResponseBadRequest(w, apiModel.Result{Result: "error in request body"}, err)
+		return
+	}
+
+	tx := middleware.GetTx(r.Context())
+	swap, err := plan.OfferSwap(tx, body.PlanID)
+	responseSwap(w, swap, err, http.StatusCreated)
+}
+
+// @Summary		Accept Swap
+// @Description	Accept an offered swap with a plan element of another person.
+// @Description	Both people must be qualified for the task and available at the meeting of each other.
+// @Description	Depending on the configuration the swap is approved directly
+// @Tags			Plan
+// @Accept			json
+// @Produce		json
+// @Param			id		path	int					true	"ID of swap request"
+// @Param			swap	body	apiModel.SwapPlan	true	"ID of the plan element of the accepting person"
+// @Security		ApiKeyAuth
+// @Success		200	{object}	dbModel.SwapRequest
+// @Failure		400	{object}	apiModel.Result
+// @Failure		401
+// @Router			/plan/swap/{id}/accept [PUT]
+func acceptSwap(w http.ResponseWriter, r *http.Request) {
+	id, err := apihelper.ExtractIntFromURL(r, "id")
+	if err != nil || id <= 0 {
+		apihelper.ResponseBadRequest(w, apiModel.Result{Result: "id not correctly set"}, err)
+		return
+	}
+
+	var body apiModel.SwapPlan
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		apihelper.ResponseBadRequest(w, apiModel.Result{Result: "error in request body"}, err)
+		return
+	}
+
+	tx := middleware.GetTx(r.Context())
+	swap, err := plan.AcceptSwap(tx, uint(id), body.PlanID, apihelper.UserIDFromRequest(r))
+	responseSwap(w, swap, err)
+}
+
+// @Summary		Approve Swap
+// @Description	Approve an accepted swap, the people of both plan elements are swapped.
+// @Description	Swaps in published plans are only approved if forced
+// @Tags			Plan
+// @Accept			json
+// @Produce		json
+// @Param			id		path	int		true	"ID of swap request"
+// @Param			force	query	bool	false	"Approve the swap, even if the plan is published"
+// @Security		ApiKeyAuth
+// @Success		200	{object}	dbModel.SwapRequest
+// @Failure		400	{object}	apiModel.Result
+// @Failure		401
+// @Failure		409	{object}	apiModel.Result
+// @Router			/plan/swap/{id}/approve [PUT]
+func approveSwap(w http.ResponseWriter, r *http.Request) {
+	id, err := apihelper.ExtractIntFromURL(r, "id")
+	if err != nil || id <= 0 {
+		apihelper.ResponseBadRequest(w, apiModel.Result{Result: "id not correctly set"}, err)
+		return
+	}
+
+	force, err := parseBool(r.URL.Query().Get("force"))
+	if err != nil {
+		apihelper.ResponseBadRequest(w, apiModel.Result{Result: "could not parse force"}, err)
+		return
+	}
+
+	tx := middleware.GetTx(r.Context())
+	swap, err := plan.ApproveSwap(tx, uint(id), apihelper.UserIDFromRequest(r), force)
+	responseSwap(w, swap, err)
+}
+
+// @Summary		Reject Swap
+// @Description	Reject an offered or accepted swap, the plan is not changed
+// @Tags			Plan
+// @Accept			json
+// @Produce		json
+// @Param			id	path	int	true	"ID of swap request"
+// @Security		ApiKeyAuth
+// @Success		200	{object}	dbModel.SwapRequest
+// @Failure		400	{object}	apiModel.Result
+// @Failure		401
+// @Router			/plan/swap/{id}/reject [PUT]
+func rejectSwap(w http.ResponseWriter, r *http.Request) {
+	id, err := apihelper.ExtractIntFromURL(r, "id")
+	if err != nil || id <= 0 {
+		apihelper.ResponseBadRequest(w, apiModel.Result{Result: "id not correctly set"}, err)
+		return
+	}
+
+	tx := middleware.GetTx(r.Context())
+	swap, err := plan.RejectSwap(tx, uint(id))
+	responseSwap(w, swap, err)
+}
+
+// responseSwap sends the swap request, StatusBadRequest if the swap is not possible or StatusConflict if the plan is published
+func responseSwap(w http.ResponseWriter, swap dbModel.SwapRequest, err error, statusCode ...int) {
+	switch err {
+	case nil:
+		apihelper.ResponseJSON(w, swap, statusCode...)
+	case errors.ErrPlanPublished:
+		responsePublished(w, err)
+	case errors.ErrSwapState, errors.ErrSwapAlreadyOffered, errors.ErrSwapInvalid,
+		errors.ErrSwapNotAvailable, errors.ErrSwapOutdated, errors.ErrTaskForPersonNotAllowed,
+		errors.ErrAssignmentCapExceeded, errors.ErrRestViolated, errors.ErrRelationViolated,
+		gorm.ErrRecordNotFound:
+		apihelper.ResponseBadRequest(w, apiModel.Result{Result: "swap not possible", Error: err.Error()}, err)
+	default:
+		apihelper.InternalError(w, err)
+	}
+}
@@ -0,0 +1,91 @@
+package plan
+
+import (
+	apiModel "mpt_data/models/apimodel"
+	api_test "mpt_data/test/api"
+	"net/http"
+	"testing"
+)
+
+func TestSwapRoutes(t *testing.T) {
+	var testcases = []struct {
+		name       string
+		data       api_test.RequestData
+		statusCode int
+	}{
+		{
+			"get",
+			api_test.RequestData{
+				Route:  apiModel.PlanSwapHref + "?state=offered",
+				Method: http.MethodGet,
+				Router: getSwapRequests,
+				Path:   apiModel.PlanSwapHref,
+			},
+			http.StatusOK,
+		},
+		{
+			"offer unknown plan",
+			api_test.RequestData{
+				Data:   apiModel.SwapPlan{PlanID: 0xFFFFFF},
+				Route:  apiModel.PlanSwapHref,
+				Method: http.MethodPost,
+				Router: offerSwap,
+				Path:   apiModel.PlanSwapHref,
+			},
+			http.StatusBadRequest,
+		},
+		{
+			"accept unknown swap",
+			api_test.RequestData{
+				Data:   apiModel.SwapPlan{PlanID: 1},
+				Route:  apiModel.PlanSwapHref + "/16777215/accept",
+				Method: http.MethodPut,
+				Router: acceptSwap,
+				Path:   apiModel.PlanSwapHrefAccept,
+			},
+			http.StatusBadRequest,
+		},
+		{
+			"approve invalid id",
+			api_test.RequestData{
+				Route:  apiModel.PlanSwapHref + "/bla/approve",
+				Method: http.MethodPut,
+				Router: approveSwap,
+				Path:   apiModel.PlanSwapHrefApprove,
+			},
+			http.StatusBadRequest,
+		},
+		{
+			"approve invalid force",
+			api_test.RequestData{
+				Route:  apiModel.PlanSwapHref + "/16777215/approve?force=bla",
+				Method: http.MethodPut,
+				Router: approveSwap,
+				Path:   apiModel.PlanSwapHrefApprove,
+			},
+			http.StatusBadRequest,
+		},
+		{
+			"reject unknown swap",
+			api_test.RequestData{
+				Route:  apiModel.PlanSwapHref + "/16777215/reject",
+				Method: http.MethodPut,
+				Router: rejectSwap,
+				Path:   apiModel.PlanSwapHrefReject,
+			},
+			http.StatusBadRequest,
+		},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			// Act
+			response := api_test.DoRequest(t, testcase.data)
+			// Assert
+			if response.Code != testcase.statusCode {
+				t.Errorf("expected status code %d, got %d", testcase.statusCode, response.Code)
+				t.Logf("Body: %s", response.Body)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+// Package stats provides the routes to show how fair people are assigned
+package stats
+
+import (
+	"encoding/csv"
+	"fmt"
+	"mpt_data/api/apihelper"
+	"mpt_data/api/middleware"
+	"mpt_data/database/plan"
+	"mpt_data/helper"
+	apiModel "mpt_data/models/apimodel"
+	generalmodel "mpt_data/models/general"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+// RegisterRoutes adds all routes to a mux.Router
+func RegisterRoutes(mux *mux.Router) {
+	mux.HandleFunc(apiModel.StatsAssignmentsHref, middleware.CheckAuthentication(getAssignmentStats)).Methods(http.MethodGet)
+}
+
+// @Summary		Get Assignment Statistics
+// @Description	Get for every person and every task detail the person is qualified for the number of assignments in a period,
+// @Description	the share of eligible meetings, the deviation from the mean of all qualified people and the number of absences
+// @Tags			Statistics
+// @Accept			json
+// @Produce		json,text/csv
+// @Param			StartDate	query	string	true	"Start date/timestamp, Either English Date, or RFC3339"	Example("2023-01-21", "2023-01-21T00:00:00+00:00")
+// @Param			EndDate		query	string	true	"End date/timestamp, Either English Date, or RFC3339"	Example("2023-01-21", "2023-01-21T00:00:00+00:00")
+// @Security		ApiKeyAuth
+// @Success		200	{array}		apiModel.AssignmentStat
+// @Failure		400	{object}	apiModel.Result
+// @Failure		401
+// @Router			/stats/assignments [GET]
+func getAssignmentStats(w http.ResponseWriter, r *http.Request) {
+	accept := r.Header.Get("Accept")
+
+	var response func(http.ResponseWriter, []apiModel.AssignmentStat)
+	if accept == "text/csv" {
+		response = responseCSV
+	} else {
+		response = responseJSON
+		if accept != "application/json" {
+			zap.L().Info(generalmodel.UnkownAcceptHeader, zap.String(generalmodel.AcceptHeader, accept))
+		}
+	}
+
+	queryParams := r.URL.Query()
+
+	startDate, err := helper.ParseTime(queryParams.Get("StartDate"))
+	endDate, err2 := helper.ParseTime(queryParams.Get("EndDate"))
+	if err != nil {
+		apihelper.ResponseBadRequest(w, apiModel.Result{Result: "could not parse StartDate and/or EndDate"}, err)
+		return
+	}
+	if err2 != nil {
+		apihelper.ResponseBadRequest(w, apiModel.Result{Result: "could not parse StartDate and/or EndDate"}, err2)
+		return
+	}
+
+	tx := middleware.GetTx(r.Context())
+	stats, err := plan.GetAssignmentStats(tx, generalmodel.Period{StartDate: startDate, EndDate: endDate})
+	if err != nil {
+		apihelper.InternalError(w, err)
+		return
+	}
+	response(w, stats)
+}
+
+func responseJSON(w http.ResponseWriter, stats []apiModel.AssignmentStat) {
+	apihelper.ResponseJSON(w, stats)
+}
+
+// responseCSV sends the statistics as csv with one line per person and task detail
+func responseCSV(w http.ResponseWriter, stats []apiModel.AssignmentStat) {
+	records := [][]string{{
+		"PersonID", "GivenName", "LastName", "TaskDetailID", "Task", "TaskDetail",
+		"Assignments", "EligibleMeetings", "Share", "Deviation", "Absences",
+	}}
+	for _, stat := range stats {
+		records = append(records, []string{
+			fmt.Sprint(stat.Person.ID),
+			stat.Person.GivenName,
+			stat.Person.LastName,
+			fmt.Sprint(stat.TaskDetail.ID),
+			stat.TaskDetail.Task.Descr,
+			stat.TaskDetail.Descr,
+			fmt.Sprint(stat.Assignments),
+			fmt.Sprint(stat.EligibleMeetings),
+			strconv.FormatFloat(stat.Share, 'f', 3, 64),
+			strconv.FormatFloat(stat.Deviation, 'f', 3, 64),
+			fmt.Sprint(stat.Absences),
+		})
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", "attachment; filename=assignments.csv")
+	w.WriteHeader(http.StatusOK)
+	if err := csv.NewWriter(w).WriteAll(records); err != nil {
+		zap.L().Error(generalmodel.InternalError, zap.Error(err))
+	}
+}
@@ -0,0 +1,53 @@
+package stats
+
+import (
+	"fmt"
+	apiModel "mpt_data/models/apimodel"
+	api_test "mpt_data/test/api"
+	"mpt_data/test/vars"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	vars.PrepareConfig()
+	m.Run()
+}
+
+func TestGetAssignmentStats(t *testing.T) {
+	route := fmt.Sprintf("%s?StartDate=2001-01-01&EndDate=2001-01-31", apiModel.StatsAssignmentsHref)
+
+	var testcases = []struct {
+		name        string
+		route       string
+		accept      string
+		statusCode  int
+		contentType string
+	}{
+		{"json", route, "application/json", http.StatusOK, "application/json"},
+		{"csv", route, "text/csv", http.StatusOK, "text/csv"},
+		{"invalid period", apiModel.StatsAssignmentsHref + "?StartDate=bla", "", http.StatusBadRequest, "application/json"},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			// Act
+			response := api_test.DoRequest(t, api_test.RequestData{
+				Route:  testcase.route,
+				Method: http.MethodGet,
+				Router: getAssignmentStats,
+				Path:   apiModel.StatsAssignmentsHref,
+				Header: map[string]string{"Accept": testcase.accept},
+			})
+			// Assert
+			if response.Code != testcase.statusCode {
+				t.Errorf("expected status code %d, got %d", testcase.statusCode, response.Code)
+				t.Logf("Body: %s", response.Body)
+			}
+			if contentType := response.Header().Get("Content-Type"); !strings.HasPrefix(contentType, testcase.contentType) {
+				t.Errorf("expected content type %s, got %s", testcase.contentType, contentType)
+			}
+		})
+	}
+}
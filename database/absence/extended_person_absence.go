@@ -2,37 +2,83 @@
 package absence
 
 import (
+	"mpt_data/helper/calendar"
 	"mpt_data/helper/errors"
+	"mpt_data/helper/rrule"
 	"mpt_data/models/dbmodel"
 	generalmodel "mpt_data/models/general"
+	"time"
 
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
 
-// AddRecurringAbsence stores recurring absence for person
+// AddRecurringAbsence stores recurring absence for person.
+// Returns ErrRecurringAbsenceExists, if the person has the same rule with the same validity.
+// NULL values are distinct in the unique index, so the validity is compared here
 func AddRecurringAbsence(absences []*dbmodel.PersonRecurringAbsence, db *gorm.DB) error {
+	for _, absence := range absences {
+		rule, err := rrule.Parse(absence.Rule)
+		if err != nil {
+			return err
+		}
+		var from, until *time.Time
+		if absence.ValidFrom != nil {
+			day := calendar.DayOf(*absence.ValidFrom)
+			from = &day
+		}
+		if absence.ValidUntil != nil {
+			day := calendar.DayOf(*absence.ValidUntil)
+			until = &day
+		}
+		var count int64
+		if err :=
+			db.Model(&dbmodel.PersonRecurringAbsence{}).
+				Where("person_id = ?", absence.PersonID).
+				Where("rule = ?", rule.String()).
+				Where("valid_from IS ?", from).
+				Where("valid_until IS ?", until).
+				Where("id <> ?", absence.ID).
+				Count(&count).Error; err != nil {
+			zap.L().Error(generalmodel.DBLoadDataFailed, zap.Error(err))
+			return err
+		}
+		if count != 0 {
+			return errors.ErrRecurringAbsenceExists
+		}
+	}
+
 	if err := db.Save(&absences).Error; err != nil {
-		zap.L().Error(generalmodel.DBSaveDataFailed, zap.Error(err))
+		if err != errors.ErrInvalidRecurrenceRule {
+			zap.L().Error(generalmodel.DBSaveDataFailed, zap.Error(err))
+		}
 		return err
 	}
 	return nil
 }
 
-// DeleteRecurringAbsence deletes recurring absence for person
+// DeleteRecurringAbsence deletes recurring absence for person, identified by ID or else by the rule
 func DeleteRecurringAbsence(absences []dbmodel.PersonRecurringAbsence, db *gorm.DB) error {
 	const funcName = packageName + ".DeleteRecurringAbsence"
 	for _, absence := range absences {
-		if absence.PersonID == 0 || absence.Weekday < 0 || absence.Weekday > 6 {
+		if absence.PersonID == 0 || (absence.ID == 0 && absence.Rule == "") {
 			return errors.ErrIDNotSet
 		}
 	}
 
 	for _, absence := range absences {
+		query := db.Where("person_id = ?", absence.PersonID)
+		if absence.ID != 0 {
+			query = query.Where("id = ?", absence.ID)
+		} else {
+			rule, err := rrule.Parse(absence.Rule)
+			if err != nil {
+				return err
+			}
+			query = query.Where("rule = ?", rule.String())
+		}
 		if err :=
-			db.Where("weekday = ?", absence.Weekday).
-				Where("person_id = ?", absence.PersonID).
-				Unscoped().Delete(&absence).Error; err != nil {
+			query.Unscoped().Delete(&dbmodel.PersonRecurringAbsence{}).Error; err != nil {
 			zap.L().Error(generalmodel.DBDeleteDataFailed, zap.Error(err))
 			return err
 		}
@@ -45,7 +91,7 @@ func GetRecurringAbsence(personID uint, db *gorm.DB) (absences []dbmodel.PersonR
 	if err :=
 		db.
 			Where("person_id = ?", personID).
-			Order("weekday asc").
+			Order("id asc").
 			Find(&absences).Error; err != nil {
 		zap.L().Error(generalmodel.DBLoadDataFailed, zap.Error(err), zap.Uint("person_id", personID))
 		return nil, err
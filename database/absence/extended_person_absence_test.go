@@ -2,44 +2,64 @@ package absence
 
 import (
 	"mpt_data/database"
+	"mpt_data/helper/errors"
 	dbModel "mpt_data/models/dbmodel"
-	database_test "mpt_data/test/database"
 	"testing"
+
+	"gorm.io/gorm"
 )
 
-var (
+// prepareRecurring creates a person with a recurring absence on sundays and tuesdays in db
+func prepareRecurring(t *testing.T, db *gorm.DB) (person dbModel.Person, recurring []*dbModel.PersonRecurringAbsence) {
+	person = dbModel.Person{GivenName: "Recurring", LastName: "Absence"}
+	if err := db.Create(&person).Error; err != nil {
+		t.Fatalf("test preparation failed: %v", err)
+	}
 	recurring = []*dbModel.PersonRecurringAbsence{
-		{
-			PersonID: 1,
-			Weekday:  0,
-		},
-		{
-			PersonID: 1,
-			Weekday:  2,
-		},
+		{PersonID: person.ID, Rule: "FREQ=WEEKLY;BYDAY=SU"},
+		{PersonID: person.ID, Rule: "FREQ=WEEKLY;BYDAY=TU"},
 	}
-)
+	if err := AddRecurringAbsence(recurring, db); err != nil {
+		t.Fatalf("test preparation failed: %v", err)
+	}
+	return person, recurring
+}
+
+// countRecurring counts the recurring absences of the person
+func countRecurring(db *gorm.DB, personID uint) (count int64) {
+	db.Model(&dbModel.PersonRecurringAbsence{}).Where("person_id = ?", personID).Count(&count)
+	return count
+}
 
 func TestAddRecurringAbsence(t *testing.T) {
 	// Prepare
+	db := database.DB.Begin()
+	t.Cleanup(func() {
+		db.Rollback()
+	})
+	person, _ := prepareRecurring(t, db)
+
 	var testcases = []struct {
 		name    string
 		absence []*dbModel.PersonRecurringAbsence
 		err     error
 		nums    int64
 	}{
-		{"success", recurring, nil, int64(len(recurring))},
+		{"success", []*dbModel.PersonRecurringAbsence{{PersonID: person.ID, Rule: "FREQ=MONTHLY;BYDAY=1SA"}}, nil, 1},
+		{"duplicate rule", []*dbModel.PersonRecurringAbsence{{PersonID: person.ID, Rule: "rrule:freq=weekly;byday=su"}}, errors.ErrRecurringAbsenceExists, 0},
+		{"invalid rule", []*dbModel.PersonRecurringAbsence{{PersonID: person.ID, Rule: "FREQ=HOURLY"}}, errors.ErrInvalidRecurrenceRule, 0},
 	}
 	for _, testcase := range testcases {
-		db := database.DB
 		t.Run(testcase.name, func(t *testing.T) {
-			countBefore := database_test.CountEntriesDB(db, &dbModel.PersonRecurringAbsence{})
+			db.SavePoint("beforeAdd")
+			defer db.RollbackTo("beforeAdd")
+			countBefore := countRecurring(db, person.ID)
 			// Act
 			err := AddRecurringAbsence(testcase.absence, db)
-			countAfter := database_test.CountEntriesDB(db, &dbModel.PersonRecurringAbsence{})
+			countAfter := countRecurring(db, person.ID)
 			// Assert
 			if err != testcase.err {
-				t.Errorf("expected %s, got %s", testcase.err, err)
+				t.Errorf("expected %v, got %v", testcase.err, err)
 			}
 			if countAfter-countBefore != testcase.nums {
 				t.Errorf("expected %d entries created, got %d", testcase.nums, countAfter-countBefore)
@@ -50,58 +70,70 @@ func TestAddRecurringAbsence(t *testing.T) {
 
 func TestGetRecurringAbsence(t *testing.T) {
 	// Prepare
+	db := database.DB.Begin()
+	t.Cleanup(func() {
+		db.Rollback()
+	})
+	person, recurring := prepareRecurring(t, db)
+
 	var testcases = []struct {
-		name    string
-		absence []*dbModel.PersonRecurringAbsence
-		err     error
-		nums    int64
+		name     string
+		personID uint
+		err      error
+		nums     int
 	}{
-		{"success", recurring, nil, int64(len(recurring))},
+		{"success", person.ID, nil, len(recurring)},
+		{"other person", person.ID + 1, nil, 0},
 	}
 	for _, testcase := range testcases {
-		db := database.DB.Begin()
 		t.Run(testcase.name, func(t *testing.T) {
 			// Act
-			_, err := GetRecurringAbsence(testcase.absence[0].PersonID, db)
-			count := database_test.CountEntriesDB(db, &dbModel.PersonRecurringAbsence{})
+			absences, err := GetRecurringAbsence(testcase.personID, db)
 			// Assert
 			if err != testcase.err {
-				t.Errorf("expected %s, got %s", testcase.err, err)
+				t.Errorf("expected %v, got %v", testcase.err, err)
 			}
-			if count != testcase.nums {
-				t.Errorf("expected %d entries created, got %d", testcase.nums, count)
+			if len(absences) != testcase.nums {
+				t.Errorf("expected %d entries, got %d", testcase.nums, len(absences))
 			}
-
 		})
-		db.Rollback()
 	}
 }
 
 func TestDeleteRecurringAbsence(t *testing.T) {
 	// Prepare
+	db := database.DB.Begin()
+	t.Cleanup(func() {
+		db.Rollback()
+	})
+	person, recurring := prepareRecurring(t, db)
+
 	var testcases = []struct {
 		name    string
 		absence []dbModel.PersonRecurringAbsence
 		err     error
+		nums    int64
 	}{
-		{"success", []dbModel.PersonRecurringAbsence{*recurring[0], *recurring[1]}, nil},
+		{"by id", []dbModel.PersonRecurringAbsence{{ID: recurring[0].ID, PersonID: person.ID}}, nil, 1},
+		{"by rule", []dbModel.PersonRecurringAbsence{{PersonID: person.ID, Rule: "FREQ=WEEKLY;BYDAY=TU"}}, nil, 1},
+		{"both", []dbModel.PersonRecurringAbsence{*recurring[0], *recurring[1]}, nil, 2},
+		{"no person", []dbModel.PersonRecurringAbsence{{ID: recurring[0].ID}}, errors.ErrIDNotSet, 0},
 	}
 	for _, testcase := range testcases {
-		db := database.DB.Begin()
 		t.Run(testcase.name, func(t *testing.T) {
-			countBefore := database_test.CountEntriesDB(db, &dbModel.PersonRecurringAbsence{})
+			db.SavePoint("beforeDelete")
+			defer db.RollbackTo("beforeDelete")
+			countBefore := countRecurring(db, person.ID)
 			// Act
 			err := DeleteRecurringAbsence(testcase.absence, db)
-			count := database_test.CountEntriesDB(db, &dbModel.PersonRecurringAbsence{})
+			countAfter := countRecurring(db, person.ID)
 			// Assert
 			if err != testcase.err {
-				t.Errorf("expected %s, got %s", testcase.err, err)
+				t.Errorf("expected %v, got %v", testcase.err, err)
 			}
-			if countBefore-count != 2 {
-				t.Errorf("expected %d entries deleted, got %d", 2, countBefore-count)
+			if countBefore-countAfter != testcase.nums {
+				t.Errorf("expected %d entries deleted, got %d", testcase.nums, countBefore-countAfter)
 			}
-
 		})
-		db.Rollback()
 	}
 }
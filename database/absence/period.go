@@ -0,0 +1,58 @@
+// Package absence provides CRUD for absences
+package absence
+
+import (
+	"mpt_data/helper/calendar"
+	"mpt_data/helper/errors"
+	"mpt_data/models/dbmodel"
+	generalmodel "mpt_data/models/general"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// AddAbsencePeriods stores absence periods, the person of every period must be set
+func AddAbsencePeriods(db *gorm.DB, periods []dbmodel.PersonAbsencePeriod) ([]dbmodel.PersonAbsencePeriod, error) {
+	if len(periods) == 0 {
+		return nil, gorm.ErrEmptySlice
+	}
+	if err := db.Create(&periods).Error; err != nil {
+		if err != errors.ErrInvalidAbsencePeriod {
+			zap.L().Error(generalmodel.DBSaveDataFailed, zap.Error(err))
+		}
+		return nil, err
+	}
+	return periods, nil
+}
+
+// DeleteAbsencePeriods deletes the absence periods of person with the specified ids
+func DeleteAbsencePeriods(db *gorm.DB, personID uint, ids []uint) error {
+	if personID == 0 || len(ids) == 0 {
+		return errors.ErrIDNotSet
+	}
+	result := db.Where("person_id = ?", personID).
+		Where("id IN (?)", ids).
+		Unscoped().Delete(&dbmodel.PersonAbsencePeriod{})
+	if result.Error != nil {
+		zap.L().Error(generalmodel.DBDeleteDataFailed, zap.Error(result.Error))
+		return result.Error
+	}
+	if result.RowsAffected != int64(len(ids)) {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// GetAbsencePeriods loads the absence periods of person, which overlap the specified period, ordered by start
+func GetAbsencePeriods(db *gorm.DB, personID uint, period generalmodel.Period) (periods []dbmodel.PersonAbsencePeriod, err error) {
+	if err :=
+		db.Where("person_id = ?", personID).
+			Where("from_date <= ?", calendar.DayOf(period.EndDate)).
+			Where("to_date >= ?", calendar.DayOf(period.StartDate)).
+			Order("from_date asc").
+			Find(&periods).Error; err != nil {
+		zap.L().Error(generalmodel.DBLoadDataFailed, zap.Error(err), zap.Uint("person_id", personID))
+		return nil, err
+	}
+	return periods, nil
+}
@@ -0,0 +1,114 @@
+package absence
+
+import (
+	"mpt_data/database"
+	"mpt_data/helper/errors"
+	dbModel "mpt_data/models/dbmodel"
+	generalmodel "mpt_data/models/general"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func TestAbsencePeriods(t *testing.T) {
+	// Prepare
+	db := database.DB.Begin()
+	t.Cleanup(func() {
+		db.Rollback()
+	})
+	person := dbModel.Person{GivenName: "Anna", LastName: "Period"}
+	if err := db.Create(&person).Error; err != nil {
+		t.Fatalf("test preparation failed: %v", err)
+	}
+	july := generalmodel.Period{
+		StartDate: time.Date(2006, 7, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2006, 7, 31, 0, 0, 0, 0, time.UTC),
+	}
+
+	var testcases = []struct {
+		name    string
+		periods []dbModel.PersonAbsencePeriod
+		err     error
+		found   int
+	}{
+		{
+			name: "overlapping start",
+			periods: []dbModel.PersonAbsencePeriod{{
+				PersonID: person.ID,
+				From:     time.Date(2006, 6, 20, 0, 0, 0, 0, time.UTC),
+				To:       time.Date(2006, 7, 1, 18, 0, 0, 0, time.UTC),
+			}},
+			found: 1,
+		},
+		{
+			name: "outside",
+			periods: []dbModel.PersonAbsencePeriod{{
+				PersonID: person.ID,
+				From:     time.Date(2006, 8, 1, 0, 0, 0, 0, time.UTC),
+				To:       time.Date(2006, 8, 14, 0, 0, 0, 0, time.UTC),
+			}},
+		},
+		{
+			name: "single day",
+			periods: []dbModel.PersonAbsencePeriod{{
+				PersonID: person.ID,
+				From:     time.Date(2006, 7, 31, 12, 0, 0, 0, time.UTC),
+				To:       time.Date(2006, 7, 31, 9, 0, 0, 0, time.UTC),
+			}},
+			found: 1,
+		},
+		{
+			name: "to before from",
+			periods: []dbModel.PersonAbsencePeriod{{
+				PersonID: person.ID,
+				From:     time.Date(2006, 7, 14, 0, 0, 0, 0, time.UTC),
+				To:       time.Date(2006, 7, 1, 0, 0, 0, 0, time.UTC),
+			}},
+			err: errors.ErrInvalidAbsencePeriod,
+		},
+		{
+			name: "to missing",
+			periods: []dbModel.PersonAbsencePeriod{{
+				PersonID: person.ID,
+				From:     time.Date(2006, 7, 14, 0, 0, 0, 0, time.UTC),
+			}},
+			err: errors.ErrInvalidAbsencePeriod,
+		},
+		{
+			name: "empty",
+			err:  gorm.ErrEmptySlice,
+		},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			db.SavePoint("beforePeriod")
+			defer db.RollbackTo("beforePeriod")
+
+			// Act
+			periods, err := AddAbsencePeriods(db, testcase.periods)
+
+			// Assert
+			if err != testcase.err {
+				t.Fatalf("expected %v, got %v", testcase.err, err)
+			}
+			if err != nil {
+				return
+			}
+			found, err := GetAbsencePeriods(db, person.ID, july)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if len(found) != testcase.found {
+				t.Fatalf("expected %d periods, got %d", testcase.found, len(found))
+			}
+			if err := DeleteAbsencePeriods(db, person.ID, []uint{periods[0].ID}); err != nil {
+				t.Errorf("expected deletion, got %v", err)
+			}
+			if err := DeleteAbsencePeriods(db, person.ID, []uint{periods[0].ID}); err != gorm.ErrRecordNotFound {
+				t.Errorf("expected %v for second deletion, got %v", gorm.ErrRecordNotFound, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+// Package auth provides functionallity for authentication and authorisation
+package auth
+
+import (
+	"mpt_data/helper/errors"
+	apiModel "mpt_data/models/apimodel"
+	dbModel "mpt_data/models/dbmodel"
+	generalmodel "mpt_data/models/general"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// RegisterUser creates a user with role, linked to the person if set
+func RegisterUser(db *gorm.DB, user apiModel.User) (dbModel.User, error) {
+	if user.Username == "" || user.Password == "" {
+		return dbModel.User{}, errors.ErrUserNotComplete
+	}
+	if err := checkLink(db, 0, user.Role, user.PersonID); err != nil {
+		return dbModel.User{}, err
+	}
+
+	dbUser := dbModel.User{Username: user.Username, Role: user.Role, PersonID: user.PersonID}
+	username, err := dbUser.EncryptedUsername()
+	if err != nil {
+		return dbModel.User{}, err
+	}
+	var count int64
+	if err := db.Model(&dbModel.User{}).Where("username = ?", username).Count(&count).Error; err != nil {
+		return dbModel.User{}, err
+	}
+	if count != 0 {
+		return dbModel.User{}, errors.ErrUserAlreadyExists
+	}
+
+	hash, err := hash(user.Password)
+	if err != nil {
+		return dbModel.User{}, err
+	}
+	dbUser.Hash = string(hash)
+	if err := db.Create(&dbUser).Error; err != nil {
+		zap.L().Error(generalmodel.DBSaveDataFailed, zap.Error(err))
+		return dbModel.User{}, err
+	}
+	return GetUser(db, dbUser.ID)
+}
+
+// UpdateUserLink changes the role of the user and the person it is linked to
+func UpdateUserLink(db *gorm.DB, userID uint, role string, personID *uint) (dbModel.User, error) {
+	if _, err := GetUser(db, userID); err != nil {
+		return dbModel.User{}, err
+	}
+	if err := checkLink(db, userID, role, personID); err != nil {
+		return dbModel.User{}, err
+	}
+	// the table is used, to skip the encryption hooks of the username
+	if err :=
+		db.Table("users").
+			Where("id = ?", userID).
+			Updates(map[string]interface{}{"role": role, "person_id": personID}).Error; err != nil {
+		zap.L().Error(generalmodel.DBSaveDataFailed, zap.Error(err))
+		return dbModel.User{}, err
+	}
+	return GetUser(db, userID)
+}
+
+// GetUser loads the user with the specified id
+func GetUser(db *gorm.DB, userID uint) (user dbModel.User, err error) {
+	if err := db.First(&user, userID).Error; err != nil {
+		return dbModel.User{}, err
+	}
+	return user, nil
+}
+
+// GetUsers loads all users
+func GetUsers(db *gorm.DB) (users []dbModel.User, err error) {
+	if err := db.Order("id asc").Find(&users).Error; err != nil {
+		zap.L().Error(generalmodel.DBLoadDataFailed, zap.Error(err))
+		return nil, err
+	}
+	return users, nil
+}
+
+// checkLink validates the role and the person of a user.
+// A self user needs a person, which exists and is not linked to another user
+func checkLink(db *gorm.DB, userID uint, role string, personID *uint) error {
+	switch role {
+	case dbModel.RoleAdmin, dbModel.RoleSelf:
+	default:
+		return errors.ErrInvalidRole
+	}
+	if personID == nil {
+		if role == dbModel.RoleSelf {
+			return errors.ErrUserNotLinked
+		}
+		return nil
+	}
+
+	var count int64
+	if err := db.Model(&dbModel.Person{}).Where("id = ?", *personID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	if err :=
+		db.Model(&dbModel.User{}).
+			Where("person_id = ?", *personID).
+			Where("id <> ?", userID).
+			Count(&count).Error; err != nil {
+		return err
+	}
+	if count != 0 {
+		return errors.ErrPersonAlreadyLinked
+	}
+	return nil
+}
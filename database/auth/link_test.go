@@ -0,0 +1,83 @@
+package auth
+
+import (
+	"mpt_data/database"
+	"mpt_data/helper/errors"
+	apiModel "mpt_data/models/apimodel"
+	dbModel "mpt_data/models/dbmodel"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func TestUserLink(t *testing.T) {
+	// Prepare
+	db := database.DB.Begin()
+	t.Cleanup(func() {
+		db.Rollback()
+	})
+	people := []dbModel.Person{
+		{GivenName: "Anna", LastName: "Link"},
+		{GivenName: "Ben", LastName: "Link"},
+	}
+	if err := db.Create(&people).Error; err != nil {
+		t.Fatalf("test preparation failed: %v", err)
+	}
+	linked, err := RegisterUser(db, apiModel.User{Username: "anna.link", Password: "secret", Role: dbModel.RoleSelf, PersonID: &people[0].ID})
+	if err != nil {
+		t.Fatalf("test preparation failed: %v", err)
+	}
+	unknown := uint(0xFFFFFF)
+
+	var testcases = []struct {
+		name     string
+		user     apiModel.User
+		register bool
+		err      error
+	}{
+		{"register self", apiModel.User{Username: "ben.link", Password: "secret", Role: dbModel.RoleSelf, PersonID: &people[1].ID}, true, nil},
+		{"register admin", apiModel.User{Username: "admin.link", Password: "secret", Role: dbModel.RoleAdmin}, true, nil},
+		{"register duplicate", apiModel.User{Username: "anna.link", Password: "secret", Role: dbModel.RoleAdmin}, true, errors.ErrUserAlreadyExists},
+		{"register without password", apiModel.User{Username: "no.password", Role: dbModel.RoleAdmin}, true, errors.ErrUserNotComplete},
+		{"register linked person", apiModel.User{Username: "other.link", Password: "secret", Role: dbModel.RoleSelf, PersonID: &people[0].ID}, true, errors.ErrPersonAlreadyLinked},
+		{"self without person", apiModel.User{Role: dbModel.RoleSelf}, false, errors.ErrUserNotLinked},
+		{"unknown role", apiModel.User{Role: "owner"}, false, errors.ErrInvalidRole},
+		{"unknown person", apiModel.User{Role: dbModel.RoleSelf, PersonID: &unknown}, false, gorm.ErrRecordNotFound},
+		{"relink same person", apiModel.User{Role: dbModel.RoleSelf, PersonID: &people[0].ID}, false, nil},
+		{"unlink as admin", apiModel.User{Role: dbModel.RoleAdmin}, false, nil},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			db.SavePoint("beforeUser")
+			defer db.RollbackTo("beforeUser")
+
+			// Act
+			var user dbModel.User
+			var err error
+			if testcase.register {
+				user, err = RegisterUser(db, testcase.user)
+			} else {
+				user, err = UpdateUserLink(db, linked.ID, testcase.user.Role, testcase.user.PersonID)
+			}
+
+			// Assert
+			if err != testcase.err {
+				t.Fatalf("expected %v, got %v", testcase.err, err)
+			}
+			if err != nil {
+				return
+			}
+			if user.Role != testcase.user.Role {
+				t.Errorf("expected role %s, got %s", testcase.user.Role, user.Role)
+			}
+			if (user.PersonID == nil) != (testcase.user.PersonID == nil) ||
+				(user.PersonID != nil && *user.PersonID != *testcase.user.PersonID) {
+				t.Errorf("expected person %v, got %v", testcase.user.PersonID, user.PersonID)
+			}
+			if testcase.register && user.Username != testcase.user.Username {
+				t.Errorf("expected username %s, got %s", testcase.user.Username, user.Username)
+			}
+		})
+	}
+}
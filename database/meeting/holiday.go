@@ -0,0 +1,73 @@
+package meeting
+
+import (
+	"mpt_data/helper/calendar"
+	"mpt_data/helper/holiday"
+	"mpt_data/models/apimodel"
+	dbModel "mpt_data/models/dbmodel"
+	generalmodel "mpt_data/models/general"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// GetHolidayMeetings loads all meetings in period, which are at a holiday of the configured region or ICS file
+func GetHolidayMeetings(db *gorm.DB, period generalmodel.Period) ([]apimodel.HolidayMeeting, error) {
+	var meetings []dbModel.Meeting
+	if err :=
+		db.Preload("Tag").
+			Where("date between ? and ?", period.StartDate, period.EndDate).
+			Order("date asc").
+			Find(&meetings).Error; err != nil {
+		return nil, err
+	}
+	return holidayMeetings(meetings)
+}
+
+// tagHolidays creates a tag with the name of the holiday for all meetings at a holiday, which have no tag yet.
+// If the holidays are configured to be only flagged, no tags are created.
+// Returns the meetings at a holiday
+func tagHolidays(db *gorm.DB, meetings []dbModel.Meeting) ([]apimodel.HolidayMeeting, error) {
+	atHoliday, err := holidayMeetings(meetings)
+	if err != nil || !holiday.Tagging() {
+		return atHoliday, err
+	}
+
+	for i, meeting := range atHoliday {
+		if meeting.Meeting.TagID != 0 || meeting.Meeting.Tag.ID != 0 {
+			continue
+		}
+		tag := dbModel.Tag{Descr: meeting.Holiday}
+		if err := CreateTag(db, meeting.Meeting.ID, tag); err != nil {
+			return atHoliday, err
+		}
+		if err := db.Preload("Tag").First(&atHoliday[i].Meeting, meeting.Meeting.ID).Error; err != nil {
+			return atHoliday, err
+		}
+	}
+	return atHoliday, nil
+}
+
+// holidayMeetings returns the meetings at a holiday of the configured provider
+func holidayMeetings(meetings []dbModel.Meeting) (atHoliday []apimodel.HolidayMeeting, err error) {
+	provider, err := holiday.FromConfig()
+	if err != nil || provider == nil {
+		return nil, err
+	}
+
+	dates := make([]time.Time, 0, len(meetings))
+	for _, meeting := range meetings {
+		dates = append(dates, meeting.Date)
+	}
+	holidays, err := holiday.OnDates(provider, dates)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, meeting := range meetings {
+		if found := holidays[calendar.DayOf(meeting.Date)]; len(found) != 0 {
+			atHoliday = append(atHoliday, apimodel.HolidayMeeting{Meeting: meeting, Holiday: holiday.Names(found)})
+		}
+	}
+	return atHoliday, nil
+}
@@ -0,0 +1,69 @@
+package meeting
+
+import (
+	"mpt_data/database"
+	"mpt_data/helper/config"
+	"mpt_data/helper/holiday"
+	dbModel "mpt_data/models/dbmodel"
+	generalmodel "mpt_data/models/general"
+	"testing"
+	"time"
+)
+
+func TestTagHolidays(t *testing.T) {
+	// Prepare
+	db := database.DB.Begin()
+	holidayConfig := config.Config.Holiday
+	t.Cleanup(func() {
+		db.Rollback()
+		config.Config.Holiday = holidayConfig
+	})
+	config.Config.Holiday.Region = "DE"
+	config.Config.Holiday.ICS = ""
+	christmas := time.Date(2008, 12, 25, 0, 0, 0, 0, time.UTC)
+	period := generalmodel.Period{StartDate: time.Date(2008, 12, 1, 0, 0, 0, 0, time.UTC), EndDate: time.Date(2008, 12, 31, 0, 0, 0, 0, time.UTC)}
+	series := dbModel.MeetingSeries{Period: period, Frequency: dbModel.SeriesWeekly, Weekday: int(time.Thursday)}
+	if err := AddMeetingSeries(db, &series); err != nil {
+		t.Fatalf("test preparation failed: %v", err)
+	}
+
+	var testcases = []struct {
+		name   string
+		mode   string
+		tagged bool
+	}{
+		{"tag", holiday.ModeTag, true},
+		{"flag", holiday.ModeFlag, false},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			db.SavePoint("beforeHolidays")
+			defer db.RollbackTo("beforeHolidays")
+			config.Config.Holiday.Mode = testcase.mode
+			// Act
+			result, err := MaterializeMeetingSeries(db, series.ID, period)
+			// Assert
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if len(result.Holidays) != 1 || !result.Holidays[0].Meeting.Date.Equal(christmas) || result.Holidays[0].Holiday != "1. Weihnachtstag" {
+				t.Fatalf("expected christmas as holiday, got %v", result.Holidays)
+			}
+			var meeting dbModel.Meeting
+			if err := db.Preload("Tag").Where("date = ?", christmas).First(&meeting).Error; err != nil {
+				t.Fatalf("expected meeting at christmas, got %v", err)
+			}
+			if tagged := meeting.Tag.ID != 0; tagged != testcase.tagged {
+				t.Errorf("expected tagged %t, got tag %v", testcase.tagged, meeting.Tag)
+			}
+			if testcase.tagged && meeting.Tag.Descr != "1. Weihnachtstag" {
+				t.Errorf("expected tag 1. Weihnachtstag, got %s", meeting.Tag.Descr)
+			}
+			flagged, err := GetHolidayMeetings(db, period)
+			if err != nil || len(flagged) != 1 {
+				t.Errorf("expected one meeting at a holiday, got %v, %v", flagged, err)
+			}
+		})
+	}
+}
@@ -7,6 +7,7 @@ import (
 	dbModel "mpt_data/models/dbmodel"
 	generalmodel "mpt_data/models/general"
 
+	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
 
@@ -21,7 +22,8 @@ func GetMeetings(period generalmodel.Period) (meetings []dbModel.Meeting, err er
 	return meetings, err
 }
 
-// AddMeetings creates all passed meetings in db, if doesnt exists already
+// AddMeetings creates all passed meetings in db, if doesnt exists already.
+// Meetings at a holiday are tagged, if configured
 func AddMeetings(meetings []dbModel.Meeting) (err error) {
 
 	db := database.DB.Begin()
@@ -34,6 +36,13 @@ func AddMeetings(meetings []dbModel.Meeting) (err error) {
 		return result.Error
 	}
 
+	// a failed tagging does not prevent the creation of the meetings
+	db.SavePoint("beforeHolidays")
+	if _, err := tagHolidays(db, meetings); err != nil {
+		db.RollbackTo("beforeHolidays")
+		zap.L().Error(generalmodel.HolidayTaggingFailed, zap.Error(err))
+	}
+
 	if result.RowsAffected != int64(len(meetings)) {
 		err = errors.ErrNotAllMeetingsCreated
 	}
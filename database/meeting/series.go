@@ -0,0 +1,177 @@
+package meeting
+
+import (
+	"mpt_data/helper/calendar"
+	"mpt_data/models/apimodel"
+	dbModel "mpt_data/models/dbmodel"
+	generalmodel "mpt_data/models/general"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// GetMeetingSeries loads all meeting series with their exceptions
+func GetMeetingSeries(db *gorm.DB) (series []dbModel.MeetingSeries, err error) {
+	if err := db.Preload("Exceptions").Order("id").Find(&series).Error; err != nil {
+		return nil, err
+	}
+	return series, nil
+}
+
+// GetMeetingSeriesByID loads one meeting series with its exceptions
+func GetMeetingSeriesByID(db *gorm.DB, id uint) (series dbModel.MeetingSeries, err error) {
+	err = db.Preload("Exceptions").First(&series, id).Error
+	return series, err
+}
+
+// AddMeetingSeries adds a meeting series, no meetings are created until the series is materialized
+func AddMeetingSeries(db *gorm.DB, series *dbModel.MeetingSeries) error {
+	series.ID = 0
+	for i := range series.Exceptions {
+		series.Exceptions[i].ID = 0
+	}
+	if err := db.Create(series).Error; err != nil {
+		zap.L().Error(generalmodel.DBSaveDataFailed, zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// UpdateMeetingSeries changes the rule of a meeting series and replaces its exceptions.
+// Meetings already created by the series are not changed
+func UpdateMeetingSeries(db *gorm.DB, series *dbModel.MeetingSeries) error {
+	existing, err := GetMeetingSeriesByID(db, series.ID)
+	if err != nil {
+		return err
+	}
+	series.Model = existing.Model
+	for i := range series.Exceptions {
+		series.Exceptions[i].ID = 0
+		series.Exceptions[i].MeetingSeriesID = series.ID
+	}
+
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("meeting_series_id = ?", series.ID).Delete(&dbModel.MeetingSeriesException{}).Error; err != nil {
+			return err
+		}
+		return tx.Save(series).Error
+	}); err != nil {
+		zap.L().Error(generalmodel.DBUpdateDataFailed, zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// DeleteMeetingSeries deletes a meeting series and its exceptions, meetings created by the series are kept
+func DeleteMeetingSeries(db *gorm.DB, id uint) error {
+	result := db.Unscoped().Delete(&dbModel.MeetingSeries{}, id)
+	if result.Error != nil {
+		zap.L().Error(generalmodel.DBDeleteDataFailed, zap.Error(result.Error))
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return db.Unscoped().Where("meeting_series_id = ?", id).Delete(&dbModel.MeetingSeriesException{}).Error
+}
+
+// MaterializeMeetingSeries creates the meetings of a series in period, which do not exist yet.
+// A meeting exists, if there is any meeting at the same day, so materializing a period again creates nothing.
+// Created meetings at a holiday are tagged, if configured, all meetings at a holiday are reported
+func MaterializeMeetingSeries(db *gorm.DB, id uint, period generalmodel.Period) (result apimodel.MaterializedMeetings, err error) {
+	series, err := GetMeetingSeriesByID(db, id)
+	if err != nil {
+		return result, err
+	}
+
+	for _, date := range occurrences(series, period) {
+		var existing []dbModel.Meeting
+		if err :=
+			db.Preload("Tag").
+				Where("date >= ? AND date < ?", calendar.DayOf(date), calendar.DayOf(date).AddDate(0, 0, 1)).
+				Find(&existing).Error; err != nil {
+			return result, err
+		}
+		if len(existing) != 0 {
+			result.Existing = append(result.Existing, existing...)
+			continue
+		}
+
+		meeting := dbModel.Meeting{Date: date, MeetingTypeID: series.MeetingTypeID}
+		if err := db.Create(&meeting).Error; err != nil {
+			zap.L().Error(generalmodel.DBSaveDataFailed, zap.Error(err))
+			return result, err
+		}
+		result.Created = append(result.Created, meeting)
+	}
+
+	// a failed tagging does not prevent the creation of the meetings
+	db.SavePoint("beforeHolidays")
+	created, err := tagHolidays(db, result.Created)
+	if err != nil {
+		db.RollbackTo("beforeHolidays")
+		zap.L().Error(generalmodel.HolidayTaggingFailed, zap.Error(err))
+		return result, nil
+	}
+	existing, err := holidayMeetings(result.Existing)
+	if err != nil {
+		zap.L().Error(generalmodel.HolidayTaggingFailed, zap.Error(err))
+		return result, nil
+	}
+	result.Holidays = append(created, existing...)
+	return result, nil
+}
+
+// occurrences calculates the dates of all meetings of series, which are in period and not an exception
+func occurrences(series dbModel.MeetingSeries, period generalmodel.Period) (dates []time.Time) {
+	first, last := calendar.DayOf(series.StartDate), calendar.DayOf(series.EndDate)
+	if start := calendar.DayOf(period.StartDate); start.After(first) {
+		first = start
+	}
+	if end := calendar.DayOf(period.EndDate); end.Before(last) {
+		last = end
+	}
+
+	var timeOfDay time.Duration
+	if clock, err := time.Parse(dbModel.TimeOfDayFormat, series.TimeOfDay); err == nil {
+		timeOfDay = time.Duration(clock.Hour())*time.Hour + time.Duration(clock.Minute())*time.Minute
+	}
+	exceptions := make(map[time.Time]bool, len(series.Exceptions))
+	for _, exception := range series.Exceptions {
+		exceptions[calendar.DayOf(exception.Date)] = true
+	}
+	interval := int(max(series.Interval, 1))
+
+	add := func(day time.Time) {
+		if !day.Before(first) && !day.After(last) && !exceptions[day] {
+			dates = append(dates, day.Add(timeOfDay))
+		}
+	}
+
+	// the counting of weeks and months starts at the start of the series, not of period
+	start := calendar.DayOf(series.StartDate)
+	switch series.Frequency {
+	case dbModel.SeriesWeekly:
+		day := start.AddDate(0, 0, (series.Weekday-int(start.Weekday())+7)%7)
+		for ; !day.After(last); day = day.AddDate(0, 0, 7*interval) {
+			add(day)
+		}
+	case dbModel.SeriesMonthly:
+		month := time.Date(start.Year(), start.Month(), 1, 0, 0, 0, 0, time.UTC)
+		for ; !month.After(last); month = month.AddDate(0, interval, 0) {
+			add(weekdayOfMonth(month, time.Weekday(series.Weekday), series.Ordinal))
+		}
+	}
+	return dates
+}
+
+// weekdayOfMonth returns the ordinal weekday in the month starting at month, the last one for ordinal -1
+func weekdayOfMonth(month time.Time, weekday time.Weekday, ordinal int) time.Time {
+	if ordinal < 0 {
+		last := month.AddDate(0, 1, -1)
+		return last.AddDate(0, 0, -((int(last.Weekday()) - int(weekday) + 7) % 7))
+	}
+	first := month.AddDate(0, 0, (int(weekday)-int(month.Weekday())+7)%7)
+	return first.AddDate(0, 0, 7*(ordinal-1))
+}
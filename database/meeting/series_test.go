@@ -0,0 +1,125 @@
+package meeting
+
+import (
+	"mpt_data/database"
+	"mpt_data/helper/errors"
+	dbModel "mpt_data/models/dbmodel"
+	generalmodel "mpt_data/models/general"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestOccurrences(t *testing.T) {
+	date := func(month time.Month, day int) time.Time {
+		return time.Date(2024, month, day, 0, 0, 0, 0, time.UTC)
+	}
+	series := generalmodel.Period{StartDate: date(1, 1), EndDate: date(3, 31)}
+	var testcases = []struct {
+		name     string
+		series   dbModel.MeetingSeries
+		period   generalmodel.Period
+		expected []time.Time
+	}{
+		{
+			name:     "weekly on sunday",
+			series:   dbModel.MeetingSeries{Period: series, Frequency: dbModel.SeriesWeekly, Interval: 1, Weekday: 0},
+			period:   generalmodel.Period{StartDate: date(1, 1), EndDate: date(1, 31)},
+			expected: []time.Time{date(1, 7), date(1, 14), date(1, 21), date(1, 28)},
+		},
+		{
+			name:     "every second wednesday counted from start of series",
+			series:   dbModel.MeetingSeries{Period: series, Frequency: dbModel.SeriesWeekly, Interval: 2, Weekday: 3},
+			period:   generalmodel.Period{StartDate: date(1, 10), EndDate: date(2, 10)},
+			expected: []time.Time{date(1, 17), date(1, 31)},
+		},
+		{
+			name:     "first friday of the month",
+			series:   dbModel.MeetingSeries{Period: series, Frequency: dbModel.SeriesMonthly, Interval: 1, Weekday: 5, Ordinal: 1},
+			period:   series,
+			expected: []time.Time{date(1, 5), date(2, 2), date(3, 1)},
+		},
+		{
+			name:     "last monday of every second month",
+			series:   dbModel.MeetingSeries{Period: series, Frequency: dbModel.SeriesMonthly, Interval: 2, Weekday: 1, Ordinal: -1},
+			period:   series,
+			expected: []time.Time{date(1, 29), date(3, 25)},
+		},
+		{
+			name: "time of day and exception",
+			series: dbModel.MeetingSeries{
+				Period: series, Frequency: dbModel.SeriesWeekly, Interval: 1, Weekday: 0, TimeOfDay: "10:30",
+				Exceptions: []dbModel.MeetingSeriesException{{Date: date(1, 14)}},
+			},
+			period:   generalmodel.Period{StartDate: date(1, 1), EndDate: date(1, 20)},
+			expected: []time.Time{date(1, 7).Add(10*time.Hour + 30*time.Minute)},
+		},
+		{
+			name:   "period outside of series",
+			series: dbModel.MeetingSeries{Period: series, Frequency: dbModel.SeriesWeekly, Interval: 1, Weekday: 0},
+			period: generalmodel.Period{StartDate: date(4, 1), EndDate: date(4, 30)},
+		},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			// Act
+			dates := occurrences(testcase.series, testcase.period)
+			// Assert
+			if !reflect.DeepEqual(dates, testcase.expected) {
+				t.Errorf("expected %v, got %v", testcase.expected, dates)
+			}
+		})
+	}
+}
+
+func TestMaterializeMeetingSeries(t *testing.T) {
+	// Prepare
+	db := database.DB.Begin()
+	t.Cleanup(func() { db.Rollback() })
+	period := generalmodel.Period{
+		StartDate: time.Date(2007, 1, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2007, 1, 31, 0, 0, 0, 0, time.UTC),
+	}
+	invalid := dbModel.MeetingSeries{Period: period, Frequency: dbModel.SeriesWeekly, Weekday: 0, Ordinal: 1}
+	if err := AddMeetingSeries(db, &invalid); err != errors.ErrInvalidMeetingSeries {
+		t.Errorf("expected %v, got %v", errors.ErrInvalidMeetingSeries, err)
+	}
+	series := dbModel.MeetingSeries{Period: period, Frequency: dbModel.SeriesWeekly, Weekday: 0, TimeOfDay: "10:00"}
+	if err := AddMeetingSeries(db, &series); err != nil {
+		t.Fatalf("test preparation failed: %v", err)
+	}
+	// a meeting at midnight on the second sunday already exists
+	if err := db.Create(&dbModel.Meeting{Date: time.Date(2007, 1, 14, 0, 0, 0, 0, time.UTC)}).Error; err != nil {
+		t.Fatalf("test preparation failed: %v", err)
+	}
+
+	// Act
+	first, err := MaterializeMeetingSeries(db, series.ID, period)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	second, err := MaterializeMeetingSeries(db, series.ID, period)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	// Assert
+	if len(first.Created) != 3 || len(first.Existing) != 1 {
+		t.Errorf("expected 3 created and 1 existing meetings, got %d and %d", len(first.Created), len(first.Existing))
+	}
+	if len(first.Created) > 0 && first.Created[0].Date.Hour() != 10 {
+		t.Errorf("expected meeting at 10:00, got %v", first.Created[0].Date)
+	}
+	if len(second.Created) != 0 || len(second.Existing) != 4 {
+		t.Errorf("expected 0 created and 4 existing meetings, got %d and %d", len(second.Created), len(second.Existing))
+	}
+	if err := DeleteMeetingSeries(db, series.ID); err != nil {
+		t.Errorf("expected no error on delete, got %v", err)
+	}
+	var meetings int64
+	db.Model(&dbModel.Meeting{}).Where("date between ? and ?", period.StartDate, period.EndDate.AddDate(0, 0, 1)).Count(&meetings)
+	if meetings != 4 {
+		t.Errorf("expected meetings to be kept after delete, got %d", meetings)
+	}
+}
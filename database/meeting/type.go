@@ -0,0 +1,116 @@
+package meeting
+
+import (
+	"mpt_data/helper/errors"
+	dbModel "mpt_data/models/dbmodel"
+	generalmodel "mpt_data/models/general"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// GetMeetingTypes loads all meeting types with their TaskDetails
+func GetMeetingTypes(db *gorm.DB) (types []dbModel.MeetingType, err error) {
+	if err := db.Preload("TaskDetails").Order("id").Find(&types).Error; err != nil {
+		return nil, err
+	}
+	return types, nil
+}
+
+// AddMeetingType adds a meeting type, the TaskDetails are referenced by their ID
+func AddMeetingType(db *gorm.DB, meetingType *dbModel.MeetingType) error {
+	meetingType.ID = 0
+	if err := loadTaskDetailsOfType(db, meetingType); err != nil {
+		return err
+	}
+	// the TaskDetails exist, only the references are saved
+	if err := db.Omit("TaskDetails.*").Create(meetingType).Error; err != nil {
+		zap.L().Error(generalmodel.DBSaveDataFailed, zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// UpdateMeetingType changes the description and replaces the TaskDetails of a meeting type
+func UpdateMeetingType(db *gorm.DB, meetingType *dbModel.MeetingType) error {
+	var existing dbModel.MeetingType
+	if err := db.First(&existing, meetingType.ID).Error; err != nil {
+		return err
+	}
+	meetingType.Model = existing.Model
+	if err := loadTaskDetailsOfType(db, meetingType); err != nil {
+		return err
+	}
+
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&existing).Association("TaskDetails").Clear(); err != nil {
+			return err
+		}
+		return tx.Omit("TaskDetails.*").Save(meetingType).Error
+	}); err != nil {
+		zap.L().Error(generalmodel.DBUpdateDataFailed, zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// DeleteMeetingType deletes a meeting type, which is not used by any meeting or meeting series
+func DeleteMeetingType(db *gorm.DB, id uint) error {
+	var meetingType dbModel.MeetingType
+	if err := db.First(&meetingType, id).Error; err != nil {
+		return err
+	}
+	for _, model := range []interface{}{&dbModel.Meeting{}, &dbModel.MeetingSeries{}} {
+		var used int64
+		if err := db.Model(model).Where("meeting_type_id = ?", id).Count(&used).Error; err != nil {
+			return err
+		}
+		if used != 0 {
+			return errors.ErrMeetingTypeInUse
+		}
+	}
+
+	if err := db.Model(&meetingType).Association("TaskDetails").Clear(); err != nil {
+		return err
+	}
+	if err := db.Unscoped().Delete(&meetingType).Error; err != nil {
+		zap.L().Error(generalmodel.DBDeleteDataFailed, zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// loadTaskDetailsOfType replaces the TaskDetails of meetingType by the stored ones with the same ID.
+// Returns ErrInvalidMeetingType, if the description is missing or a TaskDetail does not exist,
+// ErrMeetingTypeExists, if another meeting type has the same description
+func loadTaskDetailsOfType(db *gorm.DB, meetingType *dbModel.MeetingType) error {
+	if meetingType.Descr == "" {
+		return errors.ErrInvalidMeetingType
+	}
+	var sameDescr int64
+	if err :=
+		db.Model(&dbModel.MeetingType{}).
+			Where("descr = ?", meetingType.Descr).
+			Where("id <> ?", meetingType.ID).
+			Count(&sameDescr).Error; err != nil {
+		return err
+	}
+	if sameDescr != 0 {
+		return errors.ErrMeetingTypeExists
+	}
+	ids := make([]uint, 0, len(meetingType.TaskDetails))
+	for _, taskDetail := range meetingType.TaskDetails {
+		ids = append(ids, taskDetail.ID)
+	}
+	meetingType.TaskDetails = nil
+	if len(ids) == 0 {
+		return nil
+	}
+	if err := db.Where("id IN (?)", ids).Find(&meetingType.TaskDetails).Error; err != nil {
+		return err
+	}
+	if len(meetingType.TaskDetails) != len(ids) {
+		return errors.ErrInvalidMeetingType
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package meeting
+
+import (
+	"mpt_data/database"
+	"mpt_data/helper/errors"
+	dbModel "mpt_data/models/dbmodel"
+	"testing"
+	"time"
+)
+
+func TestMeetingType(t *testing.T) {
+	// Prepare
+	db := database.DB.Begin()
+	t.Cleanup(func() { db.Rollback() })
+	task := dbModel.Task{Descr: "MeetingTypeTask"}
+	if err := db.Create(&task).Error; err != nil {
+		t.Fatalf("test preparation failed: %v", err)
+	}
+	details := []dbModel.TaskDetail{
+		{Descr: "MeetingTypeFirst", TaskID: task.ID},
+		{Descr: "MeetingTypeSecond", TaskID: task.ID},
+	}
+	if err := db.Create(&details).Error; err != nil {
+		t.Fatalf("test preparation failed: %v", err)
+	}
+
+	meetingType := dbModel.MeetingType{Descr: "Sunday service", TaskDetails: []dbModel.TaskDetail{{ID: details[0].ID}}}
+	if err := AddMeetingType(db, &meetingType); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	t.Run("add invalid", func(t *testing.T) {
+		for _, invalid := range []struct {
+			meetingType dbModel.MeetingType
+			err         error
+		}{
+			{dbModel.MeetingType{}, errors.ErrInvalidMeetingType},
+			{dbModel.MeetingType{Descr: "Unknown", TaskDetails: []dbModel.TaskDetail{{ID: details[1].ID + 1}}}, errors.ErrInvalidMeetingType},
+			{dbModel.MeetingType{Descr: "Sunday service"}, errors.ErrMeetingTypeExists},
+		} {
+			// Act
+			err := AddMeetingType(db, &invalid.meetingType)
+			// Assert
+			if err != invalid.err {
+				t.Errorf("expected %v, got %v", invalid.err, err)
+			}
+		}
+	})
+
+	t.Run("update task details", func(t *testing.T) {
+		// Act
+		update := dbModel.MeetingType{ID: meetingType.ID, Descr: "Sunday service", TaskDetails: []dbModel.TaskDetail{{ID: details[1].ID}}}
+		err := UpdateMeetingType(db, &update)
+		// Assert
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		types, err := GetMeetingTypes(db)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		for _, stored := range types {
+			if stored.ID == meetingType.ID && (len(stored.TaskDetails) != 1 || stored.TaskDetails[0].ID != details[1].ID) {
+				t.Errorf("expected task detail %d, got %v", details[1].ID, stored.TaskDetails)
+			}
+		}
+	})
+
+	t.Run("meeting with unknown type", func(t *testing.T) {
+		unknown := meetingType.ID + 1
+		// Act
+		err := db.Create(&dbModel.Meeting{Date: time.Date(2008, 2, 3, 0, 0, 0, 0, time.UTC), MeetingTypeID: &unknown}).Error
+		// Assert
+		if err != errors.ErrInvalidMeetingType {
+			t.Errorf("expected %v, got %v", errors.ErrInvalidMeetingType, err)
+		}
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		db.SavePoint("beforeDelete")
+		defer db.RollbackTo("beforeDelete")
+		if err := db.Create(&dbModel.Meeting{Date: time.Date(2008, 2, 3, 0, 0, 0, 0, time.UTC), MeetingTypeID: &meetingType.ID}).Error; err != nil {
+			t.Fatalf("test preparation failed: %v", err)
+		}
+		// Act
+		err := DeleteMeetingType(db, meetingType.ID)
+		// Assert
+		if err != errors.ErrMeetingTypeInUse {
+			t.Errorf("expected %v, got %v", errors.ErrMeetingTypeInUse, err)
+		}
+		db.Unscoped().Where("meeting_type_id = ?", meetingType.ID).Delete(&dbModel.Meeting{})
+		if err := DeleteMeetingType(db, meetingType.ID); err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+	})
+}
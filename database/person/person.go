@@ -2,9 +2,14 @@
 package person
 
 import (
+	"mpt_data/helper"
+	"mpt_data/helper/calendar"
 	"mpt_data/helper/errors"
+	apiModel "mpt_data/models/apimodel"
 	dbModel "mpt_data/models/dbmodel"
 	generalmodel "mpt_data/models/general"
+	"strings"
+	"time"
 
 	"go.uber.org/zap"
 	"gorm.io/gorm"
@@ -22,12 +27,20 @@ func AddPerson(db *gorm.DB, person *dbModel.Person) error {
 	return nil
 }
 
-// UpdatePerson changes the given-/lastName
+// UpdatePerson changes the given-/lastName, the stored Active is kept if not set
 func UpdatePerson(db *gorm.DB, person *dbModel.Person) (err error) {
 	if person.ID == 0 {
 		return errors.ErrIDNotSet
 	}
 
+	if person.Active == nil {
+		stored, err := GetPersonWithID(db, person.ID)
+		if err != nil {
+			return err
+		}
+		person.Active = stored.Active
+	}
+
 	err = db.Save(person).Error
 	return
 }
@@ -40,12 +53,74 @@ func GetPerson(db *gorm.DB) (people []dbModel.Person, err error) {
 	return
 }
 
-// DeletePerson delete a person, id must be set
+// GetPersonWithFilter returns the people matching filter with their display names, the status refers to the day of date
+func GetPersonWithFilter(db *gorm.DB, filter apiModel.PersonFilter, date time.Time) (people []dbModel.Person, err error) {
+	day := calendar.DayOf(date)
+	inactive := db.Where("active = ?", false).
+		Or("(active_from IS NOT NULL AND active_from > ?)", day).
+		Or("(active_until IS NOT NULL AND active_until < ?)", day)
+	query := db
+	switch filter.Status {
+	case "":
+	case dbModel.PersonStatusActive:
+		query = query.Not(inactive)
+	case dbModel.PersonStatusInactive:
+		query = query.Where(inactive)
+	default:
+		return nil, errors.ErrInvalidPersonStatus
+	}
+
+	words := strings.Fields(filter.Query)
+	switch {
+	case len(words) == 0:
+	case filter.Exact:
+		hash := helper.BlindIndex(strings.Join(words, " "))
+		query = query.Where("(given_name_index = ? OR last_name_index = ? OR name_index = ?)", hash, hash, hash)
+	default:
+		for _, word := range words {
+			query = query.Where("id IN (?)",
+				db.Model(&dbModel.PersonSearchIndex{}).
+					Select("person_id").
+					Where("hash = ?", helper.BlindIndex(word)))
+		}
+	}
+
+	if err := query.Find(&people).Error; err != nil {
+		zap.L().Error(generalmodel.DBLoadDataFailed, zap.Error(err))
+		return nil, err
+	}
+	displayNames := make([]*dbModel.Person, 0, len(people))
+	for i := range people {
+		displayNames = append(displayNames, &people[i])
+	}
+	dbModel.SetDisplayNames(displayNames...)
+	return people, nil
+}
+
+// GetPersonWithID loads the person with the specified id
+func GetPersonWithID(db *gorm.DB, id uint) (person dbModel.Person, err error) {
+	if err := db.First(&person, id).Error; err != nil {
+		return dbModel.Person{}, err
+	}
+	return person, nil
+}
+
+// DeletePerson delete a person, id must be set.
+// Returns ErrPersonInPlan, if the person is assigned in a plan, such people are set inactive instead to keep the history
 func DeletePerson(db *gorm.DB, person dbModel.Person) (err error) {
 	if person.ID == 0 {
 		return errors.ErrIDNotSet
 	}
 
+	var count int64
+	if err := db.Model(&dbModel.Plan{}).Where("person_id = ?", person.ID).Count(&count).Error; err != nil {
+		zap.L().Error(generalmodel.DBLoadDataFailed, zap.Error(err))
+		return err
+	}
+	if count != 0 {
+		return errors.ErrPersonInPlan
+	}
+
 	err = db.Unscoped().Delete(&person).Error
 
 	return
@@ -1,12 +1,16 @@
 package person
 
 import (
+	"fmt"
 	"mpt_data/database"
+	"mpt_data/helper/config"
 	"mpt_data/helper/errors"
+	apiModel "mpt_data/models/apimodel"
 	"mpt_data/models/dbmodel"
 	dbModel "mpt_data/models/dbmodel"
 	"mpt_data/test/vars"
 	"testing"
+	"time"
 )
 
 func TestMain(m *testing.M) {
@@ -24,6 +28,8 @@ func TestAddPerson(t *testing.T) {
 		{"no first name", &dbModel.Person{GivenName: "", LastName: "Maier"}, errors.ErrPersonMissingName},
 		{"no last name", &dbModel.Person{GivenName: "Max", LastName: ""}, errors.ErrPersonMissingName},
 		{"no name", &dbModel.Person{GivenName: "", LastName: ""}, errors.ErrPersonMissingName},
+		{"birth year", &dbModel.Person{GivenName: "Max", LastName: "Maier", BirthYear: 1990}, nil},
+		{"invalid birth year", &dbModel.Person{GivenName: "Max", LastName: "Maier", BirthYear: 90}, errors.ErrPersonInvalidBirthYear},
 	}
 	for _, testcase := range testcases {
 		t.Run(testcase.name, func(t *testing.T) {
@@ -154,3 +160,268 @@ func TestDeletePerson(t *testing.T) {
 		})
 	}
 }
+
+func TestPersonContact(t *testing.T) {
+	var testcases = []struct {
+		name   string
+		person dbModel.Person
+		err    error
+	}{
+		{"complete", dbModel.Person{GivenName: "Max", LastName: "Maier", Email: "max@example.org", Phone: "+49 (0)30 1234-56", Notes: "key holder"}, nil},
+		{"no contact", dbModel.Person{GivenName: "Max", LastName: "Maier"}, nil},
+		{"invalid email", dbModel.Person{GivenName: "Max", LastName: "Maier", Email: "max.example.org"}, errors.ErrPersonInvalidEmail},
+		{"email with name", dbModel.Person{GivenName: "Max", LastName: "Maier", Email: "Max <max@example.org>"}, errors.ErrPersonInvalidEmail},
+		{"invalid phone", dbModel.Person{GivenName: "Max", LastName: "Maier", Phone: "call me"}, errors.ErrPersonInvalidPhone},
+	}
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			// Prepare
+			tx := database.DB.Begin()
+			defer tx.Rollback()
+			person := testcase.person
+			// Act
+			err := AddPerson(tx, &person)
+			// Assert
+			if err != testcase.err {
+				t.Fatalf("expected %v, got %v", testcase.err, err)
+			}
+			if err != nil {
+				return
+			}
+			var stored struct{ Email, Phone, Notes string }
+			if err := tx.Table("people").Where("id = ?", person.ID).Scan(&stored).Error; err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if testcase.person.Email != "" && (stored.Email == testcase.person.Email || stored.Phone == testcase.person.Phone || stored.Notes == testcase.person.Notes) {
+				t.Errorf("expected contact data to be encrypted, got %v", stored)
+			}
+			loaded, err := GetPersonWithID(tx, person.ID)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if loaded.Email != testcase.person.Email || loaded.Phone != testcase.person.Phone || loaded.Notes != testcase.person.Notes {
+				t.Errorf("expected %v, got %v", testcase.person, loaded)
+			}
+		})
+	}
+}
+
+func TestGetPersonWithFilter(t *testing.T) {
+	// Prepare
+	tx := database.DB.Begin()
+	defer tx.Rollback()
+	today := time.Date(2006, 5, 10, 0, 0, 0, 0, time.UTC)
+	inactive, tomorrow, yesterday := false, today.AddDate(0, 0, 1), today.AddDate(0, 0, -1)
+	people := []dbModel.Person{
+		{GivenName: "Anna", LastName: "Active", ActiveUntil: &today},
+		{GivenName: "Ben", LastName: "Retired", Active: &inactive},
+		{GivenName: "Clara", LastName: "Future", ActiveFrom: &tomorrow},
+		{GivenName: "David", LastName: "Left", ActiveUntil: &yesterday},
+	}
+	for i := range people {
+		if err := AddPerson(tx, &people[i]); err != nil {
+			t.Fatalf("test preparation failed: %v", err)
+		}
+	}
+
+	var testcases = []struct {
+		status   string
+		expected []uint
+		err      error
+	}{
+		{"", []uint{people[0].ID, people[1].ID, people[2].ID, people[3].ID}, nil},
+		{dbModel.PersonStatusActive, []uint{people[0].ID}, nil},
+		{dbModel.PersonStatusInactive, []uint{people[1].ID, people[2].ID, people[3].ID}, nil},
+		{"retired", nil, errors.ErrInvalidPersonStatus},
+	}
+	for _, testcase := range testcases {
+		t.Run(testcase.status, func(t *testing.T) {
+			// Act
+			found, err := GetPersonWithFilter(tx, apiModel.PersonFilter{Status: testcase.status}, today)
+			// Assert
+			if err != testcase.err {
+				t.Fatalf("expected %v, got %v", testcase.err, err)
+			}
+			var ids []uint
+			for _, person := range found {
+				for _, id := range []uint{people[0].ID, people[1].ID, people[2].ID, people[3].ID} {
+					if person.ID == id {
+						ids = append(ids, id)
+					}
+				}
+			}
+			if fmt.Sprint(ids) != fmt.Sprint(testcase.expected) {
+				t.Errorf("expected %v, got %v", testcase.expected, ids)
+			}
+		})
+	}
+
+	t.Run("invalid active days", func(t *testing.T) {
+		// Act
+		err := AddPerson(tx, &dbModel.Person{GivenName: "Eva", LastName: "Invalid", ActiveFrom: &today, ActiveUntil: &yesterday})
+		// Assert
+		if err != errors.ErrPersonInvalidActivity {
+			t.Errorf("expected %v, got %v", errors.ErrPersonInvalidActivity, err)
+		}
+	})
+}
+
+func TestSearchPerson(t *testing.T) {
+	// Prepare
+	tx := database.DB.Begin()
+	defer tx.Rollback()
+	people := []dbModel.Person{
+		{GivenName: "Maximilian", LastName: "Suchmann"},
+		{GivenName: "Marie", LastName: "von Suchberg"},
+		{GivenName: "Moritz", LastName: "Suchmann"},
+	}
+	for i := range people {
+		if err := AddPerson(tx, &people[i]); err != nil {
+			t.Fatalf("test preparation failed: %v", err)
+		}
+	}
+	// renamed people are found by their new name only
+	people[2].GivenName = "Benedikt"
+	if err := UpdatePerson(tx, &people[2]); err != nil {
+		t.Fatalf("test preparation failed: %v", err)
+	}
+	maximilian, marie, benedikt := people[0].ID, people[1].ID, people[2].ID
+
+	var testcases = []struct {
+		name     string
+		filter   apiModel.PersonFilter
+		expected []uint
+	}{
+		{"prefix", apiModel.PersonFilter{Query: "such"}, []uint{maximilian, marie, benedikt}},
+		{"prefix case insensitive", apiModel.PersonFilter{Query: "MA"}, []uint{maximilian, marie}},
+		{"prefix of every word", apiModel.PersonFilter{Query: "ma suchm"}, []uint{maximilian}},
+		{"prefix of second word of name", apiModel.PersonFilter{Query: "suchb"}, []uint{marie}},
+		{"old name", apiModel.PersonFilter{Query: "moritz"}, nil},
+		{"new name", apiModel.PersonFilter{Query: "bene"}, []uint{benedikt}},
+		{"exact last name", apiModel.PersonFilter{Query: "suchmann", Exact: true}, []uint{maximilian, benedikt}},
+		{"exact full name", apiModel.PersonFilter{Query: " marie  von suchberg ", Exact: true}, []uint{marie}},
+		{"exact prefix", apiModel.PersonFilter{Query: "such", Exact: true}, nil},
+	}
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			// Act
+			found, err := GetPersonWithFilter(tx, testcase.filter, time.Now())
+			// Assert
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			var ids []uint
+			for _, person := range found {
+				if person.ID == maximilian || person.ID == marie || person.ID == benedikt {
+					ids = append(ids, person.ID)
+				}
+			}
+			if fmt.Sprint(ids) != fmt.Sprint(testcase.expected) {
+				t.Errorf("expected %v, got %v", testcase.expected, ids)
+			}
+		})
+	}
+
+	t.Run("delete", func(t *testing.T) {
+		// Act
+		err := DeletePerson(tx, people[0])
+		// Assert
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		var count int64
+		tx.Model(&dbModel.PersonSearchIndex{}).Where("person_id = ?", maximilian).Count(&count)
+		if count != 0 {
+			t.Errorf("expected search index to be deleted, got %d entries", count)
+		}
+	})
+}
+
+func TestSetDisplayNames(t *testing.T) {
+	t.Cleanup(func() {
+		config.Config.Names.Duplicate = ""
+	})
+	people := func() []dbModel.Person {
+		return []dbModel.Person{
+			{ID: 1, GivenName: "Anna", LastName: "Müller", Disambiguator: "jun."},
+			{ID: 2, GivenName: "Anna", LastName: "müller", BirthYear: 1961},
+			{ID: 3, GivenName: "Anna", LastName: "Müller"},
+			{ID: 4, GivenName: "Eva", LastName: "Huber"},
+		}
+	}
+
+	var testcases = []struct {
+		name     string
+		format   string
+		people   []dbModel.Person
+		expected []string
+	}{
+		{"default format", "", people(), []string{"Anna Müller (jun.)", "Anna müller (1961)", "Anna Müller (#3)", "Eva Huber"}},
+		{"configured format", "{last}, {given} [{id}]", people(), []string{"Müller, Anna [1]", "müller, Anna [2]", "Müller, Anna [3]", "Eva Huber"}},
+		{"same person twice", "", []dbModel.Person{people()[0], people()[0], people()[3]}, []string{"Anna Müller", "Anna Müller", "Eva Huber"}},
+	}
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			// Prepare
+			config.Config.Names.Duplicate = testcase.format
+			displayNames := make([]*dbModel.Person, 0, len(testcase.people))
+			for i := range testcase.people {
+				displayNames = append(displayNames, &testcase.people[i])
+			}
+			// Act
+			dbModel.SetDisplayNames(displayNames...)
+			// Assert
+			for i, person := range testcase.people {
+				if person.DisplayName != testcase.expected[i] {
+					t.Errorf("expected %q, got %q", testcase.expected[i], person.DisplayName)
+				}
+			}
+		})
+	}
+}
+
+func TestKeepHistoryOfPerson(t *testing.T) {
+	// Prepare
+	tx := database.DB.Begin()
+	defer tx.Rollback()
+	inactive := false
+	person := dbModel.Person{GivenName: "Max", LastName: "History", Active: &inactive}
+	if err := AddPerson(tx, &person); err != nil {
+		t.Fatalf("test preparation failed: %v", err)
+	}
+
+	t.Run("update without active", func(t *testing.T) {
+		// Act
+		err := UpdatePerson(tx, &dbModel.Person{ID: person.ID, GivenName: "Moritz", LastName: "History"})
+		// Assert
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		stored, err := GetPersonWithID(tx, person.ID)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if stored.Active == nil || *stored.Active {
+			t.Errorf("expected person to stay inactive")
+		}
+	})
+
+	t.Run("delete assigned person", func(t *testing.T) {
+		meeting := dbModel.Meeting{Date: time.Date(2006, 6, 4, 10, 0, 0, 0, time.UTC)}
+		if err := tx.Create(&meeting).Error; err != nil {
+			t.Fatalf("test preparation failed: %v", err)
+		}
+		if err := tx.Create(&dbModel.Plan{PersonID: person.ID, MeetingID: meeting.ID, TaskDetailID: 1}).Error; err != nil {
+			t.Fatalf("test preparation failed: %v", err)
+		}
+		// Act
+		err := DeletePerson(tx, person)
+		// Assert
+		if err != errors.ErrPersonInPlan {
+			t.Errorf("expected %v, got %v", errors.ErrPersonInPlan, err)
+		}
+		if _, err := GetPersonWithID(tx, person.ID); err != nil {
+			t.Errorf("expected person to be kept, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,96 @@
+package person
+
+import (
+	"mpt_data/helper/errors"
+	dbModel "mpt_data/models/dbmodel"
+	generalmodel "mpt_data/models/general"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// GetPreferenceOfPerson loads all preferences of a person
+func GetPreferenceOfPerson(db *gorm.DB, personID uint) (preferences []dbModel.PersonPreference, err error) {
+	if personID == 0 {
+		return nil, errors.ErrIDNotSet
+	}
+	if err := db.Where("person_id = ?", personID).Find(&preferences).Error; err != nil {
+		return nil, err
+	}
+	return preferences, nil
+}
+
+// AddPreferenceToPerson adds a preference for a TaskDetail or a weekday to a person.
+// A person has at most one preference per TaskDetail and per weekday
+func AddPreferenceToPerson(db *gorm.DB, preference dbModel.PersonPreference) (dbModel.PersonPreference, error) {
+	if preference.PersonID == 0 {
+		return dbModel.PersonPreference{}, errors.ErrIDNotSet
+	}
+	if err := db.First(&dbModel.Person{}, preference.PersonID).Error; err != nil {
+		return dbModel.PersonPreference{}, err
+	}
+	if preference.TaskDetailID != nil {
+		if err := db.First(&dbModel.TaskDetail{}, *preference.TaskDetailID).Error; err != nil {
+			return dbModel.PersonPreference{}, errors.ErrInvalidPreference
+		}
+	}
+
+	existing := db.Model(&dbModel.PersonPreference{}).Where("person_id = ?", preference.PersonID)
+	switch {
+	case preference.TaskDetailID != nil:
+		existing = existing.Where("task_detail_id = ?", *preference.TaskDetailID)
+	case preference.Weekday != nil:
+		existing = existing.Where("weekday = ?", *preference.Weekday)
+	default:
+		return dbModel.PersonPreference{}, errors.ErrInvalidPreference
+	}
+	var count int64
+	if err := existing.Count(&count).Error; err != nil {
+		return dbModel.PersonPreference{}, err
+	}
+	if count != 0 {
+		return dbModel.PersonPreference{}, errors.ErrPreferenceExists
+	}
+
+	if err := db.Create(&preference).Error; err != nil {
+		zap.L().Error(generalmodel.DBSaveDataFailed, zap.Error(err))
+		return dbModel.PersonPreference{}, err
+	}
+	return preference, nil
+}
+
+// UpdatePreferenceOfPerson changes the weight of a preference of a person
+func UpdatePreferenceOfPerson(db *gorm.DB, personID uint, preferenceID uint, weight string) (preference dbModel.PersonPreference, err error) {
+	if personID == 0 || preferenceID == 0 {
+		return preference, errors.ErrIDNotSet
+	}
+	if err := db.Where("person_id = ?", personID).First(&preference, preferenceID).Error; err != nil {
+		return preference, err
+	}
+
+	preference.Weight = weight
+	if err := db.Save(&preference).Error; err != nil {
+		zap.L().Error(generalmodel.DBUpdateDataFailed, zap.Error(err))
+		return preference, err
+	}
+	return preference, nil
+}
+
+// DeletePreferenceFromPerson deletes a preference of a person
+func DeletePreferenceFromPerson(db *gorm.DB, personID uint, preferenceID uint) error {
+	if personID == 0 || preferenceID == 0 {
+		return errors.ErrIDNotSet
+	}
+	result :=
+		db.Unscoped().
+			Where("person_id = ?", personID).
+			Delete(&dbModel.PersonPreference{}, preferenceID)
+	if result.Error != nil {
+		zap.L().Error(generalmodel.DBDeleteDataFailed, zap.Error(result.Error))
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package person
+
+import (
+	"mpt_data/database"
+	"mpt_data/helper/errors"
+	dbModel "mpt_data/models/dbmodel"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func TestAddPreferenceToPerson(t *testing.T) {
+	sunday, monday, invalidDay := 0, 1, 7
+	const (
+		task = iota
+		taskWithPreference
+		weekday
+		both
+		none
+		unknownTask
+		noPerson
+	)
+	var testcases = []struct {
+		name    string
+		weight  string
+		target  int
+		weekday *int
+		err     error
+	}{
+		{"task", dbModel.PreferencePrefers, task, nil, nil},
+		{"weekday", dbModel.PreferenceDislikes, weekday, &monday, nil},
+		{"invalid weight", "bla", task, nil, errors.ErrInvalidPreference},
+		{"invalid weekday", dbModel.PreferenceDislikes, weekday, &invalidDay, errors.ErrInvalidPreference},
+		{"task and weekday", dbModel.PreferencePrefers, both, &monday, errors.ErrInvalidPreference},
+		{"neither task nor weekday", dbModel.PreferencePrefers, none, nil, errors.ErrInvalidPreference},
+		{"unknown task", dbModel.PreferencePrefers, unknownTask, nil, errors.ErrInvalidPreference},
+		{"error person not set", dbModel.PreferencePrefers, noPerson, nil, errors.ErrIDNotSet},
+		{"duplicate task", dbModel.PreferenceNeutral, taskWithPreference, nil, errors.ErrPreferenceExists},
+		{"duplicate weekday", dbModel.PreferencePrefers, weekday, &sunday, errors.ErrPreferenceExists},
+	}
+
+	// Prepare
+	tx := database.DB.Begin()
+	t.Cleanup(func() { tx.Rollback() })
+	person := dbModel.Person{GivenName: "Max", LastName: "Preference"}
+	taskModel := dbModel.Task{Descr: "PreferenceTask"}
+	tx.Create(&person)
+	tx.Create(&taskModel)
+	detail := dbModel.TaskDetail{Descr: "PreferenceDetail", TaskID: taskModel.ID}
+	disliked := dbModel.TaskDetail{Descr: "PreferenceDisliked", TaskID: taskModel.ID}
+	tx.Create(&detail)
+	tx.Create(&disliked)
+	unknownID := disliked.ID + 1
+	for _, existing := range []dbModel.PersonPreference{
+		{PersonID: person.ID, TaskDetailID: &disliked.ID, Weight: dbModel.PreferenceDislikes},
+		{PersonID: person.ID, Weekday: &sunday, Weight: dbModel.PreferenceDislikes},
+	} {
+		if err := tx.Create(&existing).Error; err != nil {
+			t.Fatalf("test preparation failed: %v", err)
+		}
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			tx.SavePoint("beforePreference")
+			defer tx.RollbackTo("beforePreference")
+			preference := dbModel.PersonPreference{PersonID: person.ID, Weekday: testcase.weekday, Weight: testcase.weight}
+			switch testcase.target {
+			case task, both:
+				preference.TaskDetailID = &detail.ID
+			case taskWithPreference:
+				preference.TaskDetailID = &disliked.ID
+			case unknownTask:
+				preference.TaskDetailID = &unknownID
+			case noPerson:
+				preference.PersonID = 0
+				preference.TaskDetailID = &detail.ID
+			}
+			// Act
+			data, err := AddPreferenceToPerson(tx, preference)
+			// Assert
+			if err != testcase.err {
+				t.Errorf("expected %v, got %v", testcase.err, err)
+				return
+			}
+			if err == nil && data.ID == 0 {
+				t.Errorf("expected data to be set")
+			}
+		})
+	}
+}
+
+func TestPreferenceOfPerson(t *testing.T) {
+	// Prepare
+	tx := database.DB.Begin()
+	defer tx.Rollback()
+	first := dbModel.Person{GivenName: "Max", LastName: "Preference"}
+	second := dbModel.Person{GivenName: "Eva", LastName: "Preference"}
+	tx.Create(&first)
+	tx.Create(&second)
+	saturday := 6
+	preference, err := AddPreferenceToPerson(tx, dbModel.PersonPreference{PersonID: first.ID, Weekday: &saturday, Weight: dbModel.PreferencePrefers})
+	if err != nil {
+		t.Skipf("test preparation failed: %v", err)
+	}
+
+	t.Run("get", func(t *testing.T) {
+		// Act
+		preferences, err := GetPreferenceOfPerson(tx, first.ID)
+		// Assert
+		if err != nil || len(preferences) != 1 || preferences[0].ID != preference.ID {
+			t.Errorf("expected preference %d, got %v, %v", preference.ID, preferences, err)
+		}
+	})
+
+	t.Run("update weight", func(t *testing.T) {
+		// Act
+		updated, err := UpdatePreferenceOfPerson(tx, first.ID, preference.ID, dbModel.PreferenceDislikes)
+		// Assert
+		if err != nil || updated.Weight != dbModel.PreferenceDislikes {
+			t.Errorf("expected weight %s, got %v, %v", dbModel.PreferenceDislikes, updated, err)
+		}
+	})
+
+	t.Run("update invalid weight", func(t *testing.T) {
+		// Act
+		_, err := UpdatePreferenceOfPerson(tx, first.ID, preference.ID, "bla")
+		// Assert
+		if err != errors.ErrInvalidPreference {
+			t.Errorf("expected %v, got %v", errors.ErrInvalidPreference, err)
+		}
+	})
+
+	t.Run("delete of other person", func(t *testing.T) {
+		// Act
+		err := DeletePreferenceFromPerson(tx, second.ID, preference.ID)
+		// Assert
+		if err != gorm.ErrRecordNotFound {
+			t.Errorf("expected %v, got %v", gorm.ErrRecordNotFound, err)
+		}
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		// Act
+		err := DeletePreferenceFromPerson(tx, first.ID, preference.ID)
+		// Assert
+		if err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,75 @@
+package person
+
+import (
+	"mpt_data/helper/errors"
+	dbModel "mpt_data/models/dbmodel"
+	generalmodel "mpt_data/models/general"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// GetRelationOfPerson loads all relations, where the person is on either side
+func GetRelationOfPerson(db *gorm.DB, personID uint) (relations []dbModel.PersonRelation, err error) {
+	if personID == 0 {
+		return nil, errors.ErrIDNotSet
+	}
+	if err :=
+		db.Where("person_id = ?", personID).
+			Or("related_person_id = ?", personID).
+			Find(&relations).Error; err != nil {
+		return nil, err
+	}
+	return relations, nil
+}
+
+// AddRelationToPerson adds a relation from a person to another person
+func AddRelationToPerson(db *gorm.DB, relation dbModel.PersonRelation) (dbModel.PersonRelation, error) {
+	if relation.PersonID == 0 {
+		return dbModel.PersonRelation{}, errors.ErrIDNotSet
+	}
+	if err := db.First(&dbModel.Person{}, relation.RelatedPersonID).Error; err != nil {
+		return dbModel.PersonRelation{}, errors.ErrInvalidRelation
+	}
+	if err := db.Create(&relation).Error; err != nil {
+		zap.L().Error(generalmodel.DBSaveDataFailed, zap.Error(err))
+		return dbModel.PersonRelation{}, err
+	}
+	return relation, nil
+}
+
+// UpdateRelationOfPerson changes the type of a relation of a person
+func UpdateRelationOfPerson(db *gorm.DB, personID uint, relationID uint, relationType string) (relation dbModel.PersonRelation, err error) {
+	if personID == 0 || relationID == 0 {
+		return relation, errors.ErrIDNotSet
+	}
+	if err := db.Where("person_id = ?", personID).First(&relation, relationID).Error; err != nil {
+		return relation, err
+	}
+
+	relation.Type = relationType
+	if err := db.Save(&relation).Error; err != nil {
+		zap.L().Error(generalmodel.DBUpdateDataFailed, zap.Error(err))
+		return relation, err
+	}
+	return relation, nil
+}
+
+// DeleteRelationFromPerson deletes a relation of a person
+func DeleteRelationFromPerson(db *gorm.DB, personID uint, relationID uint) error {
+	if personID == 0 || relationID == 0 {
+		return errors.ErrIDNotSet
+	}
+	result :=
+		db.Unscoped().
+			Where("person_id = ?", personID).
+			Delete(&dbModel.PersonRelation{}, relationID)
+	if result.Error != nil {
+		zap.L().Error(generalmodel.DBDeleteDataFailed, zap.Error(result.Error))
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package person
+
+import (
+	"mpt_data/database"
+	"mpt_data/helper/errors"
+	dbModel "mpt_data/models/dbmodel"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func TestAddRelationToPerson(t *testing.T) {
+	const (
+		related = iota
+		self
+		unknown
+		noPerson
+	)
+	var testcases = []struct {
+		name         string
+		relationType string
+		target       int
+		err          error
+	}{
+		{"succesfull", dbModel.RelationApart, related, nil},
+		{"invalid type", "bla", related, errors.ErrInvalidRelation},
+		{"error person not set", dbModel.RelationApart, noPerson, errors.ErrIDNotSet},
+		{"related person unknown", dbModel.RelationApart, unknown, errors.ErrInvalidRelation},
+		{"relation to itself", dbModel.RelationTogether, self, errors.ErrInvalidRelation},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			// Prepare
+			tx := database.DB.Begin()
+			defer tx.Rollback()
+			first := dbModel.Person{GivenName: "Max", LastName: "Maier"}
+			second := dbModel.Person{GivenName: "Eva", LastName: "Maier"}
+			tx.Create(&first)
+			tx.Create(&second)
+			relation := dbModel.PersonRelation{PersonID: first.ID, RelatedPersonID: second.ID, Type: testcase.relationType}
+			switch testcase.target {
+			case self:
+				relation.RelatedPersonID = first.ID
+			case unknown:
+				relation.RelatedPersonID = second.ID + 1
+			case noPerson:
+				relation.PersonID = 0
+			}
+			// Act
+			data, err := AddRelationToPerson(tx, relation)
+			// Assert
+			if err != testcase.err {
+				t.Errorf("expected %v, got %v", testcase.err, err)
+				return
+			}
+			if err == nil && data.ID == 0 {
+				t.Errorf("expected data to be set")
+			}
+		})
+	}
+}
+
+func TestRelationOfPerson(t *testing.T) {
+	// Prepare
+	tx := database.DB.Begin()
+	defer tx.Rollback()
+	first := dbModel.Person{GivenName: "Max", LastName: "Maier"}
+	second := dbModel.Person{GivenName: "Eva", LastName: "Maier"}
+	tx.Create(&first)
+	tx.Create(&second)
+	relation, err := AddRelationToPerson(tx, dbModel.PersonRelation{PersonID: first.ID, RelatedPersonID: second.ID, Type: dbModel.RelationTogether})
+	if err != nil {
+		t.Skipf("test preparation failed: %v", err)
+	}
+
+	t.Run("get from related person", func(t *testing.T) {
+		// Act
+		relations, err := GetRelationOfPerson(tx, second.ID)
+		// Assert
+		if err != nil || len(relations) != 1 || relations[0].ID != relation.ID {
+			t.Errorf("expected relation %d, got %v, %v", relation.ID, relations, err)
+		}
+	})
+
+	t.Run("update type", func(t *testing.T) {
+		// Act
+		updated, err := UpdateRelationOfPerson(tx, first.ID, relation.ID, dbModel.RelationRequires)
+		// Assert
+		if err != nil || updated.Type != dbModel.RelationRequires {
+			t.Errorf("expected type %s, got %v, %v", dbModel.RelationRequires, updated, err)
+		}
+	})
+
+	t.Run("update invalid type", func(t *testing.T) {
+		// Act
+		_, err := UpdateRelationOfPerson(tx, first.ID, relation.ID, "bla")
+		// Assert
+		if err != errors.ErrInvalidRelation {
+			t.Errorf("expected %v, got %v", errors.ErrInvalidRelation, err)
+		}
+	})
+
+	t.Run("delete of other person", func(t *testing.T) {
+		// Act
+		err := DeleteRelationFromPerson(tx, second.ID, relation.ID)
+		// Assert
+		if err != gorm.ErrRecordNotFound {
+			t.Errorf("expected %v, got %v", gorm.ErrRecordNotFound, err)
+		}
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		// Act
+		err := DeleteRelationFromPerson(tx, first.ID, relation.ID)
+		// Assert
+		if err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+	})
+}
@@ -4,10 +4,12 @@ package person
 import (
 	"mpt_data/database"
 	"mpt_data/helper/errors"
+	"mpt_data/models/apimodel"
 	dbModel "mpt_data/models/dbmodel"
 	generalmodel "mpt_data/models/general"
 
 	"go.uber.org/zap"
+	"gorm.io/gorm"
 )
 
 // AddTaskToPerson adds tasks to a person
@@ -58,6 +60,36 @@ func DeleteTaskFromPerson(personID uint, tasks []dbModel.TaskDetail) error {
 	return nil
 }
 
+// UpdateTaskLimitOfPerson sets the maximum number of assignments per period for tasks of a person, 0 for unlimited
+func UpdateTaskLimitOfPerson(db *gorm.DB, personID uint, limits []apimodel.PersonTaskLimit) (personTask []dbModel.PersonTask, err error) {
+	if personID == 0 {
+		return nil, errors.ErrIDNotSet
+	}
+
+	for _, limit := range limits {
+		if limit.TaskDetailID == 0 {
+			return nil, errors.ErrIDNotSet
+		}
+		result :=
+			db.Model(&dbModel.PersonTask{}).
+				Where("person_id = ?", personID).
+				Where("task_detail_id = ?", limit.TaskDetailID).
+				Update("max_assignments_period", limit.MaxAssignmentsPeriod)
+		if result.Error != nil {
+			zap.L().Error(generalmodel.DBUpdateDataFailed, zap.Error(result.Error))
+			return nil, result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil, errors.ErrTaskForPersonNotAllowed
+		}
+	}
+
+	if err := db.Where("person_id = ?", personID).Find(&personTask).Error; err != nil {
+		return nil, err
+	}
+	return personTask, nil
+}
+
 // GetTaskOfPerson loads tasks assigned to a person
 func GetTaskOfPerson(personID uint) (task []dbModel.Task, err error) {
 	if personID == 0 {
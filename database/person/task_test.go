@@ -1,7 +1,9 @@
 package person
 
 import (
+	"mpt_data/database"
 	"mpt_data/helper/errors"
+	"mpt_data/models/apimodel"
 	dbModel "mpt_data/models/dbmodel"
 	"testing"
 )
@@ -33,6 +35,39 @@ func TestAddTaskToPerson(t *testing.T) {
 	}
 }
 
+func TestUpdateTaskLimitOfPerson(t *testing.T) {
+	var testcases = []struct {
+		name     string
+		personID uint
+		limits   []apimodel.PersonTaskLimit
+		err      error
+	}{
+		{"succesfull", 1, []apimodel.PersonTaskLimit{{TaskDetailID: 1, MaxAssignmentsPeriod: 2}}, nil},
+		{"task not assigned", 1, []apimodel.PersonTaskLimit{{TaskDetailID: 999, MaxAssignmentsPeriod: 2}}, errors.ErrTaskForPersonNotAllowed},
+		{"error person not set", 0, []apimodel.PersonTaskLimit{{TaskDetailID: 1}}, errors.ErrIDNotSet},
+		{"error task not set", 1, []apimodel.PersonTaskLimit{{TaskDetailID: 0}}, errors.ErrIDNotSet},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			// Prepare
+			tx := database.DB.Begin()
+			defer tx.Rollback()
+			tx.Create(&dbModel.PersonTask{PersonID: 1, TaskDetailID: 1})
+			// Act
+			data, err := UpdateTaskLimitOfPerson(tx, testcase.personID, testcase.limits)
+			// Assert
+			if err != testcase.err {
+				t.Errorf("expected %v, got %v", testcase.err, err)
+				return
+			}
+			if err == nil && (len(data) != 1 || data[0].MaxAssignmentsPeriod != testcase.limits[0].MaxAssignmentsPeriod) {
+				t.Errorf("expected limit %d to be set, got %v", testcase.limits[0].MaxAssignmentsPeriod, data)
+			}
+		})
+	}
+}
+
 func TestDeleteTaskFromPerson(t *testing.T) {
 	var testcases = []struct {
 		name       string
@@ -0,0 +1,88 @@
+package plan
+
+import (
+	"mpt_data/helper/calendar"
+	dbModel "mpt_data/models/dbmodel"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// absences holds the absence periods and the recurring absences per person ID
+type absences struct {
+	periods   map[uint][]dbModel.PersonAbsencePeriod
+	recurring map[uint][]dbModel.PersonRecurringAbsence
+}
+
+// loadAbsences loads the absence periods and the recurring absences of people, of all people if personIDs is nil
+func loadAbsences(db *gorm.DB, personIDs []uint) (absent absences, err error) {
+	var periods []dbModel.PersonAbsencePeriod
+	var recurring []dbModel.PersonRecurringAbsence
+	periodQuery, recurringQuery := db, db
+	if personIDs != nil {
+		periodQuery = db.Where("person_id IN (?)", personIDs)
+		recurringQuery = db.Where("person_id IN (?)", personIDs)
+	}
+	if err := periodQuery.Find(&periods).Error; err != nil {
+		return absences{}, err
+	}
+	if err := recurringQuery.Find(&recurring).Error; err != nil {
+		return absences{}, err
+	}
+
+	absent.periods = make(map[uint][]dbModel.PersonAbsencePeriod)
+	for _, period := range periods {
+		absent.periods[period.PersonID] = append(absent.periods[period.PersonID], period)
+	}
+	absent.recurring = make(map[uint][]dbModel.PersonRecurringAbsence)
+	for _, rule := range recurring {
+		absent.recurring[rule.PersonID] = append(absent.recurring[rule.PersonID], rule)
+	}
+	return absent, nil
+}
+
+// inPeriod reports if one absence period of the person covers the day of date
+func (absent absences) inPeriod(personID uint, date time.Time) bool {
+	for _, period := range absent.periods[personID] {
+		if period.Covers(date) {
+			return true
+		}
+	}
+	return false
+}
+
+// recurs reports if one recurring absence of the person covers the day of date
+func (absent absences) recurs(personID uint, date time.Time) bool {
+	for _, rule := range absent.recurring[personID] {
+		if rule.Covers(date) {
+			return true
+		}
+	}
+	return false
+}
+
+// recurringAbsentAt returns the IDs of all people, whose recurring absence covers the day of date.
+// Recurrence rules are evaluated in go, as they can not be expressed in SQL
+func recurringAbsentAt(db *gorm.DB, date time.Time) ([]uint, error) {
+	var recurring []dbModel.PersonRecurringAbsence
+	if err := db.Find(&recurring).Error; err != nil {
+		return nil, err
+	}
+	ids := make([]uint, 0)
+	for _, rule := range recurring {
+		if rule.Covers(date) {
+			ids = append(ids, rule.PersonID)
+		}
+	}
+	return ids, nil
+}
+
+// peopleInactiveAt returns the query of the IDs of people, which are not active at the day of date
+func peopleInactiveAt(db *gorm.DB, date time.Time) *gorm.DB {
+	day := calendar.DayOf(date)
+	return db.Table("people").
+		Select("id").
+		Where("active = ?", false).
+		Or("(active_from IS NOT NULL AND active_from > ?)", day).
+		Or("(active_until IS NOT NULL AND active_until < ?)", day)
+}
@@ -0,0 +1,272 @@
+package plan
+
+import (
+	"mpt_data/database"
+	"mpt_data/models/apimodel"
+	dbModel "mpt_data/models/dbmodel"
+	generalmodel "mpt_data/models/general"
+	"testing"
+	"time"
+)
+
+func TestAbsencePeriod(t *testing.T) {
+	// Prepare
+	db := database.DB.Begin()
+	t.Cleanup(func() {
+		db.Rollback()
+	})
+	task := dbModel.Task{Descr: "AbsencePeriodTask"}
+	if err := db.Create(&task).Error; err != nil {
+		t.Fatalf("test preparation failed: %v", err)
+	}
+	detail := dbModel.TaskDetail{Descr: "AbsencePeriodDetail", TaskID: task.ID}
+	people := []dbModel.Person{
+		{GivenName: "Anna", LastName: "Vacation"},
+		{GivenName: "Ben", LastName: "Vacation"},
+	}
+	meetings := []dbModel.Meeting{
+		{Date: time.Date(2006, 7, 2, 10, 0, 0, 0, time.UTC)},
+		{Date: time.Date(2006, 7, 9, 10, 0, 0, 0, time.UTC)},
+		{Date: time.Date(2006, 7, 23, 10, 0, 0, 0, time.UTC)},
+	}
+	for _, value := range []interface{}{&detail, &people, &meetings} {
+		if err := db.Create(value).Error; err != nil {
+			t.Fatalf("test preparation failed: %v", err)
+		}
+	}
+	anna, ben := people[0].ID, people[1].ID
+	for _, personID := range []uint{anna, ben} {
+		if err := db.Create(&dbModel.PersonTask{PersonID: personID, TaskDetailID: detail.ID}).Error; err != nil {
+			t.Fatalf("test preparation failed: %v", err)
+		}
+	}
+	// Anna is on vacation from the first up to the last day of the second meeting
+	vacation := dbModel.PersonAbsencePeriod{
+		PersonID: anna,
+		From:     time.Date(2006, 7, 2, 0, 0, 0, 0, time.UTC),
+		To:       time.Date(2006, 7, 9, 0, 0, 0, 0, time.UTC),
+		Note:     "vacation",
+	}
+	if err := db.Create(&vacation).Error; err != nil {
+		t.Fatalf("test preparation failed: %v", err)
+	}
+	period := generalmodel.Period{
+		StartDate: time.Date(2006, 7, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2006, 7, 31, 0, 0, 0, 0, time.UTC),
+	}
+
+	var testcases = []struct {
+		name      string
+		meeting   dbModel.Meeting
+		available []uint
+	}{
+		{"first day", meetings[0], []uint{ben}},
+		{"last day", meetings[1], []uint{ben}},
+		{"after period", meetings[2], []uint{anna, ben}},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			// Act
+			people, err := GetAllPersonAvailable(db, dbModel.Plan{
+				MeetingID:    testcase.meeting.ID,
+				Meeting:      testcase.meeting,
+				TaskDetailID: detail.ID,
+			})
+
+			// Assert
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if len(people.Available) != len(testcase.available) {
+				t.Fatalf("expected %d available people, got %v", len(testcase.available), people.Available)
+			}
+			for i, person := range people.Available {
+				if person.ID != testcase.available[i] {
+					t.Errorf("expected person %d available, got %d", testcase.available[i], person.ID)
+				}
+			}
+		})
+	}
+
+	t.Run("conflict", func(t *testing.T) {
+		db.SavePoint("beforeConflict")
+		defer db.RollbackTo("beforeConflict")
+		if err := db.Create(&dbModel.Plan{PersonID: anna, MeetingID: meetings[1].ID, TaskDetailID: detail.ID}).Error; err != nil {
+			t.Fatalf("test preparation failed: %v", err)
+		}
+
+		// Act
+		conflicts, err := GetConflicts(db, period)
+
+		// Assert
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(conflicts) != 1 || conflicts[0].Reason != apimodel.ConflictAbsent {
+			t.Errorf("expected one %s conflict, got %v", apimodel.ConflictAbsent, conflicts)
+		}
+	})
+}
+
+func TestRecurringAbsence(t *testing.T) {
+	// Prepare
+	db := database.DB.Begin()
+	t.Cleanup(func() {
+		db.Rollback()
+	})
+	task := dbModel.Task{Descr: "RecurringAbsenceTask"}
+	if err := db.Create(&task).Error; err != nil {
+		t.Fatalf("test preparation failed: %v", err)
+	}
+	detail := dbModel.TaskDetail{Descr: "RecurringAbsenceDetail", TaskID: task.ID}
+	people := []dbModel.Person{
+		{GivenName: "Anna", LastName: "Recurring"},
+		{GivenName: "Ben", LastName: "Recurring"},
+	}
+	meetings := []dbModel.Meeting{
+		{Date: time.Date(2007, 7, 1, 10, 0, 0, 0, time.UTC)},
+		{Date: time.Date(2007, 7, 8, 10, 0, 0, 0, time.UTC)},
+		{Date: time.Date(2007, 8, 5, 10, 0, 0, 0, time.UTC)},
+	}
+	for _, value := range []interface{}{&detail, &people, &meetings} {
+		if err := db.Create(value).Error; err != nil {
+			t.Fatalf("test preparation failed: %v", err)
+		}
+	}
+	anna, ben := people[0].ID, people[1].ID
+	for _, personID := range []uint{anna, ben} {
+		if err := db.Create(&dbModel.PersonTask{PersonID: personID, TaskDetailID: detail.ID}).Error; err != nil {
+			t.Fatalf("test preparation failed: %v", err)
+		}
+	}
+	// Anna is absent every first sunday of the month until july
+	until := time.Date(2007, 7, 31, 0, 0, 0, 0, time.UTC)
+	if err := db.Create(&dbModel.PersonRecurringAbsence{PersonID: anna, Rule: "FREQ=MONTHLY;BYDAY=1SU", ValidUntil: &until}).Error; err != nil {
+		t.Fatalf("test preparation failed: %v", err)
+	}
+
+	var testcases = []struct {
+		name      string
+		meeting   dbModel.Meeting
+		available []uint
+	}{
+		{"first sunday", meetings[0], []uint{ben}},
+		{"second sunday", meetings[1], []uint{anna, ben}},
+		{"after validity", meetings[2], []uint{anna, ben}},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			// Act
+			people, err := GetAllPersonAvailable(db, dbModel.Plan{
+				MeetingID:    testcase.meeting.ID,
+				Meeting:      testcase.meeting,
+				TaskDetailID: detail.ID,
+			})
+
+			// Assert
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if len(people.Available) != len(testcase.available) {
+				t.Fatalf("expected %d available people, got %v", len(testcase.available), people.Available)
+			}
+			for i, person := range people.Available {
+				if person.ID != testcase.available[i] {
+					t.Errorf("expected person %d available, got %d", testcase.available[i], person.ID)
+				}
+			}
+		})
+	}
+}
+
+func TestInactivePerson(t *testing.T) {
+	// Prepare
+	db := database.DB.Begin()
+	t.Cleanup(func() {
+		db.Rollback()
+	})
+	task := dbModel.Task{Descr: "InactiveTask"}
+	if err := db.Create(&task).Error; err != nil {
+		t.Fatalf("test preparation failed: %v", err)
+	}
+	detail := dbModel.TaskDetail{Descr: "InactiveDetail", TaskID: task.ID}
+	inactive, from, until := false, time.Date(2006, 9, 10, 0, 0, 0, 0, time.UTC), time.Date(2006, 9, 17, 0, 0, 0, 0, time.UTC)
+	people := []dbModel.Person{
+		{GivenName: "Anna", LastName: "Retired", Active: &inactive},
+		{GivenName: "Ben", LastName: "Leave", ActiveFrom: &from},
+		{GivenName: "Clara", LastName: "Leaving", ActiveUntil: &until},
+	}
+	meetings := []dbModel.Meeting{
+		{Date: time.Date(2006, 9, 3, 10, 0, 0, 0, time.UTC)},
+		{Date: time.Date(2006, 9, 17, 10, 0, 0, 0, time.UTC)},
+		{Date: time.Date(2006, 9, 24, 10, 0, 0, 0, time.UTC)},
+	}
+	for _, value := range []interface{}{&detail, &people, &meetings} {
+		if err := db.Create(value).Error; err != nil {
+			t.Fatalf("test preparation failed: %v", err)
+		}
+	}
+	anna, ben, clara := people[0].ID, people[1].ID, people[2].ID
+	for _, personID := range []uint{anna, ben, clara} {
+		if err := db.Create(&dbModel.PersonTask{PersonID: personID, TaskDetailID: detail.ID}).Error; err != nil {
+			t.Fatalf("test preparation failed: %v", err)
+		}
+	}
+
+	var testcases = []struct {
+		name      string
+		meeting   dbModel.Meeting
+		available []uint
+	}{
+		{"before active from", meetings[0], []uint{clara}},
+		{"last active day", meetings[1], []uint{ben, clara}},
+		{"after active until", meetings[2], []uint{ben}},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			// Act
+			people, err := GetAllPersonAvailable(db, dbModel.Plan{
+				MeetingID:    testcase.meeting.ID,
+				Meeting:      testcase.meeting,
+				TaskDetailID: detail.ID,
+			})
+
+			// Assert
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if len(people.Available) != len(testcase.available) {
+				t.Fatalf("expected %d available people, got %v", len(testcase.available), people.Available)
+			}
+			for i, person := range people.Available {
+				if person.ID != testcase.available[i] {
+					t.Errorf("expected person %d available, got %d", testcase.available[i], person.ID)
+				}
+			}
+		})
+	}
+
+	t.Run("history", func(t *testing.T) {
+		db.SavePoint("beforeHistory")
+		defer db.RollbackTo("beforeHistory")
+		element := dbModel.Plan{PersonID: anna, MeetingID: meetings[0].ID, TaskDetailID: detail.ID}
+		if err := db.Create(&element).Error; err != nil {
+			t.Fatalf("test preparation failed: %v", err)
+		}
+
+		// Act
+		var plan dbModel.Plan
+		err := db.Preload("Person").First(&plan, element.ID).Error
+
+		// Assert
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if plan.Person.LastName != "Retired" {
+			t.Errorf("expected plan of inactive person with name, got %v", plan.Person)
+		}
+	})
+}
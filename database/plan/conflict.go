@@ -0,0 +1,106 @@
+package plan
+
+import (
+	"mpt_data/models/apimodel"
+	dbModel "mpt_data/models/dbmodel"
+	generalmodel "mpt_data/models/general"
+
+	"gorm.io/gorm"
+)
+
+// GetConflicts checks all plan elements with a person in the specified period.
+// Returns every element whose person is absent, not qualified for the task, assigned twice at the meeting, deleted
+// or not active at the date of the meeting
+func GetConflicts(db *gorm.DB, period generalmodel.Period) (conflicts []apimodel.PlanConflict, err error) {
+	var plan []dbModel.Plan
+	if err :=
+		db.Preload("Person").
+			Preload("Meeting").
+			Preload("TaskDetail.Task").
+			Preload("TaskDetail").
+			Joins("JOIN meetings m on m.id = meeting_id").
+			Where("m.date between ? and ?", period.StartDate, period.EndDate).
+			Where("person_id <> 0").
+			Where("task_detail_id <> 0").
+			Order("m.date asc").
+			Order("slot asc").
+			Find(&plan).Error; err != nil {
+		return nil, err
+	}
+	if len(plan) == 0 {
+		return nil, nil
+	}
+
+	var personIDs, meetingIDs []uint
+	for _, element := range plan {
+		personIDs = append(personIDs, element.PersonID)
+		meetingIDs = append(meetingIDs, element.MeetingID)
+	}
+
+	type personMeeting struct {
+		personID, meetingID uint
+	}
+	var absences []dbModel.PersonAbsence
+	if err := db.Where("meeting_id IN (?)", meetingIDs).Where("person_id IN (?)", personIDs).Find(&absences).Error; err != nil {
+		return nil, err
+	}
+	absent := make(map[personMeeting]bool, len(absences))
+	for _, absence := range absences {
+		absent[personMeeting{absence.PersonID, absence.MeetingID}] = true
+	}
+
+	absentRules, err := loadAbsences(db, personIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	var personTasks []dbModel.PersonTask
+	if err := db.Where("person_id IN (?)", personIDs).Find(&personTasks).Error; err != nil {
+		return nil, err
+	}
+	qualified := make(map[personTask]bool, len(personTasks))
+	for _, pt := range personTasks {
+		qualified[personTaskOf(pt.PersonID, pt.TaskDetailID)] = true
+	}
+
+	var existingIDs []uint
+	if err := db.Model(&dbModel.Person{}).Where("id IN (?)", personIDs).Pluck("id", &existingIDs).Error; err != nil {
+		return nil, err
+	}
+	existing := make(map[uint]bool, len(existingIDs))
+	for _, id := range existingIDs {
+		existing[id] = true
+	}
+
+	booked := make(map[personMeeting]int)
+	for _, element := range plan {
+		booked[personMeeting{element.PersonID, element.MeetingID}]++
+	}
+
+	for _, element := range plan {
+		key := personMeeting{element.PersonID, element.MeetingID}
+		var reasons []string
+		if !existing[element.PersonID] {
+			reasons = append(reasons, apimodel.ConflictPersonDeleted)
+		} else if !element.Person.IsActive(element.Meeting.Date) {
+			reasons = append(reasons, apimodel.ConflictInactive)
+		}
+		if absent[key] || absentRules.inPeriod(element.PersonID, element.Meeting.Date) {
+			reasons = append(reasons, apimodel.ConflictAbsent)
+		}
+		if absentRules.recurs(element.PersonID, element.Meeting.Date) {
+			reasons = append(reasons, apimodel.ConflictRecurringAbsent)
+		}
+		if !qualified[personTaskOf(element.PersonID, element.TaskDetailID)] {
+			reasons = append(reasons, apimodel.ConflictNotQualified)
+		}
+		if booked[key] > 1 {
+			reasons = append(reasons, apimodel.ConflictDoubleBooked)
+		}
+		for _, reason := range reasons {
+			conflicts = append(conflicts, apimodel.PlanConflict{Plan: element, Reason: reason})
+		}
+	}
+
+	return conflicts, nil
+}
@@ -0,0 +1,74 @@
+package plan
+
+import (
+	"math"
+	"mpt_data/helper/config"
+	generalmodel "mpt_data/models/general"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// defaultDecay is used, if the decay of the history is not configured
+const defaultDecay = 0.5
+
+// daysPerMonth is the average length of a month, to calculate the age of an assignment in months
+const daysPerMonth = 30.44
+
+// historyEnabled reports if assignments before the planned period count for ranking people
+func historyEnabled() bool {
+	return config.Config.Plan.History.Months > 0
+}
+
+// historyDecay returns the configured weight of an assignment one month before the planned period
+func historyDecay() float64 {
+	decay := config.Config.Plan.History.Decay
+	if decay <= 0 || decay > 1 {
+		return defaultDecay
+	}
+	return decay
+}
+
+// historyWeight returns the weight of an assignment at date for a period starting at start.
+// The weight decreases exponentially with the age in months
+func historyWeight(date, start time.Time, decay float64) float64 {
+	months := start.Sub(date).Hours() / 24 / daysPerMonth
+	return math.Pow(decay, months)
+}
+
+// assignmentLoad counts the plan entries of the given people in period.
+// If a history is configured, the entries of the months before period are added with decreasing weight,
+// so planning one period at a time balances out over a longer time
+func assignmentLoad(db *gorm.DB, period generalmodel.Period, personIDs []uint) (map[uint]float64, error) {
+	counts, err := countAssignments(db, period, personIDs)
+	if err != nil {
+		return nil, err
+	}
+	load := make(map[uint]float64, len(counts))
+	for personID, count := range counts {
+		load[personID] = float64(count)
+	}
+	if !historyEnabled() {
+		return load, nil
+	}
+
+	var entries []struct {
+		PersonID uint
+		Date     time.Time
+	}
+	if err :=
+		db.Table("plans").
+			Joins("JOIN meetings m ON m.id = plans.meeting_id").
+			Where("plans.person_id IN (?)", personIDs).
+			Where("m.date >= ? AND m.date < ?", period.StartDate.AddDate(0, -int(config.Config.Plan.History.Months), 0), period.StartDate).
+			Select("plans.person_id, m.date").
+			Scan(&entries).Error; err != nil {
+		return nil, err
+	}
+
+	decay := historyDecay()
+	for _, entry := range entries {
+		load[entry.PersonID] += historyWeight(entry.Date, period.StartDate, decay)
+	}
+	return load, nil
+}
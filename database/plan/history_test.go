@@ -0,0 +1,132 @@
+package plan
+
+import (
+	"math"
+	"mpt_data/database"
+	"mpt_data/helper/config"
+	dbModel "mpt_data/models/dbmodel"
+	generalmodel "mpt_data/models/general"
+	"testing"
+	"time"
+)
+
+func TestAssignmentLoad(t *testing.T) {
+	// Prepare
+	db := database.DB.Begin()
+	history := config.Config.Plan.History
+	t.Cleanup(func() {
+		db.Rollback()
+		config.Config.Plan.History = history
+	})
+	people := []dbModel.Person{
+		{GivenName: "Anna", LastName: "History"},
+		{GivenName: "Ben", LastName: "History"},
+	}
+	meetings := []dbModel.Meeting{
+		// before the history
+		{Date: time.Date(2004, 1, 4, 0, 0, 0, 0, time.UTC)},
+		// one and two months before the period
+		{Date: time.Date(2004, 5, 2, 0, 0, 0, 0, time.UTC)},
+		{Date: time.Date(2004, 4, 1, 0, 0, 0, 0, time.UTC)},
+		// in the period
+		{Date: time.Date(2004, 6, 6, 0, 0, 0, 0, time.UTC)},
+	}
+	if err := db.Create(&people).Error; err != nil {
+		t.Fatalf("test preparation failed: %v", err)
+	}
+	if err := db.Create(&meetings).Error; err != nil {
+		t.Fatalf("test preparation failed: %v", err)
+	}
+	plans := []dbModel.Plan{
+		{PersonID: people[0].ID, MeetingID: meetings[0].ID},
+		{PersonID: people[0].ID, MeetingID: meetings[1].ID},
+		{PersonID: people[0].ID, MeetingID: meetings[2].ID},
+		{PersonID: people[1].ID, MeetingID: meetings[3].ID},
+	}
+	if err := db.Create(&plans).Error; err != nil {
+		t.Fatalf("test preparation failed: %v", err)
+	}
+	period := generalmodel.Period{
+		StartDate: time.Date(2004, 6, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2004, 6, 30, 0, 0, 0, 0, time.UTC),
+	}
+	weight := func(date time.Time, decay float64) float64 {
+		return historyWeight(date, period.StartDate, decay)
+	}
+
+	var testcases = []struct {
+		name     string
+		months   uint
+		decay    float64
+		expected map[uint]float64
+	}{
+		{
+			"history disabled",
+			0, 0.5,
+			map[uint]float64{people[0].ID: 0, people[1].ID: 1},
+		},
+		{
+			"history with decay",
+			3, 0.5,
+			map[uint]float64{
+				people[0].ID: weight(meetings[1].Date, 0.5) + weight(meetings[2].Date, 0.5),
+				people[1].ID: 1,
+			},
+		},
+		{
+			"history without decay",
+			4, 1,
+			map[uint]float64{people[0].ID: 2, people[1].ID: 1},
+		},
+		{
+			"invalid decay uses default",
+			3, 2,
+			map[uint]float64{
+				people[0].ID: weight(meetings[1].Date, defaultDecay) + weight(meetings[2].Date, defaultDecay),
+				people[1].ID: 1,
+			},
+		},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			config.Config.Plan.History.Months = testcase.months
+			config.Config.Plan.History.Decay = testcase.decay
+			// Act
+			load, err := assignmentLoad(db, period, []uint{people[0].ID, people[1].ID})
+			// Assert
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			for personID, expected := range testcase.expected {
+				if math.Abs(load[personID]-expected) > 1e-9 {
+					t.Errorf("expected load %f of person %d, got %f", expected, personID, load[personID])
+				}
+			}
+		})
+	}
+}
+
+func TestHistoryWeight(t *testing.T) {
+	start := time.Date(2004, 6, 1, 0, 0, 0, 0, time.UTC)
+	var testcases = []struct {
+		name     string
+		date     time.Time
+		expected float64
+	}{
+		{"at start", start, 1},
+		{"one month before", start.AddDate(0, 0, -30), 0.5},
+		{"two months before", start.AddDate(0, 0, -61), 0.25},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			// Act
+			weight := historyWeight(testcase.date, start, 0.5)
+			// Assert
+			if math.Abs(weight-testcase.expected) > 0.01 {
+				t.Errorf("expected weight %f, got %f", testcase.expected, weight)
+			}
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package plan
+
+import (
+	dbModel "mpt_data/models/dbmodel"
+
+	"gorm.io/gorm"
+)
+
+// meetingTasks holds the required TaskDetails per MeetingType
+type meetingTasks map[uint]map[uint]bool
+
+// loadMeetingTasks loads the required TaskDetails of all meeting types
+func loadMeetingTasks(db *gorm.DB) (meetingTasks, error) {
+	var rows []struct {
+		MeetingTypeID uint
+		TaskDetailID  uint
+	}
+	if err := db.Table("meeting_type_task_details").Select("meeting_type_id, task_detail_id").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	tasks := make(meetingTasks)
+	for _, row := range rows {
+		if tasks[row.MeetingTypeID] == nil {
+			tasks[row.MeetingTypeID] = make(map[uint]bool)
+		}
+		tasks[row.MeetingTypeID][row.TaskDetailID] = true
+	}
+	return tasks, nil
+}
+
+// requires reports if meeting needs a slot for the TaskDetail, meetings without type need all TaskDetails
+func (tasks meetingTasks) requires(meeting dbModel.Meeting, taskDetailID uint) bool {
+	if meeting.MeetingTypeID == nil {
+		return true
+	}
+	return tasks[*meeting.MeetingTypeID][taskDetailID]
+}
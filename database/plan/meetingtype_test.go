@@ -0,0 +1,80 @@
+package plan
+
+import (
+	"mpt_data/database"
+	dbModel "mpt_data/models/dbmodel"
+	generalmodel "mpt_data/models/general"
+	"testing"
+	"time"
+)
+
+func TestCreatePlanWithMeetingType(t *testing.T) {
+	// Prepare
+	db := database.DB.Begin()
+	t.Cleanup(func() { db.Rollback() })
+	task := dbModel.Task{Descr: "TypeTask"}
+	if err := db.Create(&task).Error; err != nil {
+		t.Fatalf("test preparation failed: %v", err)
+	}
+	details := []dbModel.TaskDetail{
+		{Descr: "TypeRequired", TaskID: task.ID},
+		{Descr: "TypeNotRequired", TaskID: task.ID},
+	}
+	if err := db.Create(&details).Error; err != nil {
+		t.Fatalf("test preparation failed: %v", err)
+	}
+	meetingType := dbModel.MeetingType{Descr: "Youth evening", TaskDetails: details[:1]}
+	if err := db.Omit("TaskDetails.*").Create(&meetingType).Error; err != nil {
+		t.Fatalf("test preparation failed: %v", err)
+	}
+	meetings := []dbModel.Meeting{
+		{Date: time.Date(2008, 1, 6, 0, 0, 0, 0, time.UTC)},
+		{Date: time.Date(2008, 1, 11, 0, 0, 0, 0, time.UTC), MeetingTypeID: &meetingType.ID},
+	}
+	if err := db.Create(&meetings).Error; err != nil {
+		t.Fatalf("test preparation failed: %v", err)
+	}
+	period := generalmodel.Period{StartDate: meetings[0].Date, EndDate: meetings[1].Date}
+
+	var testcases = []struct {
+		name   string
+		create func() error
+	}{
+		{"create", func() error {
+			_, err := CreatePlanData(db, period, leastLoaded{}, false, nil)
+			return err
+		}},
+		{"solve", func() error {
+			_, err := SolvePlanData(db, period, false)
+			return err
+		}},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			db.SavePoint("beforePlan")
+			defer db.RollbackTo("beforePlan")
+			// Act
+			if err := testcase.create(); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			// Assert
+			for _, expected := range []struct {
+				meeting  dbModel.Meeting
+				detail   dbModel.TaskDetail
+				required bool
+			}{
+				{meetings[0], details[0], true},
+				{meetings[0], details[1], true},
+				{meetings[1], details[0], true},
+				{meetings[1], details[1], false},
+			} {
+				var count int64
+				db.Model(&dbModel.Plan{}).Where("meeting_id = ? AND task_detail_id = ?", expected.meeting.ID, expected.detail.ID).Count(&count)
+				if (count != 0) != expected.required {
+					t.Errorf("expected slot of %s at %v %t, got %d", expected.detail.Descr, expected.meeting.Date, expected.required, count)
+				}
+			}
+		})
+	}
+}
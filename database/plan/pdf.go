@@ -28,6 +28,10 @@ type (
 		colorBackHeader    rgb
 		// 0 for even row, 1 for odd row
 		colorBack [2]rgb
+		// background of cells, whose TaskDetail is not required by the MeetingType
+		colorNotRequired rgb
+		// draft adds a watermark to every page, if the plan is not published
+		draft bool
 	}
 
 	// rgb represents the RGB color.
@@ -37,18 +41,21 @@ type (
 
 	// pdfDate holds the data that should be printed to pdf
 	pdfData struct {
-		tasks []dbModel.Task
-		data  []planData
+		tasks    []dbModel.Task
+		data     []planData
+		required meetingTasks
 	}
 
 	planData struct {
 		meeting dbModel.Meeting
 		tag     dbModel.Tag
-		person  map[*dbModel.TaskDetail]dbModel.Person
+		// people per TaskDetail ID, ordered by slot
+		people map[uint][]dbModel.Person
 	}
 )
 
 // GetOrCreatePDF generates a PDF file based on the provided period.
+// A frozen PDF of a published plan period is returned unchanged, even if the plan changed since publication
 func GetOrCreatePDF(db *gorm.DB, period generalmodel.Period) (path string, err error) {
 	var file dbModel.PDF
 	if err :=
@@ -56,11 +63,61 @@ func GetOrCreatePDF(db *gorm.DB, period generalmodel.Period) (path string, err e
 			Where("end_date = ?", period.EndDate).
 			First(&file).Error; err != nil {
 		zap.L().Error(generalmodel.DBLoadDataFailed, zap.Error(err))
-	} else if !file.DataChanged && file.FilePath != "" {
+	} else if (file.Frozen || !file.DataChanged) && file.FilePath != "" {
 		return file.FilePath, nil
 	}
 
+	return createPDF(db, file, period, false)
+}
+
+// freezePDF creates the PDF of a published plan period, which is returned by GetOrCreatePDF until it is unfrozen
+func freezePDF(db *gorm.DB, period generalmodel.Period) error {
+	var file dbModel.PDF
+	if err :=
+		db.Where("start_date = ?", period.StartDate).
+			Where("end_date = ?", period.EndDate).
+			Find(&file).Error; err != nil {
+		zap.L().Error(generalmodel.DBLoadDataFailed, zap.Error(err))
+		return err
+	}
+	_, err := createPDF(db, file, period, true)
+	return err
+}
+
+// unfreezePDF removes the frozen PDF of period, it is recreated on the next request
+func unfreezePDF(db *gorm.DB, period generalmodel.Period) error {
+	var files []dbModel.PDF
+	if err :=
+		db.Where("start_date = ?", period.StartDate).
+			Where("end_date = ?", period.EndDate).
+			Where("frozen = ?", true).
+			Find(&files).Error; err != nil {
+		zap.L().Error(generalmodel.DBLoadDataFailed, zap.Error(err))
+		return err
+	}
+	for _, file := range files {
+		if err := os.Remove(file.FilePath); err != nil && !os.IsNotExist(err) {
+			zap.L().Error(generalmodel.PDFRemovalFailed, zap.Error(err))
+		}
+		file.Frozen = false
+		file.DataChanged = true
+		if err := db.Save(&file).Error; err != nil {
+			zap.L().Error(generalmodel.DBUpdateDataFailed, zap.Error(err))
+			return err
+		}
+	}
+	return nil
+}
+
+// createPDF writes the PDF of period from the current plan and saves it as file
+func createPDF(db *gorm.DB, file dbModel.PDF, period generalmodel.Period, frozen bool) (path string, err error) {
 	pdf := getPDF()
+	published, err := isPublished(db, period)
+	if err != nil {
+		zap.L().Error(generalmodel.DBLoadDataFailed, zap.Error(err))
+		return "", err
+	}
+	pdf.draft = !published
 
 	headline := pdf.printDateTitle(period)
 
@@ -72,6 +129,10 @@ func GetOrCreatePDF(db *gorm.DB, period generalmodel.Period) (path string, err e
 	pdf.printTable(pdfData)
 
 	pdfName := fmt.Sprintf("Dienerplan-%s.pdf", strings.ReplaceAll(headline, " ", ""))
+	if frozen {
+		// other periods with the same headline must not overwrite the frozen file
+		pdfName = fmt.Sprintf("Dienerplan-%s-%s.pdf", period.StartDate.Format("20060102"), period.EndDate.Format("20060102"))
+	}
 	pdfFile := fmt.Sprintf("%s/%s", config.Config.PDF.Path, pdfName)
 
 	if err :=
@@ -81,9 +142,10 @@ func GetOrCreatePDF(db *gorm.DB, period generalmodel.Period) (path string, err e
 	}
 
 	if file.ID == 0 {
-		db.Create(&dbModel.PDF{Name: pdfName, FilePath: pdfFile, Period: period, DataChanged: false})
+		db.Create(&dbModel.PDF{Name: pdfName, FilePath: pdfFile, Period: period, DataChanged: false, Frozen: frozen})
 	} else {
 		file.DataChanged = false
+		file.Frozen = frozen
 		file.FilePath = pdfFile
 		file.Name = pdfName
 
@@ -121,6 +183,9 @@ func getPDF() *pdf {
 	}
 	pdf.file.SetMargins(2, 2, 2)
 	pdf.file.SetFooterFunc(func() {
+		if pdf.draft {
+			pdf.printWatermark("DRAFT")
+		}
 		pdf.file.SetY(-1.5)
 		pdf.file.SetFont("Times", "I", 10)
 		pdf.file.CellFormat(0, 1.0, fmt.Sprintf("Stand %s", time.Now().Format("02.01.2006")), "", 0, "C", false, 0, "")
@@ -135,6 +200,7 @@ func getPDF() *pdf {
 	pdf.colorBackHeader = rgb{r: 68, g: 113, b: 196}
 	pdf.colorBack[0] = rgb{r: 217, g: 226, b: 243}
 	pdf.colorBack[1] = rgb{r: 255, g: 255, b: 255}
+	pdf.colorNotRequired = rgb{r: 191, g: 191, b: 191}
 
 	return pdf
 }
@@ -144,6 +210,9 @@ func getPdfData(db *gorm.DB, period generalmodel.Period) (data pdfData, err erro
 	if data.tasks, err = task.GetTask(db); err != nil {
 		return data, err
 	}
+	if data.required, err = loadMeetingTasks(db); err != nil {
+		return data, err
+	}
 
 	planFields, err := GetPlan(period)
 	if err != nil {
@@ -155,7 +224,7 @@ func getPdfData(db *gorm.DB, period generalmodel.Period) (data pdfData, err erro
 		lastMeeting := planFields[0].MeetingID
 		currentPlanData := planData{
 			meeting: planFields[0].Meeting,
-			person:  make(map[*dbModel.TaskDetail]dbModel.Person),
+			people:  make(map[uint][]dbModel.Person),
 			tag:     planFields[0].Meeting.Tag,
 		}
 
@@ -165,11 +234,13 @@ func getPdfData(db *gorm.DB, period generalmodel.Period) (data pdfData, err erro
 				data.data = append(data.data, currentPlanData)
 				currentPlanData = planData{
 					meeting: plan.Meeting,
-					person:  make(map[*dbModel.TaskDetail]dbModel.Person),
+					people:  make(map[uint][]dbModel.Person),
 					tag:     plan.Meeting.Tag,
 				}
 			}
-			currentPlanData.person[&plan.TaskDetail] = plan.Person
+			if plan.PersonID != 0 {
+				currentPlanData.people[plan.TaskDetailID] = append(currentPlanData.people[plan.TaskDetailID], plan.Person)
+			}
 			lastMeeting = plan.MeetingID
 		}
 		data.data = append(data.data, currentPlanData)
@@ -201,12 +272,15 @@ func (pdf *pdf) printTable(data pdfData) {
 				continue
 			}
 
-			for i := 0; i < len(task.TaskDetails); i++ {
-				if person, ok := getEntryByAttributeValue(row.person, task.TaskDetails[i].ID); ok {
-					pdf.writeCell(width, fmt.Sprintf("%s %s", person.GivenName, person.LastName))
-				} else {
+			for _, taskDetail := range task.TaskDetails {
+				// TaskDetails not required by the MeetingType are blanked out
+				if !data.required.requires(row.meeting, taskDetail.ID) {
+					pdf.setFillColor(pdf.colorNotRequired)
 					pdf.writeCell(width, "")
+					pdf.setFillColor(pdf.colorBack[i%2])
+					continue
 				}
+				pdf.writeCell(width, joinNames(row.people[taskDetail.ID]))
 			}
 			pdf.file.Ln(-1)
 		}
@@ -244,6 +318,21 @@ func (pdf *pdf) printDateTitle(period generalmodel.Period) string {
 	return headline
 }
 
+// printWatermark prints text diagonal and transparent across the current page.
+func (pdf *pdf) printWatermark(text string) {
+	width, height := pdf.file.GetPageSize()
+
+	pdf.file.SetFont("Times", "B", 120)
+	pdf.setTextColor(rgb{r: 192, g: 0, b: 0})
+	pdf.file.SetAlpha(0.2, "Normal")
+	pdf.file.TransformBegin()
+	pdf.file.TransformRotate(45, width/2, height/2)
+	pdf.file.Text((width-pdf.file.GetStringWidth(text))/2, height/2, text)
+	pdf.file.TransformEnd()
+	pdf.file.SetAlpha(1, "Normal")
+	pdf.setTextColor(rgb{0, 0, 0})
+}
+
 // setFillColor sets the fill color of the PDF.
 func (pdf *pdf) setFillColor(rgb rgb) { pdf.file.SetFillColor(rgb.r, rgb.g, rgb.b) }
 
@@ -276,11 +365,15 @@ func (pdf *pdf) printTaskHeader(headline dbModel.Task) {
 	pdf.file.Ln(-1)
 }
 
-func getEntryByAttributeValue(taskDetailsMap map[*dbModel.TaskDetail]dbModel.Person, attributeValue uint) (dbModel.Person, bool) {
-	for taskDetail, person := range taskDetailsMap {
-		if taskDetail.ID == attributeValue {
-			return person, true
+// joinNames joins the display names of people to the text of one cell, the full names if not set
+func joinNames(people []dbModel.Person) string {
+	names := make([]string, 0, len(people))
+	for _, person := range people {
+		if person.DisplayName != "" {
+			names = append(names, person.DisplayName)
+			continue
 		}
+		names = append(names, person.FullName())
 	}
-	return dbModel.Person{}, false
+	return strings.Join(names, ", ")
 }
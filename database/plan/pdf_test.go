@@ -0,0 +1,124 @@
+package plan
+
+import (
+	"bytes"
+	"mpt_data/database"
+	dbModel "mpt_data/models/dbmodel"
+	generalmodel "mpt_data/models/general"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestJoinNames(t *testing.T) {
+	var testcases = []struct {
+		name     string
+		people   []dbModel.Person
+		expected string
+	}{
+		{"nobody", nil, ""},
+		{"one person", []dbModel.Person{{GivenName: "Max", LastName: "Maier"}}, "Max Maier"},
+		{"several people", []dbModel.Person{{GivenName: "Max", LastName: "Maier"}, {GivenName: "Eva", LastName: "Huber"}}, "Max Maier, Eva Huber"},
+		{"display name", []dbModel.Person{{GivenName: "Anna", LastName: "Müller", DisplayName: "Anna Müller (jun.)"}, {GivenName: "Eva", LastName: "Huber"}}, "Anna Müller (jun.), Eva Huber"},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			// Act
+			result := joinNames(testcase.people)
+			// Assert
+			if result != testcase.expected {
+				t.Errorf("expected %q, got %q", testcase.expected, result)
+			}
+		})
+	}
+}
+
+func TestFrozenPDF(t *testing.T) {
+	// Prepare
+	db := database.DB.Begin()
+	t.Cleanup(func() {
+		db.Rollback()
+	})
+	people := []dbModel.Person{
+		{GivenName: "Anna", LastName: "Frozen"},
+		{GivenName: "Ben", LastName: "Frozen"},
+	}
+	task := dbModel.Task{Descr: "FrozenTask"}
+	if err := db.Create(&people).Error; err != nil {
+		t.Fatalf("test preparation failed: %v", err)
+	}
+	if err := db.Create(&task).Error; err != nil {
+		t.Fatalf("test preparation failed: %v", err)
+	}
+	detail := dbModel.TaskDetail{Descr: "FrozenDetail", TaskID: task.ID}
+	meeting := dbModel.Meeting{Date: time.Date(2004, 3, 7, 0, 0, 0, 0, time.UTC)}
+	if err := db.Create(&detail).Error; err != nil {
+		t.Fatalf("test preparation failed: %v", err)
+	}
+	if err := db.Create(&meeting).Error; err != nil {
+		t.Fatalf("test preparation failed: %v", err)
+	}
+	element := dbModel.Plan{PersonID: people[0].ID, MeetingID: meeting.ID, TaskDetailID: detail.ID}
+	if err := db.Create(&element).Error; err != nil {
+		t.Fatalf("test preparation failed: %v", err)
+	}
+	period := dbModel.PlanPeriod{Period: generalmodel.Period{
+		StartDate: time.Date(2004, 3, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2004, 3, 31, 0, 0, 0, 0, time.UTC),
+	}}
+	if err := AddPlanPeriod(db, &period); err != nil {
+		t.Fatalf("test preparation failed: %v", err)
+	}
+
+	// Act
+	if _, err := UpdatePlanPeriodState(db, period.ID, dbModel.PlanPeriodPublished); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	published, err := GetOrCreatePDF(db, period.Period)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	t.Cleanup(func() {
+		os.Remove(published)
+	})
+	content, err := os.ReadFile(published)
+	if err != nil {
+		t.Fatalf("expected frozen PDF, got %v", err)
+	}
+	if err := db.Model(&element).Update("person_id", people[1].ID).Error; err != nil {
+		t.Fatalf("test preparation failed: %v", err)
+	}
+	if err := markPDFChanged(db, period.Period); err != nil {
+		t.Fatalf("test preparation failed: %v", err)
+	}
+	changed, err := GetOrCreatePDF(db, period.Period)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if changedContent, _ := os.ReadFile(changed); changed != published || !bytes.Equal(changedContent, content) {
+		t.Errorf("expected frozen PDF %s to be unchanged, got %s", published, changed)
+	}
+
+	// Act
+	if _, err := UpdatePlanPeriodState(db, period.ID, dbModel.PlanPeriodDraft); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	draft, err := GetOrCreatePDF(db, period.Period)
+	t.Cleanup(func() {
+		os.Remove(draft)
+	})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if draft == published {
+		t.Errorf("expected recreated PDF, got frozen %s", draft)
+	}
+	if _, err := os.Stat(published); !os.IsNotExist(err) {
+		t.Errorf("expected frozen PDF %s to be removed, got %v", published, err)
+	}
+}
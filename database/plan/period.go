@@ -0,0 +1,158 @@
+package plan
+
+import (
+	"mpt_data/helper/errors"
+	dbModel "mpt_data/models/dbmodel"
+	generalmodel "mpt_data/models/general"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// GetPlanPeriods loads all plan periods overlapping with the specified period, ordered by start date
+func GetPlanPeriods(db *gorm.DB, period generalmodel.Period) (periods []dbModel.PlanPeriod, err error) {
+	if err :=
+		db.Where("start_date <= ?", period.EndDate).
+			Where("end_date >= ?", period.StartDate).
+			Order("start_date").
+			Find(&periods).Error; err != nil {
+		return nil, err
+	}
+	return periods, nil
+}
+
+// periodOf returns the plan period containing date, or the month of date if it is in no plan period.
+// Caps for a period are checked against it, when a plan element is changed
+func periodOf(db *gorm.DB, date time.Time) (generalmodel.Period, error) {
+	periods, err := GetPlanPeriods(db, generalmodel.Period{StartDate: date, EndDate: date})
+	if err != nil {
+		return generalmodel.Period{}, err
+	}
+	if len(periods) == 0 {
+		return monthOf(date), nil
+	}
+	return periods[0].Period, nil
+}
+
+// AddPlanPeriod adds a new plan period in state draft, it must not overlap with other plan periods
+func AddPlanPeriod(db *gorm.DB, period *dbModel.PlanPeriod) error {
+	if period.StartDate.IsZero() || period.EndDate.Before(period.StartDate) {
+		return errors.ErrInvalidPlanPeriod
+	}
+
+	overlapping, err := GetPlanPeriods(db, period.Period)
+	if err != nil {
+		return err
+	}
+	if len(overlapping) != 0 {
+		return errors.ErrPlanPeriodOverlap
+	}
+
+	period.ID = 0
+	period.State = dbModel.PlanPeriodDraft
+	if err := db.Create(period).Error; err != nil {
+		zap.L().Error(generalmodel.DBSaveDataFailed, zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// UpdatePlanPeriodState changes the state of a plan period, PDFs of the period are recreated afterwards.
+// At publication the PDF of the plan period is frozen, until the plan period is published again or set back to draft
+func UpdatePlanPeriodState(db *gorm.DB, id uint, state string) (period dbModel.PlanPeriod, err error) {
+	switch state {
+	case dbModel.PlanPeriodDraft, dbModel.PlanPeriodPublished, dbModel.PlanPeriodArchived:
+	default:
+		return period, errors.ErrInvalidPlanPeriod
+	}
+
+	if err := db.First(&period, id).Error; err != nil {
+		return period, err
+	}
+	if period.State == dbModel.PlanPeriodArchived && state != dbModel.PlanPeriodArchived {
+		return period, errors.ErrPlanPeriodArchived
+	}
+
+	changes := map[string]interface{}{"state": state}
+	if state == dbModel.PlanPeriodPublished && period.State != dbModel.PlanPeriodPublished {
+		changes["published_at"] = time.Now().UTC()
+	}
+	if err := db.Model(&period).Updates(changes).Error; err != nil {
+		zap.L().Error(generalmodel.DBUpdateDataFailed, zap.Error(err))
+		return period, err
+	}
+	if err := markPDFChanged(db, period.Period); err != nil {
+		return period, err
+	}
+
+	period.State = state
+	switch state {
+	case dbModel.PlanPeriodPublished:
+		err = freezePDF(db, period.Period)
+	case dbModel.PlanPeriodDraft:
+		err = unfreezePDF(db, period.Period)
+	}
+	return period, err
+}
+
+// DeletePlanPeriod deletes a plan period, the plan itself is not changed
+func DeletePlanPeriod(db *gorm.DB, id uint) error {
+	var period dbModel.PlanPeriod
+	if err := db.First(&period, id).Error; err != nil {
+		return err
+	}
+	if err := db.Unscoped().Delete(&period).Error; err != nil {
+		zap.L().Error(generalmodel.DBDeleteDataFailed, zap.Error(err))
+		return err
+	}
+	if err := markPDFChanged(db, period.Period); err != nil {
+		return err
+	}
+	return unfreezePDF(db, period.Period)
+}
+
+// meetingsNotPublished builds a query for all meetings in period, which are not in a published or archived plan period
+func meetingsNotPublished(db *gorm.DB, period generalmodel.Period) *gorm.DB {
+	return db.Table("meetings m").
+		Where("m.date between ? and ?", period.StartDate, period.EndDate).
+		Where("m.deleted_at IS NULL").
+		Where("NOT EXISTS (?)",
+			db.Table("plan_periods pp").
+				Select("1").
+				Where("pp.state <> ?", dbModel.PlanPeriodDraft).
+				Where("pp.deleted_at IS NULL").
+				Where("m.date between pp.start_date and pp.end_date"))
+}
+
+// checkNotPublished returns ErrPlanPublished, if a meeting in period is in a published or archived plan period.
+// With force no error is returned
+func checkNotPublished(db *gorm.DB, period generalmodel.Period, force bool) error {
+	if force {
+		return nil
+	}
+
+	var meetings, notPublished int64
+	if err := db.Model(&dbModel.Meeting{}).Where("date between ? and ?", period.StartDate, period.EndDate).Count(&meetings).Error; err != nil {
+		return err
+	}
+	if err := meetingsNotPublished(db, period).Count(&notPublished).Error; err != nil {
+		return err
+	}
+	if notPublished != meetings {
+		return errors.ErrPlanPublished
+	}
+	return nil
+}
+
+// isPublished reports if period has meetings and all of them are in a published or archived plan period
+func isPublished(db *gorm.DB, period generalmodel.Period) (bool, error) {
+	var meetings, notPublished int64
+	if err := db.Model(&dbModel.Meeting{}).Where("date between ? and ?", period.StartDate, period.EndDate).Count(&meetings).Error; err != nil {
+		return false, err
+	}
+	if err := meetingsNotPublished(db, period).Count(&notPublished).Error; err != nil {
+		return false, err
+	}
+	return meetings != 0 && notPublished == 0, nil
+}
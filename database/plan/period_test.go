@@ -0,0 +1,134 @@
+package plan
+
+import (
+	"mpt_data/database"
+	dbModel "mpt_data/models/dbmodel"
+	generalmodel "mpt_data/models/general"
+	"testing"
+	"time"
+)
+
+func TestPeriodOf(t *testing.T) {
+	// Prepare
+	db := database.DB.Begin()
+	t.Cleanup(func() {
+		db.Rollback()
+	})
+	period := dbModel.PlanPeriod{Period: generalmodel.Period{
+		StartDate: time.Date(2006, 1, 15, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2006, 2, 14, 0, 0, 0, 0, time.UTC),
+	}}
+	if err := AddPlanPeriod(db, &period); err != nil {
+		t.Fatalf("test preparation failed: %v", err)
+	}
+	outside := time.Date(2006, 3, 10, 0, 0, 0, 0, time.UTC)
+
+	var testcases = []struct {
+		name   string
+		date   time.Time
+		period generalmodel.Period
+	}{
+		{"start of plan period", period.StartDate, period.Period},
+		{"in plan period", time.Date(2006, 2, 1, 0, 0, 0, 0, time.UTC), period.Period},
+		{"no plan period", outside, monthOf(outside)},
+	}
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			// Act
+			result, err := periodOf(db, testcase.date)
+			// Assert
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if !result.StartDate.Equal(testcase.period.StartDate) || !result.EndDate.Equal(testcase.period.EndDate) {
+				t.Errorf("expected %v, got %v", testcase.period, result)
+			}
+		})
+	}
+}
+
+func TestCapPeriodOfSubRange(t *testing.T) {
+	// Prepare
+	db := database.DB.Begin()
+	t.Cleanup(func() {
+		db.Rollback()
+	})
+	period := dbModel.PlanPeriod{Period: generalmodel.Period{
+		StartDate: time.Date(2012, 3, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2012, 4, 30, 0, 0, 0, 0, time.UTC),
+	}}
+	if err := AddPlanPeriod(db, &period); err != nil {
+		t.Fatalf("test preparation failed: %v", err)
+	}
+	task := dbModel.Task{Descr: "SubRangeTask"}
+	if err := db.Create(&task).Error; err != nil {
+		t.Fatalf("test preparation failed: %v", err)
+	}
+	detail := dbModel.TaskDetail{Descr: "SubRangeDetail", TaskID: task.ID}
+	// Anna is capped per period, Ben per TaskDetail, both served once in March
+	people := []dbModel.Person{
+		{GivenName: "Anna", LastName: "SubRange", MaxAssignmentsPeriod: 1},
+		{GivenName: "Ben", LastName: "SubRange"},
+	}
+	meetings := []dbModel.Meeting{
+		{Date: time.Date(2012, 3, 4, 10, 0, 0, 0, time.UTC)},
+		{Date: time.Date(2012, 4, 8, 10, 0, 0, 0, time.UTC)},
+	}
+	for _, value := range []interface{}{&detail, &people, &meetings} {
+		if err := db.Create(value).Error; err != nil {
+			t.Fatalf("test preparation failed: %v", err)
+		}
+	}
+	personTasks := []dbModel.PersonTask{
+		{PersonID: people[0].ID, TaskDetailID: detail.ID},
+		{PersonID: people[1].ID, TaskDetailID: detail.ID, MaxAssignmentsPeriod: 1},
+	}
+	existing := []dbModel.Plan{
+		{PersonID: people[0].ID, MeetingID: meetings[0].ID, TaskDetailID: detail.ID},
+		{PersonID: people[1].ID, MeetingID: meetings[0].ID, TaskDetailID: detail.ID, Slot: 1},
+	}
+	for _, value := range []interface{}{&personTasks, &existing} {
+		if err := db.Create(value).Error; err != nil {
+			t.Fatalf("test preparation failed: %v", err)
+		}
+	}
+	// April is a sub-range of the plan period, in its own month both would be free
+	april := generalmodel.Period{
+		StartDate: time.Date(2012, 4, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2012, 4, 30, 0, 0, 0, 0, time.UTC),
+	}
+
+	var testcases = []struct {
+		name   string
+		create func() error
+	}{
+		{"create", func() error {
+			_, err := CreatePlanData(db, april, leastLoaded{}, false, nil)
+			return err
+		}},
+		{"solve", func() error {
+			_, err := SolvePlanData(db, april, false)
+			return err
+		}},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			db.SavePoint("beforePlan")
+			defer db.RollbackTo("beforePlan")
+			// Act
+			if err := testcase.create(); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			// Assert
+			var count int64
+			db.Model(&dbModel.Plan{}).
+				Where("meeting_id = ? AND task_detail_id = ?", meetings[1].ID, detail.ID).
+				Where("person_id IN (?)", []uint{people[0].ID, people[1].ID}).
+				Count(&count)
+			if count != 0 {
+				t.Errorf("expected no assignment exceeding the caps of the plan period, got %d", count)
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package plan
 
 import (
 	"mpt_data/database"
+	"mpt_data/helper/calendar"
 	"mpt_data/helper/errors"
 	"mpt_data/models/apimodel"
 	"mpt_data/models/dbmodel"
@@ -14,9 +15,13 @@ import (
 	"gorm.io/gorm"
 )
 
-const packageName = "database.plan"
+const (
+	packageName = "database.plan"
+	// previewSavePoint is the savepoint, to which PreviewPlanData rolls back
+	previewSavePoint = "planPreview"
+)
 
-// GetPlan loads all plan items in the specified Period.
+// GetPlan loads all plan items in the specified Period with the display names of the people.
 // Ordered by the date of the meeting
 func GetPlan(period generalmodel.Period) ([]dbModel.Plan, error) {
 	var plan []dbModel.Plan
@@ -29,6 +34,33 @@ func GetPlan(period generalmodel.Period) ([]dbModel.Plan, error) {
 			Joins("JOIN meetings m on m.id = meeting_id").
 			Where("meeting_id IN (?)", database.DB.Table("meetings").Where("date between ? and ?", period.StartDate, period.EndDate).Select("id")).
 			Order("m.date asc").
+			Order("slot asc").
+			Find(&plan).Error; err != nil {
+		return nil, err
+	}
+	people := make([]*dbModel.Person, 0, len(plan))
+	for i := range plan {
+		if plan[i].PersonID != 0 {
+			people = append(people, &plan[i].Person)
+		}
+	}
+	dbModel.SetDisplayNames(people...)
+	return plan, nil
+}
+
+// GetPlanOfPerson loads all plan items of the person in the specified Period.
+// Ordered by the date of the meeting
+func GetPlanOfPerson(db *gorm.DB, personID uint, period generalmodel.Period) (plan []dbModel.Plan, err error) {
+	if err :=
+		db.Preload("Meeting.Tag").
+			Preload("Meeting").
+			Preload("TaskDetail.Task").
+			Preload("TaskDetail").
+			Joins("JOIN meetings m on m.id = meeting_id").
+			Where("m.date between ? and ?", period.StartDate, period.EndDate).
+			Where("person_id = ?", personID).
+			Order("m.date asc").
+			Order("slot asc").
 			Find(&plan).Error; err != nil {
 		return nil, err
 	}
@@ -50,16 +82,36 @@ func GetPlanWithID(planID uint) (plan dbModel.Plan, err error) {
 	return plan, nil
 }
 
-// CreatePlanData creates all entries in table plans for the specified period and if people are available they will be automatically assigned
-func CreatePlanData(db *gorm.DB, period generalmodel.Period) ([]dbModel.Plan, error) {
+// CreatePlanData creates all entries in table plans for the specified period and if people are available they will be automatically assigned.
+// The strategy decides which of the available people is assigned, meetings with a MeetingType only get slots for its TaskDetails.
+// If a meeting in period is published, the plan is only created with force.
+// Changes of people by relations are recorded as PlanRevision with the acting user
+func CreatePlanData(db *gorm.DB, period generalmodel.Period, strategy AssignmentStrategy, force bool, userID *uint) ([]dbModel.Plan, error) {
+	return createPlanData(db, period, strategy, force, userID, zap.L())
+}
+
+// PreviewPlanData computes the plan, which CreatePlanData would create for the specified period, without changing anything.
+// Plan elements, revisions and changed PDFs are rolled back afterwards and nothing is logged, published plans are previewed as well.
+// Returns the proposed plan elements and the slots, which stayed empty
+func PreviewPlanData(db *gorm.DB, period generalmodel.Period, strategy AssignmentStrategy, userID *uint) (apimodel.PlanResult, error) {
+	db.SavePoint(previewSavePoint)
+	defer db.RollbackTo(previewSavePoint)
+
+	plan, err := createPlanData(db, period, strategy, true, userID, zap.NewNop())
+	if err != nil {
+		return apimodel.PlanResult{}, err
+	}
+	return NewPlanResult(plan), nil
+}
+
+// createPlanData creates the plan as described for CreatePlanData, failures are logged with logger
+func createPlanData(db *gorm.DB, period generalmodel.Period, strategy AssignmentStrategy, force bool, userID *uint, logger *zap.Logger) ([]dbModel.Plan, error) {
 	const funcName = packageName + ".CreatePlanData"
-	err :=
-		db.Model(&dbModel.PDF{}).
-			Where("start_date between ? and ?", period.StartDate, period.EndDate).
-			Or("end_date between ? and ?", period.StartDate, period.EndDate).
-			Update("data_changed", true).Error
-	if err != nil && err != gorm.ErrRecordNotFound {
-		zap.L().Error(generalmodel.PlanCreationFailed, zap.Error(err), zap.String(generalmodel.AdditionalInfo, "PDF loading failed"))
+	if err := checkNotPublished(db, period, force); err != nil {
+		return nil, err
+	}
+	if err := markPDFChanged(db, period); err != nil {
+		logger.Error(generalmodel.PlanCreationFailed, zap.Error(err), zap.String(generalmodel.AdditionalInfo, "PDF loading failed"))
 		return nil, err
 	}
 
@@ -67,13 +119,18 @@ func CreatePlanData(db *gorm.DB, period generalmodel.Period) ([]dbModel.Plan, er
 	if err :=
 		db.Preload("Tag").Where("date between ? and ?", period.StartDate, period.EndDate).
 			Order("date").Find(&meetings).Error; err != nil {
-		zap.L().Error(generalmodel.PlanCreationFailed, zap.Error(err), zap.String(generalmodel.AdditionalInfo, "Failed to load meetings"))
+		logger.Error(generalmodel.PlanCreationFailed, zap.Error(err), zap.String(generalmodel.AdditionalInfo, "Failed to load meetings"))
 		return nil, err
 	}
 
 	var tasks []dbModel.TaskDetail
 	if err := db.Find(&tasks).Error; err != nil {
-		zap.L().Error(generalmodel.PlanCreationFailed, zap.Error(err), zap.String(generalmodel.AdditionalInfo, "Failed to load tasks"))
+		logger.Error(generalmodel.PlanCreationFailed, zap.Error(err), zap.String(generalmodel.AdditionalInfo, "Failed to load tasks"))
+		return nil, err
+	}
+	required, err := loadMeetingTasks(db)
+	if err != nil {
+		logger.Error(generalmodel.PlanCreationFailed, zap.Error(err), zap.String(generalmodel.AdditionalInfo, "Failed to load meeting types"))
 		return nil, err
 	}
 
@@ -85,34 +142,121 @@ func CreatePlanData(db *gorm.DB, period generalmodel.Period) ([]dbModel.Plan, er
 				continue
 			}
 			if err := db.Create(&dbmodel.Plan{MeetingID: meeting.ID}).Error; err != nil {
-				zap.L().Error(generalmodel.PlanCreationFailed, zap.Error(err), zap.String(generalmodel.AdditionalInfo, "Failed to create plan element"))
+				logger.Error(generalmodel.PlanCreationFailed, zap.Error(err), zap.String(generalmodel.AdditionalInfo, "Failed to create plan element"))
 				db.RollbackTo("beforePlanCreation")
 			}
 			continue
 		}
 
+		var meetingPlanIDs []uint
 		for _, task := range tasks {
-			var ids []uint
-			if db.Table("plans").Where("meeting_id = ?", meeting.ID).Where("task_detail_id = ?", task.ID).Select("id").Find(&ids); len(ids) != 0 {
+			if !required.requires(meeting, task.ID) {
 				continue
 			}
+			for slot := uint(0); slot < task.Slots(); slot++ {
+				var ids []uint
+				if db.Table("plans").Where("meeting_id = ?", meeting.ID).Where("task_detail_id = ?", task.ID).Where("slot = ?", slot).Select("id").Find(&ids); len(ids) != 0 {
+					continue
+				}
 
-			person, err := getFirstPersonAvailable(meeting, task, period, db)
-			if err != nil {
-				zap.L().Info(generalmodel.PlanCreationError, zap.Error(err), zap.String(generalmodel.AdditionalInfo, "person loading error"))
+				person, err := getFirstPersonAvailable(meeting, task, period, db, strategy)
+				if err != nil {
+					logger.Info(generalmodel.PlanCreationError, zap.Error(err), zap.String(generalmodel.AdditionalInfo, "person loading error"))
+				}
+				if person == nil {
+					person = &dbModel.Person{}
+				}
+				plan := dbModel.Plan{PersonID: person.ID, MeetingID: meeting.ID, TaskDetailID: task.ID, Slot: slot}
+				db.SavePoint("beforePlanCreation")
+				if err := db.Create(&plan).Error; err != nil {
+					db.RollbackTo("beforePlanCreation")
+					logger.Error(generalmodel.PlanCreationFailed, zap.Error(err), zap.String(generalmodel.AdditionalInfo, "Failed to create plan element"))
+				} else {
+					planIDs = append(planIDs, plan.ID)
+					meetingPlanIDs = append(meetingPlanIDs, plan.ID)
+				}
 			}
-			if person == nil {
-				person = &dbModel.Person{}
+		}
+
+		db.SavePoint("beforeRelations")
+		if err := enforceRelations(db, meeting, meetingPlanIDs, period, strategy, userID); err != nil {
+			db.RollbackTo("beforeRelations")
+			logger.Info(generalmodel.PlanCreationError, zap.Error(err), zap.String(generalmodel.AdditionalInfo, "relations could not be enforced"))
+		}
+	}
+
+	var plan []dbModel.Plan
+	if err :=
+		db.Preload("Person").
+			Preload("Meeting").
+			Preload("TaskDetail.Task").
+			Preload("TaskDetail").
+			Where("id IN (?)", planIDs).Find(&plan).Error; err != nil {
+		return nil, err
+	}
+
+	return plan, nil
+}
+
+// FillPlanData assigns people to all plan elements without a person in the specified period.
+// Elements that already have a person are not changed. Returns the elements that got a person.
+// If a meeting in period is published, the plan is only filled with force.
+// Every change is recorded as PlanRevision with the acting user
+func FillPlanData(db *gorm.DB, period generalmodel.Period, strategy AssignmentStrategy, force bool, userID *uint) ([]dbModel.Plan, error) {
+	if err := checkNotPublished(db, period, force); err != nil {
+		return nil, err
+	}
+	if err := markPDFChanged(db, period); err != nil {
+		zap.L().Error(generalmodel.PlanCreationFailed, zap.Error(err), zap.String(generalmodel.AdditionalInfo, "PDF loading failed"))
+		return nil, err
+	}
+
+	var empty []dbModel.Plan
+	if err :=
+		db.Preload("Meeting").
+			Joins("JOIN meetings m on m.id = meeting_id").
+			Where("m.date between ? and ?", period.StartDate, period.EndDate).
+			Where("person_id = 0").
+			Where("task_detail_id <> 0").
+			Order("m.date asc").
+			Order("task_detail_id asc").
+			Order("slot asc").
+			Find(&empty).Error; err != nil {
+		zap.L().Error(generalmodel.PlanCreationFailed, zap.Error(err), zap.String(generalmodel.AdditionalInfo, "Failed to load plan elements"))
+		return nil, err
+	}
+
+	emptyOfMeeting := make(map[uint][]uint)
+	for _, element := range empty {
+		person, err := getFirstPersonAvailable(element.Meeting, dbModel.TaskDetail{ID: element.TaskDetailID}, period, db, strategy)
+		if err != nil {
+			zap.L().Info(generalmodel.PlanCreationError, zap.Error(err), zap.String(generalmodel.AdditionalInfo, "person loading error"))
+		}
+		if person != nil {
+			if err := db.Table("plans").Where("id = ?", element.ID).Update("person_id", person.ID).Error; err != nil {
+				zap.L().Error(generalmodel.PlanCreationFailed, zap.Error(err), zap.String(generalmodel.AdditionalInfo, "Failed to update plan element"))
+				return nil, err
 			}
-			plan := dbModel.Plan{PersonID: person.ID, MeetingID: meeting.ID, TaskDetailID: task.ID}
-			db.SavePoint("beforePlanCreation")
-			if err := db.Create(&plan).Error; err != nil {
-				db.RollbackTo("beforePlanCreation")
-				zap.L().Error(generalmodel.PlanCreationFailed, zap.Error(err), zap.String(generalmodel.AdditionalInfo, "Failed to create plan element"))
-			} else {
-				planIDs = append(planIDs, plan.ID)
+			if err := recordRevision(db, element.ID, 0, person.ID, userID, RevisionFill); err != nil {
+				return nil, err
 			}
 		}
+		emptyOfMeeting[element.MeetingID] = append(emptyOfMeeting[element.MeetingID], element.ID)
+	}
+
+	var ids []uint
+	for _, element := range empty {
+		planIDs, ok := emptyOfMeeting[element.MeetingID]
+		if !ok {
+			continue
+		}
+		delete(emptyOfMeeting, element.MeetingID)
+		db.SavePoint("beforeRelations")
+		if err := enforceRelations(db, element.Meeting, planIDs, period, strategy, userID); err != nil {
+			db.RollbackTo("beforeRelations")
+			zap.L().Info(generalmodel.PlanCreationError, zap.Error(err), zap.String(generalmodel.AdditionalInfo, "relations could not be enforced"))
+		}
+		ids = append(ids, planIDs...)
 	}
 
 	var plan []dbModel.Plan
@@ -121,15 +265,45 @@ func CreatePlanData(db *gorm.DB, period generalmodel.Period) ([]dbModel.Plan, er
 			Preload("Meeting").
 			Preload("TaskDetail.Task").
 			Preload("TaskDetail").
-			Where("id IN (?)", planIDs).Find(&plan).Error; err != nil {
+			Where("id IN (?)", ids).
+			Where("person_id <> 0").
+			Find(&plan).Error; err != nil {
 		return nil, err
 	}
 
 	return plan, nil
 }
 
-// UpdatePlanElement updates personId to the parameter, parameter also holds id for update
-func UpdatePlanElement(element dbModel.Plan) error {
+// NewPlanResult creates a PlanResult for the plan elements, every element without a person is an unfilled slot.
+// Elements without a task, e.g. for tagged meetings, are no slots
+func NewPlanResult(plan []dbModel.Plan) apimodel.PlanResult {
+	result := apimodel.PlanResult{Plan: plan}
+	for _, element := range plan {
+		if element.PersonID == 0 && element.TaskDetailID != 0 {
+			result.Unfilled = append(result.Unfilled, element)
+		}
+	}
+	result.Complete = len(result.Unfilled) == 0
+	return result
+}
+
+// markPDFChanged flags all PDFs overlapping with period as changed
+func markPDFChanged(db *gorm.DB, period generalmodel.Period) error {
+	err :=
+		db.Model(&dbModel.PDF{}).
+			Where("start_date between ? and ?", period.StartDate, period.EndDate).
+			Or("end_date between ? and ?", period.StartDate, period.EndDate).
+			Update("data_changed", true).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return err
+	}
+	return nil
+}
+
+// UpdatePlanElement updates personId to the parameter, parameter also holds id for update.
+// If the meeting is published, the element is only updated with force.
+// The change is recorded as PlanRevision with the acting user and reason
+func UpdatePlanElement(element dbModel.Plan, force bool, userID *uint, reason string) error {
 	db := database.DB.Begin()
 	defer db.Commit()
 
@@ -143,6 +317,34 @@ func UpdatePlanElement(element dbModel.Plan) error {
 		return errors.ErrTaskForPersonNotAllowed
 	}
 
+	var meeting dbModel.Meeting
+	if err :=
+		db.Where("id = (?)", db.Table("plans").Where("id = ?", element.ID).Select("meeting_id")).
+			First(&meeting).Error; err != nil {
+		db.Rollback()
+		return err
+	}
+	if err := checkNotPublished(db, generalmodel.Period{StartDate: meeting.Date, EndDate: meeting.Date}, force); err != nil {
+		db.Rollback()
+		return err
+	}
+
+	if err := checkAssignmentCaps(db, element, meeting, p, nil); err != nil {
+		db.Rollback()
+		return err
+	}
+
+	if err := checkRelations(db, element); err != nil {
+		db.Rollback()
+		return err
+	}
+
+	var oldPersonID uint
+	if err := db.Table("plans").Where("id = ?", element.ID).Select("person_id").Scan(&oldPersonID).Error; err != nil {
+		db.Rollback()
+		return err
+	}
+
 	if err := db.Table("plans").
 		Where("id = ?", element.ID).
 		Update("person_id", element.PersonID).Error; err != nil {
@@ -150,6 +352,11 @@ func UpdatePlanElement(element dbModel.Plan) error {
 		return err
 	}
 
+	if err := recordRevision(db, element.ID, oldPersonID, element.PersonID, userID, reason); err != nil {
+		db.Rollback()
+		return err
+	}
+
 	if err :=
 		db.Table("pdfs").
 			Where("(?) between start_date and end_date", db.Table("meetings").
@@ -163,19 +370,81 @@ func UpdatePlanElement(element dbModel.Plan) error {
 	return nil
 }
 
+// checkAssignmentCaps checks if the person of element may get one more assignment.
+// The caps for a period are checked against the plan period of the meeting, as at generation of the plan period,
+// or against the month of the meeting, if it is in no plan period.
+// The plan elements released are not counted, as the person gives them up with the change
+func checkAssignmentCaps(db *gorm.DB, element dbModel.Plan, meeting dbModel.Meeting, personTask dbModel.PersonTask, released []uint) error {
+	var person dbModel.Person
+	if err := db.First(&person, element.PersonID).Error; err != nil {
+		return err
+	}
+
+	period, err := periodOf(db, meeting.Date)
+	if err != nil {
+		return err
+	}
+	month := monthOf(meeting.Date)
+
+	assignments := func(period generalmodel.Period) *gorm.DB {
+		return db.Table("plans").
+			Where("person_id = ?", element.PersonID).
+			Where("id NOT IN (?)", append([]uint{element.ID}, released...)).
+			Where("meeting_id IN (?)", db.Table("meetings").Where("date between ? and ?", period.StartDate, period.EndDate).Select("id"))
+	}
+	var all, task, inMonth int64
+	if err := assignments(period).Count(&all).Error; err != nil {
+		return err
+	}
+	if err := assignments(period).Where("task_detail_id = ?", element.TaskDetailID).Count(&task).Error; err != nil {
+		return err
+	}
+	if err := assignments(month).Count(&inMonth).Error; err != nil {
+		return err
+	}
+
+	if exceedsCap(all, person.MaxAssignmentsPeriod) ||
+		exceedsCap(inMonth, person.MaxAssignmentsMonth) ||
+		exceedsCap(task, personTask.MaxAssignmentsPeriod) {
+		return errors.ErrAssignmentCapExceeded
+	}
+	return nil
+}
+
+// exceedsCap reports if one more assignment exceeds the cap, 0 is unlimited
+func exceedsCap(assignments int64, max uint) bool {
+	return max != 0 && assignments >= int64(max)
+}
+
+// monthOf returns the calendar month of date as period
+func monthOf(date time.Time) generalmodel.Period {
+	start := time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, date.Location())
+	return generalmodel.Period{StartDate: start, EndDate: start.AddDate(0, 1, 0).Add(-time.Nanosecond)}
+}
+
 // GetAllPersonAvailable loads all available people for a meeting with the specified task
 func GetAllPersonAvailable(db *gorm.DB, plan dbModel.Plan) (person apimodel.People, err error) {
-	person.Available, err = getAvailablePeople(plan, generalmodel.Period{
-		StartDate: time.Date(plan.Meeting.Date.Year(), plan.Meeting.Date.Month(), 1, 0, 0, 0, 0, time.Local),
-		EndDate:   time.Date(plan.Meeting.Date.Year(), plan.Meeting.Date.Month()+1, 0, 0, 0, 0, 0, time.Local),
-	}, db, false)
-
+	period := monthOf(plan.Meeting.Date)
+	person.Available, err = getAvailablePeople(plan, period, db, false, true)
+	if err != nil {
+		return apimodel.People{}, err
+	}
+	withoutRestRule, err := getAvailablePeople(plan, period, db, false, false)
 	if err != nil {
 		return apimodel.People{}, err
 	}
+
 	ids := []uint{plan.PersonID}
+	available := make(map[uint]bool)
 	for _, person := range person.Available {
 		ids = append(ids, person.ID)
+		available[person.ID] = true
+	}
+	for _, p := range withoutRestRule {
+		if !available[p.ID] {
+			ids = append(ids, p.ID)
+			person.RestViolation = append(person.RestViolation, p)
+		}
 	}
 
 	err =
@@ -194,19 +463,49 @@ func GetAllPersonAvailable(db *gorm.DB, plan dbModel.Plan) (person apimodel.Peop
 		}
 	}
 
+	var people []*dbModel.Person
+	if person.Assigned.ID != 0 {
+		people = append(people, &person.Assigned)
+	}
+	for _, list := range [][]dbModel.Person{person.Available, person.RestViolation, person.Absent} {
+		for i := range list {
+			people = append(people, &list[i])
+		}
+	}
+	dbModel.SetDisplayNames(people...)
 	return person, nil
 }
 
-// getFirstPersonAvailable loads the first available Person for a meeting with the specified task in the specified period
-func getFirstPersonAvailable(meeting dbModel.Meeting, taskDetail dbModel.TaskDetail, period generalmodel.Period, db *gorm.DB) (person *dbModel.Person, err error) {
-	people, err := getAvailablePeople(dbModel.Plan{TaskDetailID: taskDetail.ID, MeetingID: meeting.ID, Meeting: meeting}, period, db, true)
+// getFirstPersonAvailable loads the Person selected by strategy out of all available people for a meeting with the specified task in the specified period
+func getFirstPersonAvailable(meeting dbModel.Meeting, taskDetail dbModel.TaskDetail, period generalmodel.Period, db *gorm.DB, strategy AssignmentStrategy) (person *dbModel.Person, err error) {
+	plan := dbModel.Plan{TaskDetailID: taskDetail.ID, MeetingID: meeting.ID, Meeting: meeting}
+	people, err := getAvailablePeople(plan, period, db, true, true)
 	if err != nil || len(people) == 0 {
 		return nil, err
 	}
-	return &people[0], err
+	return strategy.Select(db, plan, period, people)
 }
 
-func getAvailablePeople(plan dbModel.Plan, period generalmodel.Period, db *gorm.DB, order bool) (person []dbModel.Person, err error) {
+// tasksInPeriod joins the number of assignments per person and task detail in a period as t_count.task_count.
+// Needs the people as p and their task details as td
+const tasksInPeriod = `LEFT JOIN (
+		SELECT person_id, task_detail_id, count(*) as task_count
+		FROM plans
+			WHERE meeting_id in (
+				SELECT id FROM meetings
+				WHERE date between ? and ?
+			)
+			GROUP BY person_id, task_detail_id
+		) t_count
+		ON p.id = t_count.person_id AND td.id = t_count.task_detail_id`
+
+// getAvailablePeople loads all people qualified for the task of plan, who are active and neither absent nor already assigned at the meeting.
+// If order is set, people with least entries in period are first, entries of the configured history count with decay
+// and preferences of people for the task or the weekday lower their rank.
+// The caps for a period are checked against the plan period of the meeting, or its month, as in UpdatePlanElement.
+// If restRule is set, people who would violate the minimum rest interval are excluded.
+// People who violate a relation are excluded, partners who are not absent count as possibly assigned
+func getAvailablePeople(plan dbModel.Plan, period generalmodel.Period, db *gorm.DB, order bool, restRule bool) (person []dbModel.Person, err error) {
 	timesInPeriod := `LEFT JOIN (
 		SELECT person_id, COUNT(*) as all_entries
 		FROM plans
@@ -216,26 +515,52 @@ func getAvailablePeople(plan dbModel.Plan, period generalmodel.Period, db *gorm.
 			) GROUP BY person_id
 		) plan_count
 		ON p.id = plan_count.person_id`
-	tasksInPeriod := `LEFT JOIN (
-		SELECT person_id, count(*) as task_count
+	monthInPeriod := `LEFT JOIN (
+		SELECT person_id, COUNT(*) as month_entries
 		FROM plans
 			WHERE meeting_id in (
 				SELECT id FROM meetings
 				WHERE date between ? and ?
-			)
-				AND task_detail_id = ?
-			GROUP BY person_id
-		) t_count
-		ON p.id = t_count.person_id`
+			) GROUP BY person_id
+		) month_count
+		ON p.id = month_count.person_id`
+	capInPeriod := `LEFT JOIN (
+		SELECT person_id, COUNT(*) as cap_entries
+		FROM plans
+			WHERE meeting_id in (
+				SELECT id FROM meetings
+				WHERE date between ? and ?
+			) GROUP BY person_id
+		) cap_count
+		ON p.id = cap_count.person_id`
+	taskCapInPeriod := `LEFT JOIN (
+		SELECT person_id, task_detail_id, COUNT(*) as cap_task_entries
+		FROM plans
+			WHERE meeting_id in (
+				SELECT id FROM meetings
+				WHERE date between ? and ?
+			) GROUP BY person_id, task_detail_id
+		) cap_task_count
+		ON p.id = cap_task_count.person_id AND td.id = cap_task_count.task_detail_id`
+	month := monthOf(plan.Meeting.Date)
+	capPeriod, err := periodOf(db, plan.Meeting.Date)
+	if err != nil {
+		return nil, err
+	}
 	peopleAssigned := db.Table("plans").
 		Select("COALESCE(person_id, -1)").
 		Where("meeting_id = ? AND person_id IS NOT NULL", plan.MeetingID)
 	peopleAbsent := db.Table("person_absences").
 		Select("COALESCE(person_id, -1)").
 		Where("meeting_id = ?", plan.MeetingID)
-	peopleRecuringAbsent := db.Table("person_recurring_absences").
+	peopleRecuringAbsent, err := recurringAbsentAt(db, plan.Meeting.Date)
+	if err != nil {
+		return nil, err
+	}
+	peopleAbsentPeriod := db.Table("person_absence_periods").
 		Select("COALESCE(person_id, -1)").
-		Where("weekday = ?", plan.Meeting.Date.Weekday())
+		Where("? BETWEEN from_date AND to_date", calendar.DayOf(plan.Meeting.Date))
+	peopleInactive := peopleInactiveAt(db, plan.Meeting.Date)
 
 	query := db.Table("people p").
 		// load task of person
@@ -243,12 +568,32 @@ func getAvailablePeople(plan dbModel.Plan, period generalmodel.Period, db *gorm.
 		// load allowed tasks
 		Joins("JOIN task_details td ON td.id = pt.task_detail_id").
 		Joins(timesInPeriod, period.StartDate, period.EndDate).
-		Joins(tasksInPeriod, period.StartDate, period.EndDate, plan.TaskDetailID).
+		Joins(tasksInPeriod, period.StartDate, period.EndDate).
+		Joins(monthInPeriod, month.StartDate, month.EndDate).
+		Joins(capInPeriod, capPeriod.StartDate, capPeriod.EndDate).
+		Joins(taskCapInPeriod, capPeriod.StartDate, capPeriod.EndDate).
 		// filter task
 		Where("td.id = ?", plan.TaskDetailID).
+		// filter people who reached their maximum number of assignments
+		Where("(p.max_assignments_period = 0 OR COALESCE(cap_count.cap_entries, 0) < p.max_assignments_period)").
+		Where("(pt.max_assignments_period = 0 OR COALESCE(cap_task_count.cap_task_entries, 0) < pt.max_assignments_period)").
+		Where("(p.max_assignments_month = 0 OR COALESCE(month_count.month_entries, 0) < p.max_assignments_month)").
 		Not("p.id IN (?)", peopleAssigned).
 		Not("p.id IN (?)", peopleAbsent).
-		Not("p.id IN (?)", peopleRecuringAbsent)
+		Not("p.id IN (?)", peopleAbsentPeriod).
+		Not("p.id IN (?)", peopleInactive)
+	if len(peopleRecuringAbsent) > 0 {
+		query = query.Not("p.id IN (?)", peopleRecuringAbsent)
+	}
+	if restRule {
+		resting, err := peopleResting(db, plan)
+		if err != nil {
+			return nil, err
+		}
+		if resting != nil {
+			query = query.Not("p.id IN (?)", resting)
+		}
+	}
 	if order {
 		query = query.
 			// Least entries in period first
@@ -262,6 +607,11 @@ func getAvailablePeople(plan dbModel.Plan, period generalmodel.Period, db *gorm.
 		}
 		return nil, err
 	}
+	if order {
+		if err := rankCandidates(db, plan, period, person); err != nil {
+			return nil, err
+		}
+	}
 
-	return person, nil
+	return filterRelations(db, plan, person, false)
 }
@@ -4,9 +4,12 @@ import (
 	"fmt"
 	"mpt_data/database"
 	"mpt_data/helper/config"
+	dbModel "mpt_data/models/dbmodel"
+	generalmodel "mpt_data/models/general"
 	"mpt_data/test/vars"
 	"os"
 	"testing"
+	"time"
 )
 
 func TestMain(m *testing.M) {
@@ -34,3 +37,91 @@ func TestCreatePlan(t *testing.T) {
 		})
 	}
 }
+
+func TestPreviewPlanData(t *testing.T) {
+	// Prepare
+	db := database.DB.Begin()
+	t.Cleanup(func() {
+		db.Rollback()
+	})
+	task := dbModel.Task{Descr: "PreviewTask"}
+	if err := db.Create(&task).Error; err != nil {
+		t.Fatalf("test preparation failed: %v", err)
+	}
+	details := []dbModel.TaskDetail{
+		{Descr: "PreviewFirst", TaskID: task.ID},
+		{Descr: "PreviewSecond", TaskID: task.ID},
+	}
+	people := []dbModel.Person{
+		{GivenName: "Anna", LastName: "Preview"},
+		{GivenName: "Ben", LastName: "Preview"},
+		{GivenName: "Cleo", LastName: "Preview"},
+	}
+	meeting := dbModel.Meeting{Date: time.Date(2013, 5, 5, 10, 0, 0, 0, time.UTC)}
+	period := generalmodel.Period{
+		StartDate: time.Date(2013, 5, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2013, 5, 31, 0, 0, 0, 0, time.UTC),
+	}
+	pdf := dbModel.PDF{Name: "preview.pdf", Period: period}
+	for _, value := range []interface{}{&details, &people, &meeting, &pdf} {
+		if err := db.Create(value).Error; err != nil {
+			t.Fatalf("test preparation failed: %v", err)
+		}
+	}
+	// Anna is assigned first, but removed again, because Ben is never assigned
+	personTasks := []dbModel.PersonTask{
+		{PersonID: people[0].ID, TaskDetailID: details[0].ID},
+		{PersonID: people[2].ID, TaskDetailID: details[1].ID},
+	}
+	relation := dbModel.PersonRelation{PersonID: people[0].ID, RelatedPersonID: people[1].ID, Type: dbModel.RelationRequires}
+	for _, value := range []interface{}{&personTasks, &relation} {
+		if err := db.Create(value).Error; err != nil {
+			t.Fatalf("test preparation failed: %v", err)
+		}
+	}
+
+	type state struct {
+		plans, revisions int64
+		dataChanged      bool
+	}
+	load := func() (current state) {
+		db.Model(&dbModel.Plan{}).Count(&current.plans)
+		db.Model(&dbModel.PlanRevision{}).Count(&current.revisions)
+		db.Model(&dbModel.PDF{}).Where("id = ?", pdf.ID).Select("data_changed").Scan(&current.dataChanged)
+		return current
+	}
+	before := load()
+
+	t.Run("create changes the plan", func(t *testing.T) {
+		db.SavePoint("beforePlan")
+		defer db.RollbackTo("beforePlan")
+		// Act
+		if _, err := CreatePlanData(db, period, leastLoaded{}, false, nil); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		// Assert
+		after := load()
+		if after.plans == before.plans || after.revisions == before.revisions || !after.dataChanged {
+			t.Errorf("expected plans, revisions and PDF changed, got %+v before and %+v after", before, after)
+		}
+	})
+
+	t.Run("preview", func(t *testing.T) {
+		// Act
+		result, err := PreviewPlanData(db, period, leastLoaded{}, nil)
+		// Assert
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if after := load(); after != before {
+			t.Errorf("expected nothing changed, got %+v before and %+v after", before, after)
+		}
+		assigned := make(map[uint]uint)
+		for _, element := range result.Plan {
+			assigned[element.TaskDetailID] = element.PersonID
+		}
+		if assigned[details[0].ID] != 0 || assigned[details[1].ID] != people[2].ID {
+			t.Errorf("expected nobody for %s and Cleo for %s, got %v", details[0].Descr, details[1].Descr, assigned)
+		}
+	})
+}
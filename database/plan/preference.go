@@ -0,0 +1,89 @@
+package plan
+
+import (
+	"math"
+	"mpt_data/helper/config"
+	dbModel "mpt_data/models/dbmodel"
+	generalmodel "mpt_data/models/general"
+	"sort"
+
+	"gorm.io/gorm"
+)
+
+// loadPreferences loads the preferences of the given people, grouped by person
+func loadPreferences(db *gorm.DB, personIDs []uint) (map[uint][]dbModel.PersonPreference, error) {
+	preferences := make(map[uint][]dbModel.PersonPreference)
+	if len(personIDs) == 0 {
+		return preferences, nil
+	}
+	var all []dbModel.PersonPreference
+	if err := db.Where("person_id IN (?)", personIDs).Find(&all).Error; err != nil {
+		return nil, err
+	}
+	for _, preference := range all {
+		preferences[preference.PersonID] = append(preferences[preference.PersonID], preference)
+	}
+	return preferences, nil
+}
+
+// preferenceScore sums the scores of all preferences, which apply to the TaskDetail or the weekday of the meeting of plan.
+// The result is between -2 and 2
+func preferenceScore(preferences []dbModel.PersonPreference, plan dbModel.Plan) int {
+	score := 0
+	for _, preference := range preferences {
+		if preference.Applies(plan.TaskDetailID, plan.Meeting.Date.Weekday()) {
+			score += preference.Score()
+		}
+	}
+	return score
+}
+
+// preferenceWeight returns the configured number of assignments a preference outweighs
+func preferenceWeight() float64 {
+	return math.Max(0, config.Config.Plan.PreferenceWeight)
+}
+
+// preferenceCost converts a score to a cost of the flow graph, which is lower for preferred slots.
+// One more assignment costs 2*loadScale, so a preference outweighs the configured number of assignments.
+// Without a configured weight the cost only decides between people with the same load
+func preferenceCost(score int) int {
+	return -score * (int(math.Round(2*loadScale*preferenceWeight())) + 1)
+}
+
+// rankCandidates orders people by their assignment load including the history, least first.
+// Preferences for the task or the weekday of plan lower the load by the configured weight
+// and decide between people with the same load. The order of people with the same rank is kept
+func rankCandidates(db *gorm.DB, plan dbModel.Plan, period generalmodel.Period, people []dbModel.Person) error {
+	if len(people) < 2 {
+		return nil
+	}
+	ids := make([]uint, 0, len(people))
+	for _, person := range people {
+		ids = append(ids, person.ID)
+	}
+	load, err := assignmentLoad(db, period, ids)
+	if err != nil {
+		return err
+	}
+	preferences, err := loadPreferences(db, ids)
+	if err != nil {
+		return err
+	}
+
+	weight := preferenceWeight()
+	score := make(map[uint]int, len(people))
+	rank := make(map[uint]float64, len(people))
+	for _, person := range people {
+		score[person.ID] = preferenceScore(preferences[person.ID], plan)
+		rank[person.ID] = load[person.ID] - weight*float64(score[person.ID])
+	}
+
+	sort.SliceStable(people, func(i, j int) bool {
+		a, b := people[i].ID, people[j].ID
+		if rank[a] != rank[b] {
+			return rank[a] < rank[b]
+		}
+		return score[a] > score[b]
+	})
+	return nil
+}
@@ -0,0 +1,124 @@
+package plan
+
+import (
+	"mpt_data/database"
+	"mpt_data/helper/config"
+	dbModel "mpt_data/models/dbmodel"
+	generalmodel "mpt_data/models/general"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPreferenceScore(t *testing.T) {
+	taskDetailID, otherTaskDetailID := uint(1), uint(2)
+	sunday, monday := int(time.Sunday), int(time.Monday)
+	plan := dbModel.Plan{TaskDetailID: taskDetailID, Meeting: dbModel.Meeting{Date: time.Date(2024, 1, 7, 0, 0, 0, 0, time.UTC)}}
+	var testcases = []struct {
+		name        string
+		preferences []dbModel.PersonPreference
+		expected    int
+	}{
+		{"no preferences", nil, 0},
+		{"prefers task", []dbModel.PersonPreference{{TaskDetailID: &taskDetailID, Weight: dbModel.PreferencePrefers}}, 1},
+		{"other task", []dbModel.PersonPreference{{TaskDetailID: &otherTaskDetailID, Weight: dbModel.PreferencePrefers}}, 0},
+		{"dislikes weekday", []dbModel.PersonPreference{{Weekday: &sunday, Weight: dbModel.PreferenceDislikes}}, -1},
+		{"other weekday", []dbModel.PersonPreference{{Weekday: &monday, Weight: dbModel.PreferenceDislikes}}, 0},
+		{
+			"task and weekday",
+			[]dbModel.PersonPreference{
+				{TaskDetailID: &taskDetailID, Weight: dbModel.PreferencePrefers},
+				{Weekday: &sunday, Weight: dbModel.PreferencePrefers},
+			},
+			2,
+		},
+		{"neutral", []dbModel.PersonPreference{{TaskDetailID: &taskDetailID, Weight: dbModel.PreferenceNeutral}}, 0},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			// Act
+			score := preferenceScore(testcase.preferences, plan)
+			// Assert
+			if score != testcase.expected {
+				t.Errorf("expected %d, got %d", testcase.expected, score)
+			}
+		})
+	}
+}
+
+func TestRankCandidates(t *testing.T) {
+	// Prepare
+	db := database.DB.Begin()
+	weight := config.Config.Plan.PreferenceWeight
+	history := config.Config.Plan.History
+	t.Cleanup(func() {
+		db.Rollback()
+		config.Config.Plan.PreferenceWeight = weight
+		config.Config.Plan.History = history
+	})
+	config.Config.Plan.History.Months = 0
+	task := dbModel.Task{Descr: "RankTask"}
+	if err := db.Create(&task).Error; err != nil {
+		t.Fatalf("test preparation failed: %v", err)
+	}
+	detail := dbModel.TaskDetail{Descr: "RankDetail", TaskID: task.ID}
+	people := []dbModel.Person{
+		{GivenName: "Anna", LastName: "Rank"},
+		{GivenName: "Ben", LastName: "Rank"},
+		{GivenName: "Cleo", LastName: "Rank"},
+	}
+	meetings := []dbModel.Meeting{
+		{Date: time.Date(2006, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{Date: time.Date(2006, 1, 8, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, value := range []interface{}{&detail, &people, &meetings} {
+		if err := db.Create(value).Error; err != nil {
+			t.Fatalf("test preparation failed: %v", err)
+		}
+	}
+	anna, ben, cleo := people[0].ID, people[1].ID, people[2].ID
+	sunday := int(time.Sunday)
+	for _, value := range []interface{}{
+		// Anna has one assignment more than the others
+		&dbModel.Plan{PersonID: anna, MeetingID: meetings[0].ID, TaskDetailID: detail.ID},
+		&dbModel.PersonPreference{PersonID: anna, TaskDetailID: &detail.ID, Weight: dbModel.PreferencePrefers},
+		&dbModel.PersonPreference{PersonID: anna, Weekday: &sunday, Weight: dbModel.PreferencePrefers},
+		&dbModel.PersonPreference{PersonID: ben, TaskDetailID: &detail.ID, Weight: dbModel.PreferenceDislikes},
+	} {
+		if err := db.Create(value).Error; err != nil {
+			t.Fatalf("test preparation failed: %v", err)
+		}
+	}
+	plan := dbModel.Plan{MeetingID: meetings[1].ID, TaskDetailID: detail.ID, Meeting: meetings[1]}
+	period := generalmodel.Period{StartDate: meetings[0].Date, EndDate: meetings[1].Date}
+
+	var testcases = []struct {
+		name     string
+		weight   float64
+		expected []uint
+	}{
+		{"preference only decides equal load", 0, []uint{cleo, ben, anna}},
+		{"preference outweighs assignments", 1, []uint{anna, cleo, ben}},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			config.Config.Plan.PreferenceWeight = testcase.weight
+			candidates := []dbModel.Person{people[0], people[1], people[2]}
+			// Act
+			err := rankCandidates(db, plan, period, candidates)
+			// Assert
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			ranked := make([]uint, 0, len(candidates))
+			for _, candidate := range candidates {
+				ranked = append(ranked, candidate.ID)
+			}
+			if !reflect.DeepEqual(ranked, testcase.expected) {
+				t.Errorf("expected %v, got %v", testcase.expected, ranked)
+			}
+		})
+	}
+}
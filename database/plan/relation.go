@@ -0,0 +1,264 @@
+package plan
+
+import (
+	"mpt_data/helper/calendar"
+	"mpt_data/helper/errors"
+	dbModel "mpt_data/models/dbmodel"
+	generalmodel "mpt_data/models/general"
+
+	"gorm.io/gorm"
+)
+
+// loadRelations loads all relations, where one of the people is on either side
+func loadRelations(db *gorm.DB, personIDs []uint) (relations []dbModel.PersonRelation, err error) {
+	if len(personIDs) == 0 {
+		return nil, nil
+	}
+	err =
+		db.Where("person_id IN (?)", personIDs).
+			Or("related_person_id IN (?)", personIDs).
+			Find(&relations).Error
+	return relations, err
+}
+
+// violatesRelation reports if personID may not serve at a meeting, where the people in assigned serve.
+// together and requires are not violated, as long as pending reports that the partner may still be assigned to the meeting.
+// pending may be nil, if nobody will be assigned anymore
+func violatesRelation(personID uint, relations []dbModel.PersonRelation, assigned map[uint]bool, pending func(personID uint) bool) bool {
+	for _, relation := range relations {
+		partner, ok := relation.Partner(personID)
+		if !ok {
+			continue
+		}
+		switch relation.Type {
+		case dbModel.RelationApart:
+			if assigned[partner] {
+				return true
+			}
+		case dbModel.RelationTogether, dbModel.RelationRequires:
+			if !assigned[partner] && (pending == nil || !pending(partner)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// peopleAssignedAt loads the people assigned to the meeting of plan, without the person of plan itself
+func peopleAssignedAt(db *gorm.DB, plan dbModel.Plan) (map[uint]bool, error) {
+	var ids []uint
+	if err :=
+		db.Table("plans").
+			Where("meeting_id = ?", plan.MeetingID).
+			Where("person_id <> 0").
+			Where("id <> ?", plan.ID).
+			Pluck("person_id", &ids).Error; err != nil {
+		return nil, err
+	}
+	assigned := make(map[uint]bool, len(ids))
+	for _, id := range ids {
+		assigned[id] = true
+	}
+	return assigned, nil
+}
+
+// peopleAbsentAt loads the people absent at the meeting of plan
+func peopleAbsentAt(db *gorm.DB, plan dbModel.Plan) (map[uint]bool, error) {
+	var ids, periods []uint
+	if err := db.Table("person_absences").Where("meeting_id = ?", plan.MeetingID).Pluck("person_id", &ids).Error; err != nil {
+		return nil, err
+	}
+	if err :=
+		db.Table("person_absence_periods").
+			Where("? BETWEEN from_date AND to_date", calendar.DayOf(plan.Meeting.Date)).
+			Pluck("person_id", &periods).Error; err != nil {
+		return nil, err
+	}
+	recurring, err := recurringAbsentAt(db, plan.Meeting.Date)
+	if err != nil {
+		return nil, err
+	}
+	absent := make(map[uint]bool, len(ids)+len(periods)+len(recurring))
+	for _, id := range append(append(ids, periods...), recurring...) {
+		absent[id] = true
+	}
+	return absent, nil
+}
+
+// filterRelations removes all people, who violate a relation at the meeting of plan.
+// If strict is not set, partners who are not absent may still be assigned and
+// people whose together partner is already assigned are ranked before all others
+func filterRelations(db *gorm.DB, plan dbModel.Plan, people []dbModel.Person, strict bool) ([]dbModel.Person, error) {
+	ids := make([]uint, 0, len(people))
+	for _, person := range people {
+		ids = append(ids, person.ID)
+	}
+	relations, err := loadRelations(db, ids)
+	if err != nil || len(relations) == 0 {
+		return people, err
+	}
+
+	assigned, err := peopleAssignedAt(db, plan)
+	if err != nil {
+		return nil, err
+	}
+	var pending func(uint) bool
+	if !strict {
+		absent, err := peopleAbsentAt(db, plan)
+		if err != nil {
+			return nil, err
+		}
+		pending = func(personID uint) bool { return !absent[personID] }
+	}
+
+	var partnerAssigned, others []dbModel.Person
+	for _, person := range people {
+		if violatesRelation(person.ID, relations, assigned, pending) {
+			continue
+		}
+		if !strict && togetherWithAssigned(person.ID, relations, assigned) {
+			partnerAssigned = append(partnerAssigned, person)
+		} else {
+			others = append(others, person)
+		}
+	}
+	return append(partnerAssigned, others...), nil
+}
+
+// togetherWithAssigned reports if personID has a together relation with one of the assigned people
+func togetherWithAssigned(personID uint, relations []dbModel.PersonRelation, assigned map[uint]bool) bool {
+	for _, relation := range relations {
+		if partner, ok := relation.Partner(personID); ok && relation.Type == dbModel.RelationTogether && assigned[partner] {
+			return true
+		}
+	}
+	return false
+}
+
+// checkRelations checks if the person of element may serve at the meeting of element with the people assigned to it,
+// and if the people serving with the replaced person still keep their relations without the replaced person
+func checkRelations(db *gorm.DB, element dbModel.Plan) error {
+	var stored dbModel.Plan
+	if err := db.First(&stored, element.ID).Error; err != nil {
+		return err
+	}
+	assigned, err := peopleAssignedAt(db, stored)
+	if err != nil {
+		return err
+	}
+
+	relations, err := loadRelations(db, []uint{element.PersonID})
+	if err != nil {
+		return err
+	}
+	if violatesRelation(element.PersonID, relations, assigned, nil) {
+		return errors.ErrRelationViolated
+	}
+
+	replaced := stored.PersonID
+	if replaced == 0 || replaced == element.PersonID || assigned[replaced] {
+		return nil
+	}
+	after := make(map[uint]bool, len(assigned)+1)
+	for personID := range assigned {
+		after[personID] = true
+	}
+	after[element.PersonID] = true
+
+	replacedRelations, err := loadRelations(db, []uint{replaced})
+	if err != nil {
+		return err
+	}
+	for _, relation := range replacedRelations {
+		partner, ok := relation.Partner(replaced)
+		if !ok {
+			// the replaced person is required by the person of the relation
+			partner = relation.PersonID
+		}
+		// apart is never violated by removing a person, requires only for the person requiring the replaced one
+		if !after[partner] || relation.Type == dbModel.RelationApart ||
+			(relation.Type == dbModel.RelationRequires && relation.PersonID == replaced) {
+			continue
+		}
+		partnerRelations, err := loadRelations(db, []uint{partner})
+		if err != nil {
+			return err
+		}
+		if violatesRelation(partner, partnerRelations, after, nil) {
+			return errors.ErrRelationViolated
+		}
+	}
+	return nil
+}
+
+// enforceRelations removes all assignments of the plan elements at meeting, that violate a relation.
+// Afterwards the empty elements are assigned again, only people fulfilling all relations are selected.
+// Every change is recorded as PlanRevision with the acting user
+func enforceRelations(db *gorm.DB, meeting dbModel.Meeting, planIDs []uint, period generalmodel.Period, strategy AssignmentStrategy, userID *uint) error {
+	if len(planIDs) == 0 {
+		return nil
+	}
+	assigned, err := peopleAssignedAt(db, dbModel.Plan{MeetingID: meeting.ID})
+	if err != nil {
+		return err
+	}
+	ids := make([]uint, 0, len(assigned))
+	for id := range assigned {
+		ids = append(ids, id)
+	}
+	relations, err := loadRelations(db, ids)
+	if err != nil || len(relations) == 0 {
+		return err
+	}
+
+	var plans []dbModel.Plan
+	if err := db.Where("id IN (?)", planIDs).Find(&plans).Error; err != nil {
+		return err
+	}
+
+	for removed := true; removed; {
+		removed = false
+		for i := range plans {
+			if plans[i].PersonID == 0 || !violatesRelation(plans[i].PersonID, relations, assigned, nil) {
+				continue
+			}
+			if err := db.Table("plans").Where("id = ?", plans[i].ID).Update("person_id", 0).Error; err != nil {
+				return err
+			}
+			if err := recordRevision(db, plans[i].ID, plans[i].PersonID, 0, userID, RevisionRelation); err != nil {
+				return err
+			}
+			delete(assigned, plans[i].PersonID)
+			plans[i].PersonID = 0
+			removed = true
+		}
+	}
+
+	for _, plan := range plans {
+		if plan.PersonID != 0 {
+			continue
+		}
+		plan.Meeting = meeting
+		people, err := getAvailablePeople(plan, period, db, true, true)
+		if err != nil {
+			return err
+		}
+		if people, err = filterRelations(db, plan, people, true); err != nil {
+			return err
+		}
+		person, err := strategy.Select(db, plan, period, people)
+		if err != nil {
+			return err
+		}
+		if person == nil {
+			continue
+		}
+		if err := db.Table("plans").Where("id = ?", plan.ID).Update("person_id", person.ID).Error; err != nil {
+			return err
+		}
+		if err := recordRevision(db, plan.ID, 0, person.ID, userID, RevisionRelation); err != nil {
+			return err
+		}
+	}
+	return nil
+}
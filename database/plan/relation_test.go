@@ -0,0 +1,141 @@
+package plan
+
+import (
+	"mpt_data/database"
+	"mpt_data/helper/errors"
+	dbModel "mpt_data/models/dbmodel"
+	"testing"
+	"time"
+)
+
+func TestViolatesRelation(t *testing.T) {
+	relations := []dbModel.PersonRelation{
+		{PersonID: 1, RelatedPersonID: 2, Type: dbModel.RelationTogether},
+		{PersonID: 3, RelatedPersonID: 4, Type: dbModel.RelationApart},
+		{PersonID: 5, RelatedPersonID: 6, Type: dbModel.RelationRequires},
+	}
+	nobody := func(uint) bool { return false }
+	everybody := func(uint) bool { return true }
+	var testcases = []struct {
+		name     string
+		personID uint
+		assigned map[uint]bool
+		pending  func(uint) bool
+		expected bool
+	}{
+		{"no relation", 7, map[uint]bool{}, nil, false},
+		{"together partner assigned", 1, map[uint]bool{2: true}, nil, false},
+		{"together reverse partner assigned", 2, map[uint]bool{1: true}, nil, false},
+		{"together partner missing", 1, map[uint]bool{}, nil, true},
+		{"together partner pending", 2, map[uint]bool{}, everybody, false},
+		{"together partner not pending", 2, map[uint]bool{}, nobody, true},
+		{"apart partner assigned", 3, map[uint]bool{4: true}, everybody, true},
+		{"apart reverse partner assigned", 4, map[uint]bool{3: true}, nil, true},
+		{"apart partner missing", 4, map[uint]bool{}, nil, false},
+		{"requires related assigned", 5, map[uint]bool{6: true}, nil, false},
+		{"requires related missing", 5, map[uint]bool{}, nil, true},
+		{"requires related pending", 5, map[uint]bool{}, everybody, false},
+		{"requires only one direction", 6, map[uint]bool{}, nil, false},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			// Act
+			result := violatesRelation(testcase.personID, relations, testcase.assigned, testcase.pending)
+			// Assert
+			if result != testcase.expected {
+				t.Errorf("expected %t, got %t", testcase.expected, result)
+			}
+		})
+	}
+}
+
+func TestCheckRelations(t *testing.T) {
+	// Prepare
+	db := database.DB.Begin()
+	t.Cleanup(func() {
+		db.Rollback()
+	})
+	task := dbModel.Task{Descr: "CheckRelationTask"}
+	if err := db.Create(&task).Error; err != nil {
+		t.Fatalf("test preparation failed: %v", err)
+	}
+	detail := dbModel.TaskDetail{Descr: "CheckRelationDetail", TaskID: task.ID, Headcount: 2}
+	meeting := dbModel.Meeting{Date: time.Date(2006, 10, 1, 10, 0, 0, 0, time.UTC)}
+	people := []dbModel.Person{
+		{GivenName: "Anna", LastName: "Together"},
+		{GivenName: "Ben", LastName: "Together"},
+		{GivenName: "Clara", LastName: "Requires"},
+		{GivenName: "David", LastName: "Required"},
+		{GivenName: "Eva", LastName: "Single"},
+	}
+	for _, value := range []interface{}{&detail, &meeting, &people} {
+		if err := db.Create(value).Error; err != nil {
+			t.Fatalf("test preparation failed: %v", err)
+		}
+	}
+	anna, ben, clara, david, eva := people[0].ID, people[1].ID, people[2].ID, people[3].ID, people[4].ID
+	relations := []dbModel.PersonRelation{
+		{PersonID: anna, RelatedPersonID: ben, Type: dbModel.RelationTogether},
+		{PersonID: clara, RelatedPersonID: david, Type: dbModel.RelationRequires},
+	}
+	if err := db.Create(&relations).Error; err != nil {
+		t.Fatalf("test preparation failed: %v", err)
+	}
+	plans := []dbModel.Plan{
+		{MeetingID: meeting.ID, TaskDetailID: detail.ID, Slot: 0},
+		{MeetingID: meeting.ID, TaskDetailID: detail.ID, Slot: 1},
+	}
+	if err := db.Create(&plans).Error; err != nil {
+		t.Fatalf("test preparation failed: %v", err)
+	}
+
+	var testcases = []struct {
+		name     string
+		assigned []uint
+		element  dbModel.Plan
+		err      error
+	}{
+		{"together partner missing", []uint{0, 0}, dbModel.Plan{ID: plans[0].ID, PersonID: anna}, errors.ErrRelationViolated},
+		{"together partner assigned", []uint{0, ben}, dbModel.Plan{ID: plans[0].ID, PersonID: anna}, nil},
+		{"requires partner missing", []uint{0, ben}, dbModel.Plan{ID: plans[0].ID, PersonID: clara}, errors.ErrRelationViolated},
+		{"requires partner assigned", []uint{0, david}, dbModel.Plan{ID: plans[0].ID, PersonID: clara}, nil},
+		{"replaced together partner", []uint{anna, ben}, dbModel.Plan{ID: plans[1].ID, PersonID: eva}, errors.ErrRelationViolated},
+		{"replaced required person", []uint{clara, david}, dbModel.Plan{ID: plans[1].ID, PersonID: eva}, errors.ErrRelationViolated},
+		{"replaced person requiring", []uint{clara, david}, dbModel.Plan{ID: plans[0].ID, PersonID: eva}, nil},
+	}
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			db.SavePoint("beforeCheck")
+			defer db.RollbackTo("beforeCheck")
+			for i, personID := range testcase.assigned {
+				if err := db.Table("plans").Where("id = ?", plans[i].ID).Update("person_id", personID).Error; err != nil {
+					t.Fatalf("test preparation failed: %v", err)
+				}
+			}
+			// Act
+			err := checkRelations(db, testcase.element)
+			// Assert
+			if err != testcase.err {
+				t.Errorf("expected %v, got %v", testcase.err, err)
+			}
+		})
+	}
+
+	t.Run("rank together partner", func(t *testing.T) {
+		db.SavePoint("beforeRank")
+		defer db.RollbackTo("beforeRank")
+		if err := db.Table("plans").Where("id = ?", plans[1].ID).Update("person_id", ben).Error; err != nil {
+			t.Fatalf("test preparation failed: %v", err)
+		}
+		// Act
+		ranked, err := filterRelations(db, dbModel.Plan{ID: plans[0].ID, MeetingID: meeting.ID, Meeting: meeting}, []dbModel.Person{people[4], people[0]}, false)
+		// Assert
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(ranked) != 2 || ranked[0].ID != anna || ranked[1].ID != eva {
+			t.Errorf("expected anna ranked before eva, got %v", ranked)
+		}
+	})
+}
@@ -0,0 +1,81 @@
+package plan
+
+import (
+	"mpt_data/helper/config"
+	"mpt_data/helper/errors"
+	dbModel "mpt_data/models/dbmodel"
+
+	"gorm.io/gorm"
+)
+
+// restInterval returns the minimum number of days and meetings between two assignments of a person for taskDetail.
+// Values set for taskDetail override the values from config
+func restInterval(taskDetail dbModel.TaskDetail) (days, meetings uint) {
+	days, meetings = config.Config.Plan.MinRestDays, config.Config.Plan.MinRestMeetings
+	if taskDetail.MinRestDays != nil {
+		days = *taskDetail.MinRestDays
+	}
+	if taskDetail.MinRestMeetings != nil {
+		meetings = *taskDetail.MinRestMeetings
+	}
+	return days, meetings
+}
+
+// peopleResting builds a query for all people, who have an assignment too close to the meeting of plan.
+// Returns nil if no minimum rest interval applies
+func peopleResting(db *gorm.DB, plan dbModel.Plan) (*gorm.DB, error) {
+	var taskDetail dbModel.TaskDetail
+	if err := db.First(&taskDetail, plan.TaskDetailID).Error; err != nil {
+		return nil, err
+	}
+
+	days, meetings := restInterval(taskDetail)
+	if days == 0 && meetings == 0 {
+		return nil, nil
+	}
+
+	date := plan.Meeting.Date
+	var closeMeetings []*gorm.DB
+	if days != 0 {
+		closeMeetings = append(closeMeetings,
+			db.Table("meetings").
+				Where("date > ? AND date < ?", date.AddDate(0, 0, -int(days)), date.AddDate(0, 0, int(days))).
+				Select("id"))
+	}
+	if meetings != 0 {
+		closeMeetings = append(closeMeetings,
+			db.Table("meetings").Where("date < ?", date).Order("date desc").Limit(int(meetings)).Select("id"),
+			db.Table("meetings").Where("date > ?", date).Order("date asc").Limit(int(meetings)).Select("id"))
+	}
+
+	condition := db.Where("meeting_id IN (?)", closeMeetings[0])
+	for _, closeMeeting := range closeMeetings[1:] {
+		condition = condition.Or("meeting_id IN (?)", closeMeeting)
+	}
+
+	return db.Table("plans").
+		Select("COALESCE(person_id, -1)").
+		Where("meeting_id <> ?", plan.MeetingID).
+		Where(condition), nil
+}
+
+// checkRest returns ErrRestViolated, if the person of element has an assignment too close to the meeting of element.
+// The meeting of element must be set, the plan elements released are not counted
+func checkRest(db *gorm.DB, element dbModel.Plan, released []uint) error {
+	resting, err := peopleResting(db, element)
+	if err != nil || resting == nil {
+		return err
+	}
+	var count int64
+	if err :=
+		resting.
+			Where("person_id = ?", element.PersonID).
+			Where("id NOT IN (?)", append([]uint{element.ID}, released...)).
+			Count(&count).Error; err != nil {
+		return err
+	}
+	if count != 0 {
+		return errors.ErrRestViolated
+	}
+	return nil
+}
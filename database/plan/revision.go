@@ -0,0 +1,89 @@
+package plan
+
+import (
+	dbModel "mpt_data/models/dbmodel"
+	generalmodel "mpt_data/models/general"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// GetPlanHistory loads all changes of a plan element, oldest first
+func GetPlanHistory(db *gorm.DB, planID uint) (revisions []dbModel.PlanRevision, err error) {
+	if err :=
+		preloadRevision(db).
+			Where("plan_id = ?", planID).
+			Order("changed_at").
+			Order("id").
+			Find(&revisions).Error; err != nil {
+		return nil, err
+	}
+	return revisions, nil
+}
+
+// GetPlanChanges loads all changes of plan elements since the given time, oldest first
+func GetPlanChanges(db *gorm.DB, since time.Time) (revisions []dbModel.PlanRevision, err error) {
+	if err :=
+		preloadRevision(db).
+			Where("changed_at >= ?", since.UTC()).
+			Order("changed_at").
+			Order("id").
+			Find(&revisions).Error; err != nil {
+		return nil, err
+	}
+	return revisions, nil
+}
+
+// LastPublication returns the time a plan period was published the last time.
+// Returns the zero time, if no plan period was published
+func LastPublication(db *gorm.DB) (time.Time, error) {
+	var period dbModel.PlanPeriod
+	err :=
+		db.Where("published_at IS NOT NULL").
+			Order("published_at desc").
+			First(&period).Error
+	if err == gorm.ErrRecordNotFound {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	return *period.PublishedAt, nil
+}
+
+func preloadRevision(db *gorm.DB) *gorm.DB {
+	return db.
+		Preload("Plan.Meeting").
+		Preload("Plan.TaskDetail").
+		Preload("OldPerson").
+		Preload("NewPerson")
+}
+
+// Reasons of revisions by the planning itself
+const (
+	// RevisionFill an empty plan element got a person
+	RevisionFill = "plan filled"
+	// RevisionRelation the person of a plan element changed to fulfill the relations of the meeting
+	RevisionRelation = "relation enforced"
+)
+
+// recordRevision stores the change of the person of a plan element, nothing is stored if the person is unchanged
+func recordRevision(db *gorm.DB, planID, oldPersonID, newPersonID uint, userID *uint, reason string) error {
+	if oldPersonID == newPersonID {
+		return nil
+	}
+	revision := dbModel.PlanRevision{
+		PlanID:      planID,
+		OldPersonID: oldPersonID,
+		NewPersonID: newPersonID,
+		UserID:      userID,
+		ChangedAt:   time.Now().UTC(),
+		Reason:      reason,
+	}
+	if err := db.Create(&revision).Error; err != nil {
+		zap.L().Error(generalmodel.DBSaveDataFailed, zap.Error(err))
+		return err
+	}
+	return nil
+}
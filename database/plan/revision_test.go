@@ -0,0 +1,133 @@
+package plan
+
+import (
+	"mpt_data/database"
+	dbModel "mpt_data/models/dbmodel"
+	generalmodel "mpt_data/models/general"
+	"testing"
+	"time"
+)
+
+func TestPlanRevision(t *testing.T) {
+	// Prepare
+	db := database.DB.Begin()
+	t.Cleanup(func() {
+		db.Rollback()
+	})
+	people := []dbModel.Person{
+		{GivenName: "Anna", LastName: "Revision"},
+		{GivenName: "Ben", LastName: "Revision"},
+	}
+	meeting := dbModel.Meeting{Date: time.Date(2006, 1, 1, 0, 0, 0, 0, time.UTC)}
+	if err := db.Create(&people).Error; err != nil {
+		t.Fatalf("test preparation failed: %v", err)
+	}
+	if err := db.Create(&meeting).Error; err != nil {
+		t.Fatalf("test preparation failed: %v", err)
+	}
+	element := dbModel.Plan{PersonID: people[0].ID, MeetingID: meeting.ID}
+	if err := db.Create(&element).Error; err != nil {
+		t.Fatalf("test preparation failed: %v", err)
+	}
+	period := dbModel.PlanPeriod{Period: generalmodel.Period{StartDate: meeting.Date, EndDate: meeting.Date}}
+	if err := AddPlanPeriod(db, &period); err != nil {
+		t.Fatalf("test preparation failed: %v", err)
+	}
+	userID := uint(1)
+
+	// Act
+	if err := recordRevision(db, element.ID, people[0].ID, people[1].ID, &userID, "sick"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := recordRevision(db, element.ID, people[1].ID, people[1].ID, nil, "unchanged"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if _, err := UpdatePlanPeriodState(db, period.ID, dbModel.PlanPeriodPublished); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	published, err := LastPublication(db)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := recordRevision(db, element.ID, people[1].ID, people[0].ID, nil, ""); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	// Assert
+	history, err := GetPlanHistory(db, element.ID)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(history) != 2 {
+		t.Fatalf("expected 2 revisions, got %d", len(history))
+	}
+	first := history[0]
+	if first.OldPerson.GivenName != "Anna" || first.NewPerson.GivenName != "Ben" || first.Reason != "sick" || first.UserID == nil || *first.UserID != userID {
+		t.Errorf("unexpected first revision %+v", first)
+	}
+	if published.IsZero() {
+		t.Fatalf("expected time of publication")
+	}
+	changes, err := GetPlanChanges(db, published)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	var changesOfElement int
+	for _, change := range changes {
+		if change.PlanID == element.ID {
+			changesOfElement++
+		}
+	}
+	if changesOfElement != 1 {
+		t.Errorf("expected 1 change since publication, got %d", changesOfElement)
+	}
+}
+
+func TestFillPlanRevision(t *testing.T) {
+	// Prepare
+	db := database.DB.Begin()
+	t.Cleanup(func() {
+		db.Rollback()
+	})
+	task := dbModel.Task{Descr: "RevisionTask"}
+	if err := db.Create(&task).Error; err != nil {
+		t.Fatalf("test preparation failed: %v", err)
+	}
+	detail := dbModel.TaskDetail{Descr: "RevisionDetail", TaskID: task.ID}
+	person := dbModel.Person{GivenName: "Filled", LastName: "Revision"}
+	meeting := dbModel.Meeting{Date: time.Date(2006, 2, 5, 0, 0, 0, 0, time.UTC)}
+	for _, value := range []interface{}{&detail, &person, &meeting} {
+		if err := db.Create(value).Error; err != nil {
+			t.Fatalf("test preparation failed: %v", err)
+		}
+	}
+	if err := db.Create(&dbModel.PersonTask{PersonID: person.ID, TaskDetailID: detail.ID}).Error; err != nil {
+		t.Fatalf("test preparation failed: %v", err)
+	}
+	element := dbModel.Plan{MeetingID: meeting.ID, TaskDetailID: detail.ID}
+	if err := db.Create(&element).Error; err != nil {
+		t.Fatalf("test preparation failed: %v", err)
+	}
+	userID := uint(1)
+
+	// Act
+	filled, err := FillPlanData(db, generalmodel.Period{StartDate: meeting.Date, EndDate: meeting.Date}, leastLoaded{}, false, &userID)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(filled) != 1 {
+		t.Fatalf("expected 1 filled plan element, got %d", len(filled))
+	}
+	history, err := GetPlanHistory(db, element.ID)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(history) != 1 {
+		t.Fatalf("expected 1 revision, got %d", len(history))
+	}
+	if history[0].OldPersonID != 0 || history[0].NewPersonID != filled[0].PersonID || history[0].Reason != RevisionFill || history[0].UserID == nil || *history[0].UserID != userID {
+		t.Errorf("unexpected revision %+v", history[0])
+	}
+}
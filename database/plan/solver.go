@@ -0,0 +1,818 @@
+package plan
+
+import (
+	"math"
+	"mpt_data/models/apimodel"
+	dbModel "mpt_data/models/dbmodel"
+	generalmodel "mpt_data/models/general"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	monthFormat = "2006-01"
+	// loadScale converts the fractional load to integer costs of the flow graph
+	loadScale = 100
+	// maxSolves bounds the number of flows calculated while resolving conflicts of the solution
+	maxSolves = 100
+)
+
+type (
+	// solverSlot is one slot of a task at a meeting, that must be assigned
+	solverSlot struct {
+		plan       dbModel.Plan
+		candidates []uint
+		// meetingIndex is the position of the meeting in the ordered meetings of the period
+		meetingIndex int
+		// restDays and restMeetings are the minimum rest interval for the TaskDetail
+		restDays, restMeetings uint
+		// preference holds the preference score of the candidates for the slot
+		preference map[uint]int
+		// capPeriod identifies the plan period or month of the meeting, against which the caps for a period are checked
+		capPeriod string
+	}
+
+	// personPeriod identifies the cap period of a person
+	personPeriod struct {
+		personID uint
+		period   string
+	}
+
+	// personMonth identifies a calendar month of a person
+	personMonth struct {
+		personID uint
+		month    string
+	}
+
+	// personTask identifies a TaskDetail of a person
+	personTask struct {
+		personID, taskDetailID uint
+	}
+
+	// taskPeriod identifies a TaskDetail of a person in a cap period
+	taskPeriod struct {
+		personTask
+		period string
+	}
+
+	// solverCapacity holds how many more assignments people may get.
+	// People without an entry are unlimited
+	solverCapacity struct {
+		period map[personPeriod]int
+		month  map[personMonth]int
+		task   map[taskPeriod]int
+	}
+
+	// flowEdge is an edge in the residual graph of flowGraph
+	flowEdge struct {
+		to, rev        int
+		capacity, cost int
+		forward        bool
+	}
+
+	// flowGraph is a graph to calculate a min cost max flow
+	flowGraph struct {
+		edges [][]flowEdge
+	}
+
+	// solverRemoval removes a candidate from a slot to resolve a conflict of the solution
+	solverRemoval struct {
+		slot     int
+		personID uint
+	}
+
+	// solverSearch is a branch and bound search over the conflicts of the flow solution
+	solverSearch struct {
+		load           map[uint]float64
+		capacity       solverCapacity
+		assignedBefore map[uint]map[uint]bool
+		relations      []dbModel.PersonRelation
+		// solves is the number of flows left to calculate
+		solves int
+		best   []uint
+		// filled and cost of best
+		filled, cost int
+	}
+)
+
+// SolvePlanData creates all entries in table plans for the specified period and assigns people to all slots together.
+// In contrast to CreatePlanData a min cost max flow fills as many slots as possible, afterwards it minimises the imbalance of load between people.
+// The caps for a period are checked against the plan period of each meeting, or its month, as in UpdatePlanElement.
+// The flow keeps the one task per meeting rule, the caps per month, the caps per period, unless a capped month of the person
+// spans two cap periods, and the caps per TaskDetail, where a person is only candidate for one TaskDetail at a meeting
+// and has no cap per month. All other caps, rest intervals and relations are resolved afterwards by a branch and bound search, which removes the conflicting candidates one by one
+// and solves the flow again. The search is bounded by maxSolves, if it is exhausted, conflicts are resolved greedily,
+// so the result may have more unfilled slots than necessary in this case.
+// Meetings with a MeetingType only get slots for its TaskDetails.
+// If not all slots could be filled, the result is not complete and holds the unfilled slots.
+// If a meeting in period is published, the plan is only created with force
+func SolvePlanData(db *gorm.DB, period generalmodel.Period, force bool) (result apimodel.PlanResult, err error) {
+	if err := checkNotPublished(db, period, force); err != nil {
+		return result, err
+	}
+	if err := markPDFChanged(db, period); err != nil {
+		zap.L().Error(generalmodel.PlanCreationFailed, zap.Error(err), zap.String(generalmodel.AdditionalInfo, "PDF loading failed"))
+		return result, err
+	}
+
+	var meetings []dbModel.Meeting
+	if err :=
+		db.Preload("Tag").Where("date between ? and ?", period.StartDate, period.EndDate).
+			Order("date").Find(&meetings).Error; err != nil {
+		zap.L().Error(generalmodel.PlanCreationFailed, zap.Error(err), zap.String(generalmodel.AdditionalInfo, "Failed to load meetings"))
+		return result, err
+	}
+
+	var tasks []dbModel.TaskDetail
+	if err := db.Find(&tasks).Error; err != nil {
+		zap.L().Error(generalmodel.PlanCreationFailed, zap.Error(err), zap.String(generalmodel.AdditionalInfo, "Failed to load tasks"))
+		return result, err
+	}
+	required, err := loadMeetingTasks(db)
+	if err != nil {
+		zap.L().Error(generalmodel.PlanCreationFailed, zap.Error(err), zap.String(generalmodel.AdditionalInfo, "Failed to load meeting types"))
+		return result, err
+	}
+
+	var planIDs []uint
+	var slots []solverSlot
+	var candidateIDs []uint
+	assignedBefore := make(map[uint]map[uint]bool)
+	for meetingIndex, meeting := range meetings {
+		var ids []uint
+		if meeting.Tag.ID != 0 {
+			if db.Table("plans").Where("meeting_id = ?", meeting.ID).Select("id").Find(&ids); len(ids) != 0 {
+				continue
+			}
+			plan := dbModel.Plan{MeetingID: meeting.ID}
+			if err := db.Create(&plan).Error; err != nil {
+				zap.L().Error(generalmodel.PlanCreationFailed, zap.Error(err), zap.String(generalmodel.AdditionalInfo, "Failed to create plan element"))
+				return result, err
+			}
+			planIDs = append(planIDs, plan.ID)
+			continue
+		}
+
+		if assignedBefore[meeting.ID], err = peopleAssignedAt(db, dbModel.Plan{MeetingID: meeting.ID}); err != nil {
+			zap.L().Error(generalmodel.PlanCreationFailed, zap.Error(err), zap.String(generalmodel.AdditionalInfo, "person loading error"))
+			return result, err
+		}
+		for _, task := range tasks {
+			if !required.requires(meeting, task.ID) {
+				continue
+			}
+			for index := uint(0); index < task.Slots(); index++ {
+				if db.Table("plans").Where("meeting_id = ?", meeting.ID).Where("task_detail_id = ?", task.ID).Where("slot = ?", index).Select("id").Find(&ids); len(ids) != 0 {
+					continue
+				}
+
+				slot := solverSlot{plan: dbModel.Plan{MeetingID: meeting.ID, TaskDetailID: task.ID, Slot: index, Meeting: meeting}, meetingIndex: meetingIndex}
+				slot.restDays, slot.restMeetings = restInterval(task)
+				people, err := getAvailablePeople(slot.plan, period, db, false, true)
+				if err != nil {
+					zap.L().Error(generalmodel.PlanCreationFailed, zap.Error(err), zap.String(generalmodel.AdditionalInfo, "person loading error"))
+					return result, err
+				}
+				for _, person := range people {
+					slot.candidates = append(slot.candidates, person.ID)
+					candidateIDs = append(candidateIDs, person.ID)
+				}
+				slots = append(slots, slot)
+			}
+		}
+	}
+
+	load := make(map[uint]float64)
+	if len(candidateIDs) > 0 {
+		if load, err = assignmentLoad(db, period, candidateIDs); err != nil {
+			zap.L().Error(generalmodel.PlanCreationFailed, zap.Error(err), zap.String(generalmodel.AdditionalInfo, "Failed to count assignments"))
+			return result, err
+		}
+	}
+
+	preferences, err := loadPreferences(db, candidateIDs)
+	if err != nil {
+		zap.L().Error(generalmodel.PlanCreationFailed, zap.Error(err), zap.String(generalmodel.AdditionalInfo, "Failed to load preferences"))
+		return result, err
+	}
+	for i := range slots {
+		slots[i].preference = make(map[uint]int, len(slots[i].candidates))
+		for _, personID := range slots[i].candidates {
+			slots[i].preference[personID] = preferenceScore(preferences[personID], slots[i].plan)
+		}
+	}
+
+	capacity, err := loadCapacity(db, slots, candidateIDs)
+	if err != nil {
+		zap.L().Error(generalmodel.PlanCreationFailed, zap.Error(err), zap.String(generalmodel.AdditionalInfo, "Failed to load maximum assignments"))
+		return result, err
+	}
+
+	relations, err := loadRelations(db, candidateIDs)
+	if err != nil {
+		zap.L().Error(generalmodel.PlanCreationFailed, zap.Error(err), zap.String(generalmodel.AdditionalInfo, "Failed to load relations"))
+		return result, err
+	}
+
+	assigned := solveSlots(slots, load, capacity, assignedBefore, relations)
+
+	for i, personID := range assigned {
+		plan := dbModel.Plan{PersonID: personID, MeetingID: slots[i].plan.MeetingID, TaskDetailID: slots[i].plan.TaskDetailID, Slot: slots[i].plan.Slot}
+		if err := db.Create(&plan).Error; err != nil {
+			zap.L().Error(generalmodel.PlanCreationFailed, zap.Error(err), zap.String(generalmodel.AdditionalInfo, "Failed to create plan element"))
+			return result, err
+		}
+		planIDs = append(planIDs, plan.ID)
+	}
+
+	var plan []dbModel.Plan
+	if err :=
+		db.Preload("Person").
+			Preload("Meeting").
+			Preload("TaskDetail.Task").
+			Preload("TaskDetail").
+			Where("id IN (?)", planIDs).Find(&plan).Error; err != nil {
+		return result, err
+	}
+
+	result = NewPlanResult(plan)
+	if !result.Complete {
+		zap.L().Info(generalmodel.PlanNotComplete, zap.Int("unfilled", len(result.Unfilled)))
+	}
+
+	return result, nil
+}
+
+// solveSlots assigns the candidates to the slots, keeping the caps, the rest intervals and the relations.
+// Conflicts of the flow solution are resolved by a branch and bound search: one of the conflicting assignments is removed
+// from the candidates and the flow is solved again. The solution with the most filled slots and the lowest cost is returned.
+// A branch is cut, if its flow fills fewer slots or costs more than the best solution, as removing candidates never improves the flow.
+// After maxSolves flows the conflicts of the current branch are resolved greedily
+func solveSlots(slots []solverSlot, load map[uint]float64, capacity solverCapacity, assignedBefore map[uint]map[uint]bool, relations []dbModel.PersonRelation) []uint {
+	search := solverSearch{
+		load:           load,
+		capacity:       capacity,
+		assignedBefore: assignedBefore,
+		relations:      relations,
+		solves:         maxSolves,
+	}
+	search.search(slots)
+	return search.best
+}
+
+// search solves the flow for slots and branches over the removals resolving the first conflict
+func (s *solverSearch) search(slots []solverSlot) {
+	assigned, cost := assignSlots(slots, s.load, s.capacity)
+	s.solves--
+	if s.best != nil && !s.better(assigned, cost) {
+		return
+	}
+
+	removals := s.conflict(slots, assigned)
+	if len(removals) == 0 {
+		s.keep(assigned, cost)
+		return
+	}
+	if s.solves <= 0 {
+		s.repair(copySlots(slots))
+		return
+	}
+	for _, removal := range removals {
+		if s.solves <= 0 && s.best != nil {
+			return
+		}
+		branch := copySlots(slots)
+		branch[removal.slot].removeCandidate(removal.personID)
+		s.search(branch)
+	}
+}
+
+// repair resolves the conflicts of slots greedily, removing the later assignments
+func (s *solverSearch) repair(slots []solverSlot) {
+	assigned, cost := assignSlots(slots, s.load, s.capacity)
+	for removals := s.conflict(slots, assigned); removals != nil; removals = s.conflict(slots, assigned) {
+		slots[removals[0].slot].removeCandidate(removals[0].personID)
+		assigned, cost = assignSlots(slots, s.load, s.capacity)
+	}
+	if s.best == nil || s.better(assigned, cost) {
+		s.keep(assigned, cost)
+	}
+}
+
+// conflict returns the removals, of which one is needed to resolve the first conflict of assigned, nil without conflicts.
+// The removal chosen by the greedy repair comes first
+func (s *solverSearch) conflict(slots []solverSlot, assigned []uint) []solverRemoval {
+	if removals := periodCapConflict(slots, assigned, s.capacity); removals != nil {
+		return removals
+	}
+	if removals := taskCapConflict(slots, assigned, s.capacity); removals != nil {
+		return removals
+	}
+	if removals := restConflict(slots, assigned); removals != nil {
+		return removals
+	}
+	return relationConflict(slots, assigned, s.assignedBefore, s.relations)
+}
+
+// better reports if assigned fills more slots than the best solution or the same number with lower cost
+func (s *solverSearch) better(assigned []uint, cost int) bool {
+	filled := filledSlots(assigned)
+	return filled > s.filled || (filled == s.filled && cost < s.cost)
+}
+
+func (s *solverSearch) keep(assigned []uint, cost int) {
+	s.best, s.filled, s.cost = assigned, filledSlots(assigned), cost
+}
+
+// filledSlots counts the slots with a person
+func filledSlots(assigned []uint) (filled int) {
+	for _, personID := range assigned {
+		if personID != 0 {
+			filled++
+		}
+	}
+	return filled
+}
+
+// copySlots copies slots with their candidates, so candidates can be removed in a branch of the search
+func copySlots(slots []solverSlot) []solverSlot {
+	copied := make([]solverSlot, len(slots))
+	for i, slot := range slots {
+		copied[i] = slot
+		copied[i].candidates = append([]uint(nil), slot.candidates...)
+	}
+	return copied
+}
+
+// periodCapConflict returns the slots of a person exceeding the maximum assignments in a cap period, latest first
+func periodCapConflict(slots []solverSlot, assigned []uint, capacity solverCapacity) []solverRemoval {
+	used := make(map[personPeriod]int)
+	for i := range slots {
+		if assigned[i] != 0 {
+			used[personPeriod{assigned[i], slots[i].capPeriod}]++
+		}
+	}
+	for i := range slots {
+		key := personPeriod{assigned[i], slots[i].capPeriod}
+		if remaining, capped := capacity.period[key]; assigned[i] == 0 || !capped || used[key] <= remaining {
+			continue
+		}
+		var removals []solverRemoval
+		for j := len(slots) - 1; j >= 0; j-- {
+			if assigned[j] == key.personID && slots[j].capPeriod == key.period {
+				removals = append(removals, solverRemoval{j, key.personID})
+			}
+		}
+		return removals
+	}
+	return nil
+}
+
+// taskCapConflict returns the slots of a person exceeding the maximum assignments for a TaskDetail in a cap period, latest first
+func taskCapConflict(slots []solverSlot, assigned []uint, capacity solverCapacity) []solverRemoval {
+	used := make(map[taskPeriod]int)
+	for i := range slots {
+		if assigned[i] != 0 {
+			used[taskPeriodOf(assigned[i], slots[i])]++
+		}
+	}
+	for i := range slots {
+		key := taskPeriodOf(assigned[i], slots[i])
+		if remaining, capped := capacity.task[key]; assigned[i] == 0 || !capped || used[key] <= remaining {
+			continue
+		}
+		var removals []solverRemoval
+		for j := len(slots) - 1; j >= 0; j-- {
+			if assigned[j] == key.personID && taskPeriodOf(assigned[j], slots[j]) == key {
+				removals = append(removals, solverRemoval{j, key.personID})
+			}
+		}
+		return removals
+	}
+	return nil
+}
+
+// restConflict returns both slots of the first person assigned too close to another assignment, the later slot first
+func restConflict(slots []solverSlot, assigned []uint) []solverRemoval {
+	for i := range slots {
+		for j := range slots {
+			if assigned[i] == 0 || assigned[i] != assigned[j] || slots[i].meetingIndex >= slots[j].meetingIndex {
+				continue
+			}
+			if tooClose(slots[i], slots[j]) || tooClose(slots[j], slots[i]) {
+				return []solverRemoval{{j, assigned[j]}, {i, assigned[i]}}
+			}
+		}
+	}
+	return nil
+}
+
+// relationConflict returns the latest slot, whose person violates a relation at the meeting,
+// and the slots of the people at the meeting, the person must not serve with
+func relationConflict(slots []solverSlot, assigned []uint, assignedBefore map[uint]map[uint]bool, relations []dbModel.PersonRelation) []solverRemoval {
+	if len(relations) == 0 {
+		return nil
+	}
+	atMeeting := make(map[uint]map[uint]bool)
+	for i, slot := range slots {
+		if atMeeting[slot.plan.MeetingID] == nil {
+			atMeeting[slot.plan.MeetingID] = make(map[uint]bool)
+			for personID := range assignedBefore[slot.plan.MeetingID] {
+				atMeeting[slot.plan.MeetingID][personID] = true
+			}
+		}
+		if assigned[i] != 0 {
+			atMeeting[slot.plan.MeetingID][assigned[i]] = true
+		}
+	}
+
+	for i := len(slots) - 1; i >= 0; i-- {
+		if assigned[i] == 0 {
+			continue
+		}
+		people := make(map[uint]bool, len(atMeeting[slots[i].plan.MeetingID]))
+		for personID := range atMeeting[slots[i].plan.MeetingID] {
+			people[personID] = personID != assigned[i]
+		}
+		if !violatesRelation(assigned[i], relations, people, nil) {
+			continue
+		}
+		removals := []solverRemoval{{i, assigned[i]}}
+		for _, relation := range relations {
+			partner, ok := relation.Partner(assigned[i])
+			if !ok || relation.Type != dbModel.RelationApart {
+				continue
+			}
+			for j := range slots {
+				if j != i && assigned[j] == partner && slots[j].plan.MeetingID == slots[i].plan.MeetingID {
+					removals = append(removals, solverRemoval{j, partner})
+				}
+			}
+		}
+		return removals
+	}
+	return nil
+}
+
+// loadCapacity loads the remaining assignments of all capped people per cap period, per month and per TaskDetail.
+// The cap period of a slot is the plan period of its meeting or its month, it is stored in the slots
+func loadCapacity(db *gorm.DB, slots []solverSlot, personIDs []uint) (capacity solverCapacity, err error) {
+	capacity = solverCapacity{
+		period: make(map[personPeriod]int),
+		month:  make(map[personMonth]int),
+		task:   make(map[taskPeriod]int),
+	}
+
+	periods := make(map[string]generalmodel.Period)
+	months := make(map[string]generalmodel.Period)
+	for i := range slots {
+		date := slots[i].plan.Meeting.Date
+		capPeriod, err := periodOf(db, date)
+		if err != nil {
+			return capacity, err
+		}
+		slots[i].capPeriod = periodKey(capPeriod)
+		periods[slots[i].capPeriod] = capPeriod
+		months[date.Format(monthFormat)] = monthOf(date)
+	}
+	if len(personIDs) == 0 {
+		return capacity, nil
+	}
+
+	var people []dbModel.Person
+	if err := db.Where("id IN (?)", personIDs).Find(&people).Error; err != nil {
+		return capacity, err
+	}
+	var personTasks []dbModel.PersonTask
+	if err :=
+		db.Where("person_id IN (?)", personIDs).
+			Where("max_assignments_period <> 0").
+			Find(&personTasks).Error; err != nil {
+		return capacity, err
+	}
+
+	for key, capPeriod := range periods {
+		periodCount, err := countAssignments(db, capPeriod, personIDs)
+		if err != nil {
+			return capacity, err
+		}
+		for _, person := range people {
+			if person.MaxAssignmentsPeriod != 0 {
+				capacity.period[personPeriod{person.ID, key}] = int(person.MaxAssignmentsPeriod) - int(periodCount[person.ID])
+			}
+		}
+
+		var taskCount []struct {
+			PersonID     uint
+			TaskDetailID uint
+			Count        int
+		}
+		if err :=
+			db.Table("plans").
+				Where("person_id IN (?)", personIDs).
+				Where("meeting_id IN (?)", db.Table("meetings").Where("date between ? and ?", capPeriod.StartDate, capPeriod.EndDate).Select("id")).
+				Group("person_id, task_detail_id").
+				Select("person_id, task_detail_id, COUNT(*) as count").
+				Scan(&taskCount).Error; err != nil {
+			return capacity, err
+		}
+		for _, personTask := range personTasks {
+			capacity.task[taskPeriod{personTaskOf(personTask.PersonID, personTask.TaskDetailID), key}] = int(personTask.MaxAssignmentsPeriod)
+		}
+		for _, count := range taskCount {
+			task := taskPeriod{personTaskOf(count.PersonID, count.TaskDetailID), key}
+			if _, ok := capacity.task[task]; ok {
+				capacity.task[task] -= count.Count
+			}
+		}
+	}
+
+	for month, monthPeriod := range months {
+		monthCount, err := countAssignments(db, monthPeriod, personIDs)
+		if err != nil {
+			return capacity, err
+		}
+		for _, person := range people {
+			if person.MaxAssignmentsMonth != 0 {
+				capacity.month[personMonth{person.ID, month}] = int(person.MaxAssignmentsMonth) - int(monthCount[person.ID])
+			}
+		}
+	}
+
+	return capacity, nil
+}
+
+// periodKey identifies a cap period in the solver
+func periodKey(period generalmodel.Period) string {
+	return period.StartDate.Format(time.RFC3339) + "/" + period.EndDate.Format(time.RFC3339)
+}
+
+// tooClose reports if the meeting of other is within the rest interval of slot
+func tooClose(slot, other solverSlot) bool {
+	meetings := slot.meetingIndex - other.meetingIndex
+	if meetings < 0 {
+		meetings = -meetings
+	}
+	if slot.restMeetings != 0 && meetings <= int(slot.restMeetings) {
+		return true
+	}
+	days := slot.plan.Meeting.Date.Sub(other.plan.Meeting.Date)
+	if days < 0 {
+		days = -days
+	}
+	return slot.restDays != 0 && days < time.Duration(slot.restDays)*24*time.Hour
+}
+
+// removeCandidate removes personID from the candidates of the slot
+func (slot *solverSlot) removeCandidate(personID uint) {
+	candidates := make([]uint, 0, len(slot.candidates))
+	for _, candidate := range slot.candidates {
+		if candidate != personID {
+			candidates = append(candidates, candidate)
+		}
+	}
+	slot.candidates = candidates
+}
+
+func personTaskOf(personID, taskDetailID uint) personTask {
+	return personTask{personID: personID, taskDetailID: taskDetailID}
+}
+
+// taskPeriodOf returns the TaskDetail of slot for personID in the cap period of slot
+func taskPeriodOf(personID uint, slot solverSlot) taskPeriod {
+	return taskPeriod{personTaskOf(personID, slot.plan.TaskDetailID), slot.capPeriod}
+}
+
+// assignSlots assigns the candidates to the slots with a min cost max flow.
+// Every slot gets at most one person, every person at most one slot per meeting and not more than their capacity per month.
+// The capacity per period is only kept, if a capped month of the person lies in one cap period, the capacity per TaskDetail
+// only, if the person has no capacity per month and is candidate for one TaskDetail at the meeting.
+// The costs of a person rise with every assignment, so the load is spread evenly, load holds the already existing assignments.
+// Preferences of the candidates lower the costs of a slot, but never the number of filled slots.
+// Returns the assigned person for every slot, 0 if the slot stays unfilled, and the cost of the flow
+func assignSlots(slots []solverSlot, load map[uint]float64, capacity solverCapacity) (assigned []uint, cost int) {
+	const (
+		source = 0
+		sink   = 1
+	)
+	type personMeeting struct {
+		personID, meetingID uint
+	}
+
+	nodes := 2 + len(slots)
+	personMeetingNodes := make(map[personMeeting]int)
+	var personMeetings []personMeeting
+	// the cap period, month and TaskDetail of the candidate slots of a person at a meeting, the TaskDetail is 0 if there are several
+	periodOfPersonMeeting := make(map[personMeeting]string)
+	monthOfPersonMeeting := make(map[personMeeting]personMonth)
+	taskOfPersonMeeting := make(map[personMeeting]uint)
+	personNodes := make(map[uint]int)
+	var people []uint
+	meetingsOfPerson := make(map[uint]int)
+	for _, slot := range slots {
+		for _, personID := range slot.candidates {
+			key := personMeeting{personID, slot.plan.MeetingID}
+			if task, ok := taskOfPersonMeeting[key]; ok {
+				if task != slot.plan.TaskDetailID {
+					taskOfPersonMeeting[key] = 0
+				}
+				continue
+			}
+			taskOfPersonMeeting[key] = slot.plan.TaskDetailID
+			periodOfPersonMeeting[key] = slot.capPeriod
+			monthOfPersonMeeting[key] = personMonth{personID, slot.plan.Meeting.Date.Format(monthFormat)}
+			personMeetingNodes[key] = nodes
+			personMeetings = append(personMeetings, key)
+			nodes++
+			meetingsOfPerson[personID]++
+			if _, ok := personNodes[personID]; !ok {
+				personNodes[personID] = nodes
+				nodes++
+				people = append(people, personID)
+			}
+		}
+	}
+
+	// the flow of a person at a meeting passes the capped month or the capped TaskDetail, then the capped period and the person.
+	// A month spanning two cap periods leads to the person, its cap period is resolved by solveSlots
+	personMonthNodes := make(map[personMonth]int)
+	var personMonths []personMonth
+	periodOfMonth := make(map[personMonth]string)
+	// spansPeriods holds the capped months of people lying in several cap periods
+	spansPeriods := make(map[personMonth]bool)
+	personTaskNodes := make(map[taskPeriod]int)
+	var personTasks []taskPeriod
+	taskOfPersonMeetingNode := make(map[personMeeting]taskPeriod)
+	personPeriodNodes := make(map[personPeriod]int)
+	var personPeriods []personPeriod
+	addPeriod := func(key personPeriod) {
+		if _, capped := capacity.period[key]; !capped {
+			return
+		}
+		if _, ok := personPeriodNodes[key]; !ok {
+			personPeriodNodes[key] = nodes
+			nodes++
+			personPeriods = append(personPeriods, key)
+		}
+	}
+	for _, key := range personMeetings {
+		capPeriod := periodOfPersonMeeting[key]
+		month := monthOfPersonMeeting[key]
+		if _, capped := capacity.month[month]; capped {
+			if _, ok := personMonthNodes[month]; !ok {
+				personMonthNodes[month] = nodes
+				nodes++
+				personMonths = append(personMonths, month)
+				periodOfMonth[month] = capPeriod
+			} else if periodOfMonth[month] != capPeriod {
+				spansPeriods[month] = true
+			}
+			continue
+		}
+		addPeriod(personPeriod{key.personID, capPeriod})
+		// the cap of the TaskDetail is a capacity, if it is unambiguous which TaskDetail the person serves at the meeting,
+		// otherwise it is resolved by solveSlots
+		task := taskPeriod{personTaskOf(key.personID, taskOfPersonMeeting[key]), capPeriod}
+		if _, capped := capacity.task[task]; !capped || task.taskDetailID == 0 {
+			continue
+		}
+		taskOfPersonMeetingNode[key] = task
+		if _, ok := personTaskNodes[task]; !ok {
+			personTaskNodes[task] = nodes
+			nodes++
+			personTasks = append(personTasks, task)
+		}
+	}
+	for _, month := range personMonths {
+		if !spansPeriods[month] {
+			addPeriod(personPeriod{month.personID, periodOfMonth[month]})
+		}
+	}
+
+	graph := newFlowGraph(nodes)
+	// periodNode returns the node of the capped period, or the person if the period is not capped
+	periodNode := func(key personPeriod) int {
+		if node, capped := personPeriodNodes[key]; capped {
+			return node
+		}
+		return personNodes[key.personID]
+	}
+	personOfNode := make(map[int]uint)
+	for i, slot := range slots {
+		graph.addEdge(source, 2+i, 1, 0)
+		for _, personID := range slot.candidates {
+			node := personMeetingNodes[personMeeting{personID, slot.plan.MeetingID}]
+			personOfNode[node] = personID
+			graph.addEdge(2+i, node, 1, preferenceCost(slot.preference[personID]))
+		}
+	}
+	for _, key := range personMeetings {
+		if node, capped := personMonthNodes[monthOfPersonMeeting[key]]; capped {
+			graph.addEdge(personMeetingNodes[key], node, 1, 0)
+		} else if task, capped := taskOfPersonMeetingNode[key]; capped {
+			graph.addEdge(personMeetingNodes[key], personTaskNodes[task], 1, 0)
+		} else {
+			graph.addEdge(personMeetingNodes[key], periodNode(personPeriod{key.personID, periodOfPersonMeeting[key]}), 1, 0)
+		}
+	}
+	for _, month := range personMonths {
+		next := personNodes[month.personID]
+		if !spansPeriods[month] {
+			next = periodNode(personPeriod{month.personID, periodOfMonth[month]})
+		}
+		if remaining := capacity.month[month]; remaining > 0 {
+			graph.addEdge(personMonthNodes[month], next, remaining, 0)
+		}
+	}
+	for _, task := range personTasks {
+		if remaining := capacity.task[task]; remaining > 0 {
+			graph.addEdge(personTaskNodes[task], periodNode(personPeriod{task.personID, task.period}), remaining, 0)
+		}
+	}
+	for _, key := range personPeriods {
+		if remaining := capacity.period[key]; remaining > 0 {
+			graph.addEdge(personPeriodNodes[key], personNodes[key.personID], remaining, 0)
+		}
+	}
+	for _, personID := range people {
+		// every further assignment costs more, which minimises the sum of squared loads,
+		// costs are scaled, because the load of the history is fractional
+		for unit := 1; unit <= meetingsOfPerson[personID]; unit++ {
+			graph.addEdge(personNodes[personID], sink, 1, int(math.Round(loadScale*(2*(load[personID]+float64(unit))-1))))
+		}
+	}
+
+	_, cost = graph.minCostFlow(source, sink)
+
+	assigned = make([]uint, len(slots))
+	for i := range slots {
+		for _, edge := range graph.edges[2+i] {
+			if edge.forward && edge.capacity == 0 {
+				assigned[i] = personOfNode[edge.to]
+			}
+		}
+	}
+	return assigned, cost
+}
+
+func newFlowGraph(nodes int) *flowGraph {
+	return &flowGraph{edges: make([][]flowEdge, nodes)}
+}
+
+// addEdge adds an edge and its residual edge to the graph
+func (g *flowGraph) addEdge(from, to, capacity, cost int) {
+	g.edges[from] = append(g.edges[from], flowEdge{to: to, rev: len(g.edges[to]), capacity: capacity, cost: cost, forward: true})
+	g.edges[to] = append(g.edges[to], flowEdge{to: from, rev: len(g.edges[from]) - 1, capacity: 0, cost: -cost})
+}
+
+// minCostFlow sends as much flow as possible from source to sink with minimal cost.
+// Uses successive shortest paths, found with Bellman-Ford, because residual edges have negative costs
+func (g *flowGraph) minCostFlow(source, sink int) (flow, cost int) {
+	const infinity = int(^uint(0) >> 1)
+	nodes := len(g.edges)
+
+	for {
+		dist := make([]int, nodes)
+		prevNode := make([]int, nodes)
+		prevEdge := make([]int, nodes)
+		inQueue := make([]bool, nodes)
+		for i := range dist {
+			dist[i] = infinity
+		}
+		dist[source] = 0
+		queue := []int{source}
+		inQueue[source] = true
+
+		for len(queue) > 0 {
+			node := queue[0]
+			queue = queue[1:]
+			inQueue[node] = false
+			for i, edge := range g.edges[node] {
+				if edge.capacity > 0 && dist[node]+edge.cost < dist[edge.to] {
+					dist[edge.to] = dist[node] + edge.cost
+					prevNode[edge.to] = node
+					prevEdge[edge.to] = i
+					if !inQueue[edge.to] {
+						queue = append(queue, edge.to)
+						inQueue[edge.to] = true
+					}
+				}
+			}
+		}
+
+		if dist[sink] == infinity {
+			return flow, cost
+		}
+
+		// the edges from source have capacity 1, so every path carries one unit
+		for node := sink; node != source; node = prevNode[node] {
+			edge := &g.edges[prevNode[node]][prevEdge[node]]
+			edge.capacity--
+			g.edges[node][edge.rev].capacity++
+		}
+		flow++
+		cost += dist[sink]
+	}
+}
@@ -0,0 +1,312 @@
+package plan
+
+import (
+	dbModel "mpt_data/models/dbmodel"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestAssignSlots(t *testing.T) {
+	january := time.Date(2024, 1, 7, 0, 0, 0, 0, time.UTC)
+	var testcases = []struct {
+		name     string
+		slots    []solverSlot
+		load     map[uint]float64
+		capacity solverCapacity
+		expected []uint
+	}{
+		{
+			name: "fill all slots of meeting",
+			slots: []solverSlot{
+				{plan: dbModel.Plan{MeetingID: 1, TaskDetailID: 1}, candidates: []uint{1, 2}},
+				{plan: dbModel.Plan{MeetingID: 1, TaskDetailID: 2}, candidates: []uint{1}},
+			},
+			expected: []uint{2, 1},
+		},
+		{
+			name: "one task per meeting",
+			slots: []solverSlot{
+				{plan: dbModel.Plan{MeetingID: 1, TaskDetailID: 1}, candidates: []uint{1}},
+				{plan: dbModel.Plan{MeetingID: 1, TaskDetailID: 2}, candidates: []uint{1}},
+			},
+			expected: []uint{1, 0},
+		},
+		{
+			name: "spread load",
+			slots: []solverSlot{
+				{plan: dbModel.Plan{MeetingID: 1, TaskDetailID: 1}, candidates: []uint{1, 2}},
+				{plan: dbModel.Plan{MeetingID: 2, TaskDetailID: 1}, candidates: []uint{1, 2}},
+			},
+			expected: []uint{1, 2},
+		},
+		{
+			name: "existing load",
+			slots: []solverSlot{
+				{plan: dbModel.Plan{MeetingID: 1, TaskDetailID: 1}, candidates: []uint{1, 2}},
+			},
+			load:     map[uint]float64{1: 2},
+			expected: []uint{2},
+		},
+		{
+			name: "period capacity",
+			slots: []solverSlot{
+				{plan: dbModel.Plan{MeetingID: 1, TaskDetailID: 1}, candidates: []uint{1}},
+				{plan: dbModel.Plan{MeetingID: 2, TaskDetailID: 1}, candidates: []uint{1}},
+			},
+			capacity: solverCapacity{period: map[personPeriod]int{{1, ""}: 1}},
+			expected: []uint{1, 0},
+		},
+		{
+			name: "period capacity per cap period",
+			slots: []solverSlot{
+				{plan: dbModel.Plan{MeetingID: 1, TaskDetailID: 1}, candidates: []uint{1}, capPeriod: "january"},
+				{plan: dbModel.Plan{MeetingID: 2, TaskDetailID: 1}, candidates: []uint{1}, capPeriod: "february"},
+			},
+			capacity: solverCapacity{period: map[personPeriod]int{{1, "january"}: 1, {1, "february"}: 1}},
+			expected: []uint{1, 1},
+		},
+		{
+			name: "month capacity",
+			slots: []solverSlot{
+				{plan: dbModel.Plan{MeetingID: 1, TaskDetailID: 1, Meeting: dbModel.Meeting{Date: january}}, candidates: []uint{1, 2}},
+				{plan: dbModel.Plan{MeetingID: 2, TaskDetailID: 1, Meeting: dbModel.Meeting{Date: january.AddDate(0, 0, 7)}}, candidates: []uint{1}},
+			},
+			capacity: solverCapacity{month: map[personMonth]int{{1, "2024-01"}: 1}},
+			expected: []uint{2, 1},
+		},
+		{
+			name: "task capacity",
+			slots: []solverSlot{
+				{plan: dbModel.Plan{MeetingID: 1, TaskDetailID: 1}, candidates: []uint{1, 2}},
+				{plan: dbModel.Plan{MeetingID: 2, TaskDetailID: 1}, candidates: []uint{1}},
+			},
+			load:     map[uint]float64{2: 5},
+			capacity: solverCapacity{task: map[taskPeriod]int{{personTask{1, 1}, ""}: 1}},
+			expected: []uint{2, 1},
+		},
+		{
+			name: "preference decides equal load",
+			slots: []solverSlot{
+				{plan: dbModel.Plan{MeetingID: 1, TaskDetailID: 1}, candidates: []uint{1, 2}, preference: map[uint]int{2: 1}},
+			},
+			expected: []uint{2},
+		},
+		{
+			name: "preference does not leave slots unfilled",
+			slots: []solverSlot{
+				{plan: dbModel.Plan{MeetingID: 1, TaskDetailID: 1}, candidates: []uint{1, 2}, preference: map[uint]int{1: -1}},
+				{plan: dbModel.Plan{MeetingID: 1, TaskDetailID: 2}, candidates: []uint{2}, preference: map[uint]int{2: -1}},
+			},
+			expected: []uint{1, 2},
+		},
+		{
+			name: "no candidates",
+			slots: []solverSlot{
+				{plan: dbModel.Plan{MeetingID: 1, TaskDetailID: 1}},
+			},
+			expected: []uint{0},
+		},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			// Act
+			assigned, _ := assignSlots(testcase.slots, testcase.load, testcase.capacity)
+			// Assert
+			if !reflect.DeepEqual(assigned, testcase.expected) {
+				t.Errorf("expected %v, got %v", testcase.expected, assigned)
+			}
+		})
+	}
+}
+
+func TestSolveSlots(t *testing.T) {
+	sunday := time.Date(2024, 1, 7, 0, 0, 0, 0, time.UTC)
+	slot := func(meetingIndex int, taskDetailID uint, candidates ...uint) solverSlot {
+		return solverSlot{
+			plan:         dbModel.Plan{MeetingID: uint(meetingIndex + 1), TaskDetailID: taskDetailID, Meeting: dbModel.Meeting{Date: sunday.AddDate(0, 0, 7*meetingIndex)}},
+			candidates:   candidates,
+			meetingIndex: meetingIndex,
+			restMeetings: 1,
+		}
+	}
+	var testcases = []struct {
+		name      string
+		slots     []solverSlot
+		load      map[uint]float64
+		capacity  solverCapacity
+		before    map[uint]map[uint]bool
+		relations []dbModel.PersonRelation
+		expected  []uint
+	}{
+		{
+			// the greedy repair removes person 1 from the later slot, which has no other candidate
+			name:     "rest interval",
+			slots:    []solverSlot{slot(0, 1, 1, 2), slot(1, 1, 1)},
+			load:     map[uint]float64{2: 5},
+			expected: []uint{2, 1},
+		},
+		{
+			// person 1 is candidate for two TaskDetails at the first meeting, so the cap is no capacity of the flow
+			name: "task cap",
+			slots: []solverSlot{
+				func() solverSlot { s := slot(0, 1, 1, 2); s.restMeetings = 0; return s }(),
+				func() solverSlot { s := slot(0, 2, 1); s.restMeetings = 0; return s }(),
+				func() solverSlot { s := slot(2, 1, 1); s.restMeetings = 0; return s }(),
+			},
+			load:     map[uint]float64{2: 5},
+			capacity: solverCapacity{task: map[taskPeriod]int{{personTask{1, 1}, ""}: 1}},
+			expected: []uint{2, 1, 1},
+		},
+		{
+			name: "relation",
+			slots: []solverSlot{
+				func() solverSlot { s := slot(0, 1, 1, 3); s.restMeetings = 0; return s }(),
+				func() solverSlot { s := slot(0, 2, 2); s.restMeetings = 0; return s }(),
+			},
+			load:      map[uint]float64{3: 5},
+			relations: []dbModel.PersonRelation{{PersonID: 1, RelatedPersonID: 2, Type: dbModel.RelationApart}},
+			expected:  []uint{3, 2},
+		},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			// Act
+			assigned := solveSlots(testcase.slots, testcase.load, testcase.capacity, testcase.before, testcase.relations)
+			// Assert
+			if !reflect.DeepEqual(assigned, testcase.expected) {
+				t.Errorf("expected %v, got %v", testcase.expected, assigned)
+			}
+		})
+	}
+}
+
+func TestPeriodCapConflict(t *testing.T) {
+	// Prepare
+	slots := []solverSlot{
+		{plan: dbModel.Plan{MeetingID: 1, TaskDetailID: 1}, capPeriod: "january"},
+		{plan: dbModel.Plan{MeetingID: 2, TaskDetailID: 1}, capPeriod: "january"},
+		{plan: dbModel.Plan{MeetingID: 3, TaskDetailID: 1}, capPeriod: "february"},
+	}
+	capacity := solverCapacity{period: map[personPeriod]int{{1, "january"}: 1, {1, "february"}: 1}}
+	// Act
+	removals := periodCapConflict(slots, []uint{1, 1, 1}, capacity)
+	// Assert
+	if expected := []solverRemoval{{1, 1}, {0, 1}}; !reflect.DeepEqual(removals, expected) {
+		t.Errorf("expected removals %v, got %v", expected, removals)
+	}
+}
+
+func TestTaskCapConflict(t *testing.T) {
+	// Prepare
+	slots := []solverSlot{
+		{plan: dbModel.Plan{MeetingID: 1, TaskDetailID: 1}},
+		{plan: dbModel.Plan{MeetingID: 2, TaskDetailID: 1}},
+	}
+	capacity := solverCapacity{task: map[taskPeriod]int{{personTask{1, 1}, ""}: 1}}
+	// Act
+	removals := taskCapConflict(slots, []uint{1, 1}, capacity)
+	// Assert
+	if expected := []solverRemoval{{1, 1}, {0, 1}}; !reflect.DeepEqual(removals, expected) {
+		t.Errorf("expected removals %v, got %v", expected, removals)
+	}
+}
+
+func TestRestConflict(t *testing.T) {
+	sunday := time.Date(2024, 1, 7, 0, 0, 0, 0, time.UTC)
+	slot := func(meetingIndex int, days, meetings uint) solverSlot {
+		return solverSlot{
+			plan:         dbModel.Plan{MeetingID: uint(meetingIndex + 1), TaskDetailID: 1, Meeting: dbModel.Meeting{Date: sunday.AddDate(0, 0, 7*meetingIndex)}},
+			meetingIndex: meetingIndex,
+			restDays:     days,
+			restMeetings: meetings,
+		}
+	}
+	conflict := []solverRemoval{{1, 1}, {0, 1}}
+	var testcases = []struct {
+		name     string
+		slots    []solverSlot
+		expected []solverRemoval
+	}{
+		{"no rule", []solverSlot{slot(0, 0, 0), slot(1, 0, 0)}, nil},
+		{"days", []solverSlot{slot(0, 8, 0), slot(1, 8, 0)}, conflict},
+		{"days boundary", []solverSlot{slot(0, 7, 0), slot(1, 7, 0)}, nil},
+		{"meetings", []solverSlot{slot(0, 0, 1), slot(1, 0, 1)}, conflict},
+		{"stricter rule of earlier slot", []solverSlot{slot(0, 0, 2), slot(2, 0, 0)}, conflict},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			// Act
+			removals := restConflict(testcase.slots, []uint{1, 1})
+			// Assert
+			if !reflect.DeepEqual(removals, testcase.expected) {
+				t.Errorf("expected removals %v, got %v", testcase.expected, removals)
+			}
+		})
+	}
+}
+
+func TestRelationConflict(t *testing.T) {
+	var testcases = []struct {
+		name     string
+		relation dbModel.PersonRelation
+		assigned []uint
+		before   map[uint]map[uint]bool
+		expected []solverRemoval
+	}{
+		{
+			"apart removes later slot first",
+			dbModel.PersonRelation{PersonID: 1, RelatedPersonID: 2, Type: dbModel.RelationApart},
+			[]uint{1, 2},
+			nil,
+			[]solverRemoval{{1, 2}, {0, 1}},
+		},
+		{
+			"requires without related person",
+			dbModel.PersonRelation{PersonID: 1, RelatedPersonID: 2, Type: dbModel.RelationRequires},
+			[]uint{1, 3},
+			nil,
+			[]solverRemoval{{0, 1}},
+		},
+		{
+			"requires with related person",
+			dbModel.PersonRelation{PersonID: 1, RelatedPersonID: 2, Type: dbModel.RelationRequires},
+			[]uint{1, 2},
+			nil,
+			nil,
+		},
+		{
+			"requires with related person assigned before",
+			dbModel.PersonRelation{PersonID: 1, RelatedPersonID: 2, Type: dbModel.RelationRequires},
+			[]uint{1, 3},
+			map[uint]map[uint]bool{1: {2: true}},
+			nil,
+		},
+		{
+			"together without partner",
+			dbModel.PersonRelation{PersonID: 1, RelatedPersonID: 2, Type: dbModel.RelationTogether},
+			[]uint{3, 2},
+			nil,
+			[]solverRemoval{{1, 2}},
+		},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			// Prepare
+			slots := []solverSlot{
+				{plan: dbModel.Plan{MeetingID: 1, TaskDetailID: 1}},
+				{plan: dbModel.Plan{MeetingID: 1, TaskDetailID: 2}},
+			}
+			// Act
+			removals := relationConflict(slots, testcase.assigned, testcase.before, []dbModel.PersonRelation{testcase.relation})
+			// Assert
+			if !reflect.DeepEqual(removals, testcase.expected) {
+				t.Errorf("expected removals %v, got %v", testcase.expected, removals)
+			}
+		})
+	}
+}
@@ -0,0 +1,167 @@
+package plan
+
+import (
+	"mpt_data/models/apimodel"
+	dbModel "mpt_data/models/dbmodel"
+	generalmodel "mpt_data/models/general"
+
+	"gorm.io/gorm"
+)
+
+// GetAssignmentStats loads for every person and every task detail the person is qualified for
+// the number of assignments, absences and eligible meetings in the specified period.
+// Ordered by task detail and person, an empty list if nobody is qualified for a task detail
+func GetAssignmentStats(db *gorm.DB, period generalmodel.Period) (stats []apimodel.AssignmentStat, err error) {
+	var rows []struct {
+		PersonID     uint
+		TaskDetailID uint
+		Assignments  uint
+	}
+	if err :=
+		db.Table("people p").
+			Select("p.id as person_id, td.id as task_detail_id, COALESCE(t_count.task_count, 0) as assignments").
+			// load task of person
+			Joins("JOIN person_tasks pt ON p.id = pt.person_id").
+			// load allowed tasks
+			Joins("JOIN task_details td ON td.id = pt.task_detail_id").
+			Joins(tasksInPeriod, period.StartDate, period.EndDate).
+			Where("p.deleted_at IS NULL").
+			Where("pt.deleted_at IS NULL").
+			Where("td.deleted_at IS NULL").
+			Order("td.id").
+			Order("p.id").
+			Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return []apimodel.AssignmentStat{}, nil
+	}
+
+	var personIDs, taskDetailIDs []uint
+	for _, row := range rows {
+		personIDs = append(personIDs, row.PersonID)
+		taskDetailIDs = append(taskDetailIDs, row.TaskDetailID)
+	}
+
+	var people []dbModel.Person
+	if err := db.Where("id IN (?)", personIDs).Find(&people).Error; err != nil {
+		return nil, err
+	}
+	displayNames := make([]*dbModel.Person, 0, len(people))
+	for i := range people {
+		displayNames = append(displayNames, &people[i])
+	}
+	dbModel.SetDisplayNames(displayNames...)
+	personByID := make(map[uint]dbModel.Person, len(people))
+	for _, person := range people {
+		personByID[person.ID] = person
+	}
+
+	var taskDetails []dbModel.TaskDetail
+	if err := db.Preload("Task").Where("id IN (?)", taskDetailIDs).Find(&taskDetails).Error; err != nil {
+		return nil, err
+	}
+	taskDetailByID := make(map[uint]dbModel.TaskDetail, len(taskDetails))
+	for _, taskDetail := range taskDetails {
+		taskDetailByID[taskDetail.ID] = taskDetail
+	}
+
+	pairs := make([]personTask, 0, len(rows))
+	for _, row := range rows {
+		pairs = append(pairs, personTaskOf(row.PersonID, row.TaskDetailID))
+	}
+	absences, eligible, err := countAbsences(db, period, personByID, pairs)
+	if err != nil {
+		return nil, err
+	}
+
+	total := make(map[uint]uint)
+	qualified := make(map[uint]uint)
+	for _, row := range rows {
+		total[row.TaskDetailID] += row.Assignments
+		qualified[row.TaskDetailID]++
+	}
+
+	for _, row := range rows {
+		stat := apimodel.AssignmentStat{
+			Person:           personByID[row.PersonID],
+			TaskDetail:       taskDetailByID[row.TaskDetailID],
+			Assignments:      row.Assignments,
+			Absences:         absences[personTaskOf(row.PersonID, row.TaskDetailID)],
+			EligibleMeetings: eligible[personTaskOf(row.PersonID, row.TaskDetailID)],
+		}
+		if stat.EligibleMeetings != 0 {
+			stat.Share = float64(stat.Assignments) / float64(stat.EligibleMeetings)
+		}
+		mean := float64(total[row.TaskDetailID]) / float64(qualified[row.TaskDetailID])
+		stat.Deviation = float64(stat.Assignments) - mean
+		stats = append(stats, stat)
+	}
+
+	return stats, nil
+}
+
+// countAbsences counts per person and task detail the meetings without tag in period, which need the task detail
+// and at which the person is active. Of these meetings absences holds the ones, at which the person is absent,
+// recurring absent or in an absence period, eligible holds all others
+func countAbsences(db *gorm.DB, period generalmodel.Period, people map[uint]dbModel.Person, pairs []personTask) (absences, eligible map[personTask]uint, err error) {
+	var meetings []dbModel.Meeting
+	if err :=
+		db.Where("date between ? and ?", period.StartDate, period.EndDate).
+			Where("tag_id IS NULL OR tag_id = 0").
+			Find(&meetings).Error; err != nil {
+		return nil, nil, err
+	}
+	absences, eligible = make(map[personTask]uint), make(map[personTask]uint)
+	if len(meetings) == 0 {
+		return absences, eligible, nil
+	}
+	meetingIDs := make([]uint, 0, len(meetings))
+	for _, meeting := range meetings {
+		meetingIDs = append(meetingIDs, meeting.ID)
+	}
+	personIDs := make([]uint, 0, len(people))
+	for id := range people {
+		personIDs = append(personIDs, id)
+	}
+
+	required, err := loadMeetingTasks(db)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var personAbsences []dbModel.PersonAbsence
+	if err := db.Where("meeting_id IN (?)", meetingIDs).Where("person_id IN (?)", personIDs).Find(&personAbsences).Error; err != nil {
+		return nil, nil, err
+	}
+	type personMeeting struct {
+		personID, meetingID uint
+	}
+	absent := make(map[personMeeting]bool, len(personAbsences))
+	for _, absence := range personAbsences {
+		absent[personMeeting{absence.PersonID, absence.MeetingID}] = true
+	}
+
+	absentRules, err := loadAbsences(db, personIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, pair := range pairs {
+		person := people[pair.personID]
+		for _, meeting := range meetings {
+			if !required.requires(meeting, pair.taskDetailID) || !person.IsActive(meeting.Date) {
+				continue
+			}
+			if absent[personMeeting{pair.personID, meeting.ID}] ||
+				absentRules.recurs(pair.personID, meeting.Date) ||
+				absentRules.inPeriod(pair.personID, meeting.Date) {
+				absences[pair]++
+			} else {
+				eligible[pair]++
+			}
+		}
+	}
+
+	return absences, eligible, nil
+}
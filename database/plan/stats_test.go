@@ -0,0 +1,152 @@
+package plan
+
+import (
+	"math"
+	"mpt_data/database"
+	dbModel "mpt_data/models/dbmodel"
+	generalmodel "mpt_data/models/general"
+	"testing"
+	"time"
+)
+
+func TestGetAssignmentStats(t *testing.T) {
+	// Prepare
+	db := database.DB.Begin()
+	t.Cleanup(func() {
+		db.Rollback()
+	})
+	task := dbModel.Task{Descr: "StatsTask"}
+	if err := db.Create(&task).Error; err != nil {
+		t.Fatalf("test preparation failed: %v", err)
+	}
+	detail := dbModel.TaskDetail{Descr: "StatsDetail", TaskID: task.ID}
+	if err := db.Create(&detail).Error; err != nil {
+		t.Fatalf("test preparation failed: %v", err)
+	}
+	meetingType := dbModel.MeetingType{Descr: "StatsType"}
+	if err := db.Create(&meetingType).Error; err != nil {
+		t.Fatalf("test preparation failed: %v", err)
+	}
+	activeUntil := time.Date(2003, 1, 10, 0, 0, 0, 0, time.UTC)
+	people := []dbModel.Person{
+		{GivenName: "Anna", LastName: "Stats"},
+		{GivenName: "Ben", LastName: "Stats"},
+		{GivenName: "Cleo", LastName: "Stats"},
+		{GivenName: "Dana", LastName: "Stats", ActiveUntil: &activeUntil},
+	}
+	meetings := []dbModel.Meeting{
+		{Date: time.Date(2003, 1, 5, 0, 0, 0, 0, time.UTC)},
+		{Date: time.Date(2003, 1, 12, 0, 0, 0, 0, time.UTC)},
+		{Date: time.Date(2003, 1, 15, 0, 0, 0, 0, time.UTC)},
+		// the meeting type does not need the task detail
+		{Date: time.Date(2003, 1, 19, 0, 0, 0, 0, time.UTC), MeetingTypeID: &meetingType.ID},
+	}
+	if err := db.Create(&people).Error; err != nil {
+		t.Fatalf("test preparation failed: %v", err)
+	}
+	if err := db.Create(&meetings).Error; err != nil {
+		t.Fatalf("test preparation failed: %v", err)
+	}
+	for _, person := range people {
+		if err := db.Create(&dbModel.PersonTask{PersonID: person.ID, TaskDetailID: detail.ID}).Error; err != nil {
+			t.Fatalf("test preparation failed: %v", err)
+		}
+	}
+	plans := []dbModel.Plan{
+		{PersonID: people[0].ID, MeetingID: meetings[0].ID, TaskDetailID: detail.ID},
+		{PersonID: people[0].ID, MeetingID: meetings[1].ID, TaskDetailID: detail.ID},
+		{PersonID: people[1].ID, MeetingID: meetings[2].ID, TaskDetailID: detail.ID},
+	}
+	if err := db.Create(&plans).Error; err != nil {
+		t.Fatalf("test preparation failed: %v", err)
+	}
+	if err := db.Create(&dbModel.PersonAbsence{PersonID: people[2].ID, MeetingID: meetings[0].ID}).Error; err != nil {
+		t.Fatalf("test preparation failed: %v", err)
+	}
+	if err := db.Create(&dbModel.PersonRecurringAbsence{PersonID: people[1].ID, Rule: "FREQ=WEEKLY;BYDAY=SU"}).Error; err != nil {
+		t.Fatalf("test preparation failed: %v", err)
+	}
+	period := generalmodel.Period{
+		StartDate: time.Date(2003, 1, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2003, 1, 31, 0, 0, 0, 0, time.UTC),
+	}
+
+	var testcases = []struct {
+		name        string
+		personID    uint
+		assignments uint
+		eligible    uint
+		share       float64
+		deviation   float64
+		absences    uint
+	}{
+		{"above mean", people[0].ID, 2, 3, 2.0 / 3.0, 1.25, 0},
+		{"recurring absent", people[1].ID, 1, 1, 1, 0.25, 2},
+		{"absent", people[2].ID, 0, 2, 0, -0.75, 1},
+		{"inactive", people[3].ID, 0, 1, 0, -0.75, 0},
+	}
+
+	// Act
+	stats, err := GetAssignmentStats(db, period)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			// Assert
+			found := false
+			for _, stat := range stats {
+				if stat.Person.ID != testcase.personID || stat.TaskDetail.ID != detail.ID {
+					continue
+				}
+				found = true
+				if stat.Assignments != testcase.assignments {
+					t.Errorf("expected %d assignments, got %d", testcase.assignments, stat.Assignments)
+				}
+				if stat.EligibleMeetings != testcase.eligible {
+					t.Errorf("expected %d eligible meetings, got %d", testcase.eligible, stat.EligibleMeetings)
+				}
+				if math.Abs(stat.Share-testcase.share) > 1e-9 {
+					t.Errorf("expected share %f, got %f", testcase.share, stat.Share)
+				}
+				if math.Abs(stat.Deviation-testcase.deviation) > 1e-9 {
+					t.Errorf("expected deviation %f, got %f", testcase.deviation, stat.Deviation)
+				}
+				if stat.Absences != testcase.absences {
+					t.Errorf("expected %d absences, got %d", testcase.absences, stat.Absences)
+				}
+				if stat.Person.DisplayName == "" {
+					t.Errorf("expected display name of person %d", testcase.personID)
+				}
+			}
+			if !found {
+				t.Errorf("expected statistic of person %d", testcase.personID)
+			}
+		})
+	}
+}
+
+func TestGetAssignmentStatsWithoutPeople(t *testing.T) {
+	// Prepare
+	db := database.DB.Begin()
+	t.Cleanup(func() {
+		db.Rollback()
+	})
+	if err := db.Exec("DELETE FROM person_tasks").Error; err != nil {
+		t.Fatalf("test preparation failed: %v", err)
+	}
+	period := generalmodel.Period{
+		StartDate: time.Date(2003, 1, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2003, 1, 31, 0, 0, 0, 0, time.UTC),
+	}
+	// Act
+	stats, err := GetAssignmentStats(db, period)
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if stats == nil || len(stats) != 0 {
+		t.Errorf("expected empty statistics, got %v", stats)
+	}
+}
@@ -0,0 +1,182 @@
+package plan
+
+import (
+	"math"
+	"math/rand"
+	"mpt_data/helper/config"
+	"mpt_data/helper/errors"
+	dbModel "mpt_data/models/dbmodel"
+	generalmodel "mpt_data/models/general"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// Names of the available assignment strategies
+const (
+	StrategyLeastLoaded    = "leastloaded"
+	StrategyRoundRobin     = "roundrobin"
+	StrategyWeightedRandom = "weightedrandom"
+)
+
+// AssignmentStrategy decides which of the available people will be assigned to a plan element
+type AssignmentStrategy interface {
+	// Select returns the person that should be assigned to plan.
+	// candidates are all available people, ordered by least entries in period first.
+	// Returns nil if nobody should be assigned
+	Select(db *gorm.DB, plan dbModel.Plan, period generalmodel.Period, candidates []dbModel.Person) (*dbModel.Person, error)
+}
+
+// GetStrategy returns the AssignmentStrategy with the given name.
+// If name is empty, the strategy from config is used, if this is also empty least loaded is used
+func GetStrategy(name string) (AssignmentStrategy, error) {
+	if name == "" {
+		name = config.Config.Plan.Strategy
+	}
+
+	switch strings.ToLower(name) {
+	case "", StrategyLeastLoaded:
+		return leastLoaded{}, nil
+	case StrategyRoundRobin:
+		return roundRobin{}, nil
+	case StrategyWeightedRandom:
+		return weightedRandom{}, nil
+	default:
+		return nil, errors.ErrUnknownStrategy
+	}
+}
+
+// leastLoaded selects the person with the least entries in period and history, then with the least entries for the task
+type leastLoaded struct{}
+
+func (leastLoaded) Select(_ *gorm.DB, _ dbModel.Plan, _ generalmodel.Period, candidates []dbModel.Person) (*dbModel.Person, error) {
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+	return &candidates[0], nil
+}
+
+// roundRobin rotates strictly through all people of a TaskDetail.
+// The person who did not serve the task for the longest time is selected, people who never served the task first
+type roundRobin struct{}
+
+func (roundRobin) Select(db *gorm.DB, plan dbModel.Plan, _ generalmodel.Period, candidates []dbModel.Person) (*dbModel.Person, error) {
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]uint, 0, len(candidates))
+	for _, candidate := range candidates {
+		ids = append(ids, candidate.ID)
+	}
+
+	var lastServed []struct {
+		PersonID uint
+		LastDate string
+	}
+	if err :=
+		db.Table("plans").
+			Joins("JOIN meetings m ON m.id = plans.meeting_id").
+			Where("plans.task_detail_id = ?", plan.TaskDetailID).
+			Where("plans.person_id IN (?)", ids).
+			Group("plans.person_id").
+			Select("plans.person_id, MAX(m.date) as last_date").
+			Scan(&lastServed).Error; err != nil {
+		return nil, err
+	}
+
+	lastDates := make(map[uint]string, len(lastServed))
+	for _, entry := range lastServed {
+		lastDates[entry.PersonID] = entry.LastDate
+	}
+
+	selected := 0
+	for i := 1; i < len(candidates); i++ {
+		if servedBefore(lastDates, candidates[i].ID, candidates[selected].ID) {
+			selected = i
+		}
+	}
+
+	return &candidates[selected], nil
+}
+
+// servedBefore reports if person a is due before person b.
+// A person who never served is due first, ties are decided by the lower id
+func servedBefore(lastDates map[uint]string, a, b uint) bool {
+	lastA, servedA := lastDates[a]
+	lastB, servedB := lastDates[b]
+	switch {
+	case servedA != servedB:
+		return !servedA
+	case lastA != lastB:
+		return lastA < lastB
+	default:
+		return a < b
+	}
+}
+
+// weightedRandom selects a random person, people with less entries in period and history have a higher chance to be selected.
+// Preferences for the task or weekday of plan raise the chance, dislikes lower it
+// The top level functions of math/rand are used, as they are safe for concurrent plan creation
+type weightedRandom struct{}
+
+func (weightedRandom) Select(db *gorm.DB, plan dbModel.Plan, period generalmodel.Period, candidates []dbModel.Person) (*dbModel.Person, error) {
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]uint, 0, len(candidates))
+	for _, candidate := range candidates {
+		ids = append(ids, candidate.ID)
+	}
+	load, err := assignmentLoad(db, period, ids)
+	if err != nil {
+		return nil, err
+	}
+	preferences, err := loadPreferences(db, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	// a preference counts at least half an assignment, so it has an effect without a configured weight
+	weight := math.Max(0.5, preferenceWeight())
+	weights := make([]float64, len(candidates))
+	var sum float64
+	for i, candidate := range candidates {
+		rank := load[candidate.ID] - weight*float64(preferenceScore(preferences[candidate.ID], plan))
+		weights[i] = 1 / (1 + math.Max(0, rank))
+		sum += weights[i]
+	}
+
+	pick := rand.Float64() * sum
+	for i, weight := range weights {
+		if pick < weight {
+			return &candidates[i], nil
+		}
+		pick -= weight
+	}
+	return &candidates[len(candidates)-1], nil
+}
+
+// countAssignments counts the plan entries of the given people in period
+func countAssignments(db *gorm.DB, period generalmodel.Period, personIDs []uint) (map[uint]int64, error) {
+	var entries []struct {
+		PersonID uint
+		Count    int64
+	}
+	if err :=
+		db.Table("plans").
+			Where("person_id IN (?)", personIDs).
+			Where("meeting_id IN (?)", db.Table("meetings").Where("date between ? and ?", period.StartDate, period.EndDate).Select("id")).
+			Group("person_id").
+			Select("person_id, COUNT(*) as count").
+			Scan(&entries).Error; err != nil {
+		return nil, err
+	}
+
+	counts := make(map[uint]int64, len(entries))
+	for _, entry := range entries {
+		counts[entry.PersonID] = entry.Count
+	}
+	return counts, nil
+}
@@ -0,0 +1,93 @@
+package plan
+
+import (
+	"mpt_data/helper/errors"
+	dbModel "mpt_data/models/dbmodel"
+	generalmodel "mpt_data/models/general"
+	"testing"
+)
+
+func TestGetStrategy(t *testing.T) {
+	var testcases = []struct {
+		name     string
+		strategy string
+		expected AssignmentStrategy
+		err      error
+	}{
+		{"least loaded", StrategyLeastLoaded, leastLoaded{}, nil},
+		{"round robin", StrategyRoundRobin, roundRobin{}, nil},
+		{"weighted random", StrategyWeightedRandom, weightedRandom{}, nil},
+		{"case insensitive", "RoundRobin", roundRobin{}, nil},
+		{"unknown", "bla", nil, errors.ErrUnknownStrategy},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			// Act
+			strategy, err := GetStrategy(testcase.strategy)
+			// Assert
+			if err != testcase.err {
+				t.Errorf("expected %v, got %v", testcase.err, err)
+			}
+			if strategy != testcase.expected {
+				t.Errorf("expected %T, got %T", testcase.expected, strategy)
+			}
+		})
+	}
+}
+
+func TestLeastLoaded(t *testing.T) {
+	t.Run("first candidate", func(t *testing.T) {
+		// Prepare
+		candidates := []dbModel.Person{{ID: 3}, {ID: 1}}
+		// Act
+		person, err := leastLoaded{}.Select(nil, dbModel.Plan{}, generalmodel.Period{}, candidates)
+		// Assert
+		if err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+		if person == nil || person.ID != 3 {
+			t.Errorf("expected person 3, got %v", person)
+		}
+	})
+
+	t.Run("no candidates", func(t *testing.T) {
+		// Act
+		person, err := leastLoaded{}.Select(nil, dbModel.Plan{}, generalmodel.Period{}, nil)
+		// Assert
+		if err != nil || person != nil {
+			t.Errorf("expected no person and no error, got %v, %v", person, err)
+		}
+	})
+}
+
+func TestServedBefore(t *testing.T) {
+	lastDates := map[uint]string{
+		1: "2024-01-07 00:00:00+00:00",
+		2: "2024-01-14 00:00:00+00:00",
+		4: "2024-01-07 00:00:00+00:00",
+	}
+	var testcases = []struct {
+		name     string
+		a, b     uint
+		expected bool
+	}{
+		{"never served first", 3, 1, true},
+		{"served after never served", 1, 3, false},
+		{"earlier date first", 1, 2, true},
+		{"later date last", 2, 1, false},
+		{"same date lower id", 1, 4, true},
+		{"both never served lower id", 3, 5, true},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			// Act
+			result := servedBefore(lastDates, testcase.a, testcase.b)
+			// Assert
+			if result != testcase.expected {
+				t.Errorf("expected %t, got %t", testcase.expected, result)
+			}
+		})
+	}
+}
@@ -0,0 +1,313 @@
+package plan
+
+import (
+	"fmt"
+	"mpt_data/helper/config"
+	"mpt_data/helper/errors"
+	dbModel "mpt_data/models/dbmodel"
+	generalmodel "mpt_data/models/general"
+	"strings"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// Rules to approve a swap on acceptance
+const (
+	SwapApproveNever    = "never"
+	SwapApproveAlways   = "always"
+	SwapApproveSameTask = "sametask"
+)
+
+// GetSwapRequests loads all swap requests, if state is set only swap requests in this state
+func GetSwapRequests(db *gorm.DB, state string) (swaps []dbModel.SwapRequest, err error) {
+	query := preloadSwap(db)
+	if state != "" {
+		query = query.Where("state = ?", state)
+	}
+	if err := query.Order("id").Find(&swaps).Error; err != nil {
+		return nil, err
+	}
+	return swaps, nil
+}
+
+// OfferSwap creates a swap request for the plan element, offered by the person assigned to it
+func OfferSwap(db *gorm.DB, planID uint) (swap dbModel.SwapRequest, err error) {
+	var plan dbModel.Plan
+	if err := db.First(&plan, planID).Error; err != nil {
+		return swap, err
+	}
+	if plan.PersonID == 0 || plan.TaskDetailID == 0 {
+		return swap, errors.ErrSwapInvalid
+	}
+
+	var open int64
+	if err :=
+		db.Model(&dbModel.SwapRequest{}).
+			Where("plan_id = ?", planID).
+			Where("state IN (?)", []string{dbModel.SwapOffered, dbModel.SwapAccepted}).
+			Count(&open).Error; err != nil {
+		return swap, err
+	}
+	if open != 0 {
+		return swap, errors.ErrSwapAlreadyOffered
+	}
+
+	swap = dbModel.SwapRequest{PlanID: plan.ID, PersonID: plan.PersonID, State: dbModel.SwapOffered}
+	if err := db.Create(&swap).Error; err != nil {
+		zap.L().Error(generalmodel.DBSaveDataFailed, zap.Error(err))
+		return swap, err
+	}
+	return getSwap(db, swap.ID)
+}
+
+// AcceptSwap accepts an offered swap with the plan element of another person.
+// Both people must be qualified and available for the meeting of each other.
+// The swap is approved directly, if the configured rule allows it and no meeting is in a published plan period
+func AcceptSwap(db *gorm.DB, id uint, planID uint, userID *uint) (swap dbModel.SwapRequest, err error) {
+	if swap, err = getSwap(db, id); err != nil {
+		return swap, err
+	}
+	if swap.State != dbModel.SwapOffered {
+		return swap, errors.ErrSwapState
+	}
+
+	var accepted dbModel.Plan
+	if err := db.Preload("Meeting").First(&accepted, planID).Error; err != nil {
+		return swap, err
+	}
+	if accepted.PersonID == 0 || accepted.TaskDetailID == 0 {
+		return swap, errors.ErrSwapInvalid
+	}
+	swap.AcceptedPlanID = &accepted.ID
+	swap.AcceptedPersonID = &accepted.PersonID
+	swap.AcceptedPlan = &accepted
+
+	if err := checkSwap(db, swap); err != nil {
+		return swap, err
+	}
+
+	swap.State = dbModel.SwapAccepted
+	if err :=
+		db.Model(&dbModel.SwapRequest{}).
+			Where("id = ?", swap.ID).
+			Updates(map[string]interface{}{
+				"accepted_plan_id":   accepted.ID,
+				"accepted_person_id": accepted.PersonID,
+				"state":              swap.State,
+			}).Error; err != nil {
+		zap.L().Error(generalmodel.DBUpdateDataFailed, zap.Error(err))
+		return swap, err
+	}
+
+	approve, err := autoApprove(db, swap)
+	if err != nil {
+		return swap, err
+	}
+	if approve {
+		return ApproveSwap(db, id, userID, false)
+	}
+	return getSwap(db, id)
+}
+
+// ApproveSwap swaps the people of both plan elements of an accepted swap.
+// The swap is checked again, because the plan may have changed since the acceptance.
+// If a meeting of the swap is in a published plan period, the swap is only approved with force.
+// Both changes are recorded as PlanRevision with the approving user
+func ApproveSwap(db *gorm.DB, id uint, userID *uint, force bool) (swap dbModel.SwapRequest, err error) {
+	if swap, err = getSwap(db, id); err != nil {
+		return swap, err
+	}
+	if swap.State != dbModel.SwapAccepted {
+		return swap, errors.ErrSwapState
+	}
+	if err := checkSwap(db, swap); err != nil {
+		return swap, err
+	}
+	for _, meeting := range []dbModel.Meeting{swap.Plan.Meeting, swap.AcceptedPlan.Meeting} {
+		if err := checkNotPublished(db, generalmodel.Period{StartDate: meeting.Date, EndDate: meeting.Date}, force); err != nil {
+			return swap, err
+		}
+	}
+
+	reason := fmt.Sprintf("swap request %d", swap.ID)
+	// both plan elements are changed or none
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&dbModel.Plan{}).Where("id = ?", swap.PlanID).Update("person_id", *swap.AcceptedPersonID).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&dbModel.Plan{}).Where("id = ?", *swap.AcceptedPlanID).Update("person_id", swap.PersonID).Error; err != nil {
+			return err
+		}
+		if err := recordRevision(tx, swap.PlanID, swap.PersonID, *swap.AcceptedPersonID, userID, reason); err != nil {
+			return err
+		}
+		if err := recordRevision(tx, *swap.AcceptedPlanID, *swap.AcceptedPersonID, swap.PersonID, userID, reason); err != nil {
+			return err
+		}
+		for _, meeting := range []dbModel.Meeting{swap.Plan.Meeting, swap.AcceptedPlan.Meeting} {
+			if err := markPDFChanged(tx, generalmodel.Period{StartDate: meeting.Date, EndDate: meeting.Date}); err != nil {
+				return err
+			}
+		}
+		return tx.Model(&dbModel.SwapRequest{}).Where("id = ?", swap.ID).Update("state", dbModel.SwapApproved).Error
+	}); err != nil {
+		zap.L().Error(generalmodel.DBUpdateDataFailed, zap.Error(err))
+		return swap, err
+	}
+	return getSwap(db, id)
+}
+
+// RejectSwap rejects an offered or accepted swap, the plan is not changed
+func RejectSwap(db *gorm.DB, id uint) (swap dbModel.SwapRequest, err error) {
+	if swap, err = getSwap(db, id); err != nil {
+		return swap, err
+	}
+	if swap.State != dbModel.SwapOffered && swap.State != dbModel.SwapAccepted {
+		return swap, errors.ErrSwapState
+	}
+	if err := db.Model(&dbModel.SwapRequest{}).Where("id = ?", swap.ID).Update("state", dbModel.SwapRejected).Error; err != nil {
+		zap.L().Error(generalmodel.DBUpdateDataFailed, zap.Error(err))
+		return swap, err
+	}
+	return getSwap(db, id)
+}
+
+func preloadSwap(db *gorm.DB) *gorm.DB {
+	return db.
+		Preload("Plan.Person").
+		Preload("Plan.Meeting").
+		Preload("Plan.TaskDetail").
+		Preload("AcceptedPlan.Person").
+		Preload("AcceptedPlan.Meeting").
+		Preload("AcceptedPlan.TaskDetail")
+}
+
+func getSwap(db *gorm.DB, id uint) (swap dbModel.SwapRequest, err error) {
+	err = preloadSwap(db).First(&swap, id).Error
+	return swap, err
+}
+
+// checkSwap checks if the people of both plan elements are unchanged, different,
+// qualified for the task of each other and available at the meeting of each other.
+// Each plan element after the swap must keep the caps, the rest interval and the relations of its new person,
+// as for a change by UpdatePlanElement
+func checkSwap(db *gorm.DB, swap dbModel.SwapRequest) error {
+	offered, accepted := swap.Plan, *swap.AcceptedPlan
+	if offered.PersonID != swap.PersonID || accepted.PersonID != *swap.AcceptedPersonID {
+		return errors.ErrSwapOutdated
+	}
+	if offered.PersonID == accepted.PersonID {
+		return errors.ErrSwapInvalid
+	}
+
+	for _, change := range []struct {
+		personID uint
+		plan     dbModel.Plan
+	}{
+		{accepted.PersonID, offered},
+		{offered.PersonID, accepted},
+	} {
+		var personTask dbModel.PersonTask
+		if err :=
+			db.Where("person_id = ?", change.personID).
+				Where("task_detail_id = ?", change.plan.TaskDetailID).
+				First(&personTask).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return errors.ErrTaskForPersonNotAllowed
+			}
+			return err
+		}
+
+		available, err := availableForSwap(db, change.personID, change.plan.Meeting, []uint{offered.ID, accepted.ID})
+		if err != nil {
+			return err
+		}
+		if !available {
+			return errors.ErrSwapNotAvailable
+		}
+
+		// the plan element after the swap, the person gives up the other one
+		swapped := dbModel.Plan{
+			ID:           change.plan.ID,
+			PersonID:     change.personID,
+			MeetingID:    change.plan.MeetingID,
+			Meeting:      change.plan.Meeting,
+			TaskDetailID: change.plan.TaskDetailID,
+		}
+		released := []uint{offered.ID, accepted.ID}
+		if err := checkAssignmentCaps(db, swapped, change.plan.Meeting, personTask, released); err != nil {
+			return err
+		}
+		if err := checkRest(db, swapped, released); err != nil {
+			return err
+		}
+		// at the same meeting the people of the meeting stay the same
+		if offered.MeetingID != accepted.MeetingID {
+			if err := checkRelations(db, swapped); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// availableForSwap reports if the person is active, neither absent at the meeting
+// nor assigned to it with a plan element other than the swapped ones
+func availableForSwap(db *gorm.DB, personID uint, meeting dbModel.Meeting, swapped []uint) (bool, error) {
+	var person dbModel.Person
+	if err := db.First(&person, personID).Error; err != nil {
+		return false, err
+	}
+	if !person.IsActive(meeting.Date) {
+		return false, nil
+	}
+	var absent int64
+	if err :=
+		db.Model(&dbModel.PersonAbsence{}).
+			Where("person_id = ?", personID).
+			Where("meeting_id = ?", meeting.ID).
+			Count(&absent).Error; err != nil {
+		return false, err
+	}
+	absences, err := loadAbsences(db, []uint{personID})
+	if err != nil {
+		return false, err
+	}
+	var assigned int64
+	if err :=
+		db.Model(&dbModel.Plan{}).
+			Where("person_id = ?", personID).
+			Where("meeting_id = ?", meeting.ID).
+			Not("id IN (?)", swapped).
+			Count(&assigned).Error; err != nil {
+		return false, err
+	}
+	return absent == 0 && !absences.recurs(personID, meeting.Date) && !absences.inPeriod(personID, meeting.Date) && assigned == 0, nil
+}
+
+// autoApprove reports if the configured rule approves the accepted swap without a planner.
+// Swaps changing a published plan period are never approved automatically
+func autoApprove(db *gorm.DB, swap dbModel.SwapRequest) (bool, error) {
+	var approve bool
+	switch strings.ToLower(config.Config.Plan.Swap.AutoApprove) {
+	case SwapApproveAlways:
+		approve = true
+	case SwapApproveSameTask:
+		approve = swap.Plan.TaskDetailID == swap.AcceptedPlan.TaskDetailID
+	}
+	if !approve {
+		return false, nil
+	}
+
+	for _, meeting := range []dbModel.Meeting{swap.Plan.Meeting, swap.AcceptedPlan.Meeting} {
+		err := checkNotPublished(db, generalmodel.Period{StartDate: meeting.Date, EndDate: meeting.Date}, false)
+		if err == errors.ErrPlanPublished {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,279 @@
+package plan
+
+import (
+	"mpt_data/database"
+	"mpt_data/helper/config"
+	"mpt_data/helper/errors"
+	dbModel "mpt_data/models/dbmodel"
+	generalmodel "mpt_data/models/general"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func TestSwap(t *testing.T) {
+	// Prepare
+	db := database.DB.Begin()
+	autoApprove := config.Config.Plan.Swap.AutoApprove
+	t.Cleanup(func() {
+		db.Rollback()
+		config.Config.Plan.Swap.AutoApprove = autoApprove
+	})
+	task := dbModel.Task{Descr: "SwapTask"}
+	if err := db.Create(&task).Error; err != nil {
+		t.Fatalf("test preparation failed: %v", err)
+	}
+	details := []dbModel.TaskDetail{
+		{Descr: "SwapDetail", TaskID: task.ID},
+		{Descr: "SwapOther", TaskID: task.ID},
+	}
+	people := []dbModel.Person{
+		{GivenName: "Anna", LastName: "Swap"},
+		{GivenName: "Ben", LastName: "Swap"},
+		{GivenName: "Cleo", LastName: "Swap"},
+	}
+	meetings := []dbModel.Meeting{
+		{Date: time.Date(2005, 1, 2, 0, 0, 0, 0, time.UTC)},
+		{Date: time.Date(2005, 1, 9, 0, 0, 0, 0, time.UTC)},
+		{Date: time.Date(2005, 1, 16, 0, 0, 0, 0, time.UTC)},
+		{Date: time.Date(2005, 1, 23, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, value := range []interface{}{&details, &people, &meetings} {
+		if err := db.Create(value).Error; err != nil {
+			t.Fatalf("test preparation failed: %v", err)
+		}
+	}
+	anna, ben, cleo := people[0].ID, people[1].ID, people[2].ID
+	// Cleo is not qualified
+	for _, personID := range []uint{anna, ben} {
+		for _, detail := range details {
+			if err := db.Create(&dbModel.PersonTask{PersonID: personID, TaskDetailID: detail.ID}).Error; err != nil {
+				t.Fatalf("test preparation failed: %v", err)
+			}
+		}
+	}
+	plans := []dbModel.Plan{
+		{PersonID: anna, MeetingID: meetings[0].ID, TaskDetailID: details[0].ID},
+		{PersonID: ben, MeetingID: meetings[1].ID, TaskDetailID: details[0].ID},
+		{PersonID: cleo, MeetingID: meetings[1].ID, TaskDetailID: details[1].ID},
+		{PersonID: anna, MeetingID: meetings[3].ID, TaskDetailID: details[0].ID},
+		{PersonID: ben, MeetingID: meetings[2].ID, TaskDetailID: details[1].ID},
+	}
+	if err := db.Create(&plans).Error; err != nil {
+		t.Fatalf("test preparation failed: %v", err)
+	}
+	offered, accepted, unqualified, samePerson, otherTask := plans[0], plans[1], plans[2], plans[3], plans[4]
+
+	var testcases = []struct {
+		name        string
+		prepare     func(t *testing.T)
+		published   bool
+		acceptPlan  dbModel.Plan
+		autoApprove string
+		approve     bool
+		force       bool
+		err         error
+		state       string
+	}{
+		{
+			name:       "accepted",
+			acceptPlan: accepted,
+			state:      dbModel.SwapAccepted,
+		},
+		{
+			name:       "approved",
+			acceptPlan: accepted,
+			approve:    true,
+			state:      dbModel.SwapApproved,
+		},
+		{
+			name:        "auto approved",
+			acceptPlan:  accepted,
+			autoApprove: SwapApproveAlways,
+			state:       dbModel.SwapApproved,
+		},
+		{
+			name:        "auto approved for same task",
+			acceptPlan:  accepted,
+			autoApprove: SwapApproveSameTask,
+			state:       dbModel.SwapApproved,
+		},
+		{
+			name:        "not auto approved in published plan",
+			published:   true,
+			acceptPlan:  accepted,
+			autoApprove: SwapApproveAlways,
+			state:       dbModel.SwapAccepted,
+		},
+		{
+			name:       "approval in published plan",
+			published:  true,
+			acceptPlan: accepted,
+			approve:    true,
+			err:        errors.ErrPlanPublished,
+		},
+		{
+			name:       "forced approval in published plan",
+			published:  true,
+			acceptPlan: accepted,
+			approve:    true,
+			force:      true,
+			state:      dbModel.SwapApproved,
+		},
+		{
+			name:        "not auto approved for other task",
+			acceptPlan:  otherTask,
+			autoApprove: SwapApproveSameTask,
+			state:       dbModel.SwapAccepted,
+		},
+		{
+			name:       "not qualified",
+			acceptPlan: unqualified,
+			err:        errors.ErrTaskForPersonNotAllowed,
+		},
+		{
+			name:       "same person",
+			acceptPlan: samePerson,
+			err:        errors.ErrSwapInvalid,
+		},
+		{
+			name: "absent at approval",
+			prepare: func(t *testing.T) {
+				if err := db.Create(&dbModel.PersonAbsence{PersonID: ben, MeetingID: meetings[0].ID}).Error; err != nil {
+					t.Fatalf("test preparation failed: %v", err)
+				}
+			},
+			acceptPlan: accepted,
+			approve:    true,
+			err:        errors.ErrSwapNotAvailable,
+		},
+		{
+			name: "cap exceeded",
+			prepare: func(t *testing.T) {
+				// Ben keeps the other assignment in the month
+				if err := db.Table("people").Where("id = ?", ben).Update("max_assignments_month", 1).Error; err != nil {
+					t.Fatalf("test preparation failed: %v", err)
+				}
+			},
+			acceptPlan: accepted,
+			approve:    true,
+			err:        errors.ErrAssignmentCapExceeded,
+		},
+		{
+			name: "rest interval",
+			prepare: func(t *testing.T) {
+				// Anna serves two weeks after the accepted meeting
+				if err := db.Table("task_details").Where("id = ?", details[0].ID).Update("min_rest_days", 15).Error; err != nil {
+					t.Fatalf("test preparation failed: %v", err)
+				}
+			},
+			acceptPlan: accepted,
+			approve:    true,
+			err:        errors.ErrRestViolated,
+		},
+		{
+			name: "relation",
+			prepare: func(t *testing.T) {
+				// Cleo serves at the accepted meeting
+				if err := db.Create(&dbModel.PersonRelation{PersonID: anna, RelatedPersonID: cleo, Type: dbModel.RelationApart}).Error; err != nil {
+					t.Fatalf("test preparation failed: %v", err)
+				}
+			},
+			acceptPlan: accepted,
+			approve:    true,
+			err:        errors.ErrRelationViolated,
+		},
+		{
+			name: "outdated",
+			prepare: func(t *testing.T) {
+				if err := db.Model(&dbModel.Plan{}).Where("id = ?", accepted.ID).Update("person_id", cleo).Error; err != nil {
+					t.Fatalf("test preparation failed: %v", err)
+				}
+			},
+			acceptPlan: accepted,
+			approve:    true,
+			err:        errors.ErrSwapOutdated,
+		},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			db.SavePoint("beforeSwap")
+			defer db.RollbackTo("beforeSwap")
+			config.Config.Plan.Swap.AutoApprove = testcase.autoApprove
+			if testcase.published {
+				period := dbModel.PlanPeriod{Period: generalmodel.Period{StartDate: meetings[1].Date, EndDate: meetings[1].Date}}
+				if err := AddPlanPeriod(db, &period); err != nil {
+					t.Fatalf("test preparation failed: %v", err)
+				}
+				if _, err := UpdatePlanPeriodState(db, period.ID, dbModel.PlanPeriodPublished); err != nil {
+					t.Fatalf("test preparation failed: %v", err)
+				}
+			}
+
+			// Act
+			swap, err := OfferSwap(db, offered.ID)
+			if err != nil {
+				t.Fatalf("expected offer, got %v", err)
+			}
+			if _, err := OfferSwap(db, offered.ID); err != errors.ErrSwapAlreadyOffered {
+				t.Errorf("expected %v for second offer, got %v", errors.ErrSwapAlreadyOffered, err)
+			}
+			swap, err = AcceptSwap(db, swap.ID, testcase.acceptPlan.ID, nil)
+			if err == nil && testcase.prepare != nil {
+				testcase.prepare(t)
+			}
+			if err == nil && testcase.approve {
+				swap, err = ApproveSwap(db, swap.ID, nil, testcase.force)
+			}
+
+			// Assert
+			if err != testcase.err {
+				t.Fatalf("expected error %v, got %v", testcase.err, err)
+			}
+			if err != nil {
+				return
+			}
+			if swap.State != testcase.state {
+				t.Errorf("expected state %s, got %s", testcase.state, swap.State)
+			}
+			var offeredAfter, acceptedAfter dbModel.Plan
+			db.First(&offeredAfter, offered.ID)
+			db.First(&acceptedAfter, testcase.acceptPlan.ID)
+			swapped := offeredAfter.PersonID == testcase.acceptPlan.PersonID && acceptedAfter.PersonID == offered.PersonID
+			if swapped != (testcase.state == dbModel.SwapApproved) {
+				t.Errorf("expected swapped %t, got persons %d and %d", !swapped, offeredAfter.PersonID, acceptedAfter.PersonID)
+			}
+			var revisions int64
+			db.Model(&dbModel.PlanRevision{}).Where("plan_id IN (?)", []uint{offered.ID, testcase.acceptPlan.ID}).Count(&revisions)
+			if swapped && revisions != 2 {
+				t.Errorf("expected 2 revisions, got %d", revisions)
+			}
+			if _, err := ApproveSwap(db, swap.ID, nil, testcase.force); testcase.state == dbModel.SwapApproved && err != errors.ErrSwapState {
+				t.Errorf("expected %v for second approval, got %v", errors.ErrSwapState, err)
+			}
+		})
+	}
+
+	t.Run("reject", func(t *testing.T) {
+		db.SavePoint("beforeSwap")
+		defer db.RollbackTo("beforeSwap")
+		// Act
+		swap, err := OfferSwap(db, offered.ID)
+		if err != nil {
+			t.Fatalf("expected offer, got %v", err)
+		}
+		swap, err = RejectSwap(db, swap.ID)
+		// Assert
+		if err != nil || swap.State != dbModel.SwapRejected {
+			t.Errorf("expected rejected swap, got %s and %v", swap.State, err)
+		}
+		if _, err := AcceptSwap(db, swap.ID, accepted.ID, nil); err != errors.ErrSwapState {
+			t.Errorf("expected %v, got %v", errors.ErrSwapState, err)
+		}
+		if _, err := RejectSwap(db, 0xFFFFFF); err != gorm.ErrRecordNotFound {
+			t.Errorf("expected %v, got %v", gorm.ErrRecordNotFound, err)
+		}
+	})
+}
@@ -109,6 +109,28 @@ func TestAddTask(t *testing.T) {
 		}
 
 	})
+
+	t.Run("duplicate blind index", func(t *testing.T) {
+		// Prepare
+		tx := database.DB.Begin()
+		defer tx.Rollback()
+		task := &dbModel.Task{Descr: "Index"}
+		if err := AddTask(tx, task); err != nil {
+			t.Fatalf("test preparation failed: %v", err)
+		}
+
+		// Act
+		err := AddTask(tx, &dbModel.Task{Descr: " index "})
+		// the unique constraint holds without hooks as well
+		errIndex := tx.Exec("INSERT INTO tasks (descr, descr_index) VALUES (?, ?)", "copy", task.DescrIndex).Error
+		// Assert
+		if err != myerrors.ErrTaskAlreadyExists {
+			t.Errorf("expected %v, got %v", myerrors.ErrTaskAlreadyExists, err)
+		}
+		if errIndex == nil {
+			t.Errorf("expected unique constraint of descr_index to fail")
+		}
+	})
 }
 func TestUpdateTask(t *testing.T) {
 	var testcases = []struct {
@@ -0,0 +1,10 @@
+// Package calendar provides helpers for calendar days
+package calendar
+
+import "time"
+
+// DayOf returns the date of t at midnight in UTC, as days of absences, series, recurrence rules and holidays are compared
+func DayOf(t time.Time) time.Time {
+	year, month, day := t.UTC().Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,30 @@
+package calendar
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDayOf(t *testing.T) {
+	berlin := time.FixedZone("CET", 3600)
+	var testcases = []struct {
+		name     string
+		time     time.Time
+		expected time.Time
+	}{
+		{"midnight", time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC), time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC)},
+		{"during the day", time.Date(2024, 3, 10, 18, 30, 0, 0, time.UTC), time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC)},
+		{"other zone", time.Date(2024, 3, 10, 0, 30, 0, 0, berlin), time.Date(2024, 3, 9, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			// Act
+			result := DayOf(testcase.time)
+			// Assert
+			if !result.Equal(testcase.expected) || result.Location() != time.UTC {
+				t.Errorf("expected %v, got %v", testcase.expected, result)
+			}
+		})
+	}
+}
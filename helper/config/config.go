@@ -25,10 +25,38 @@ type config struct {
 		JWTKey                 string
 		UseSwagger             bool
 		AuthenticationRequired bool
+		// roles of users, who see the contact data of people
+		ContactRoles []string
 	}
 	PDF struct {
 		Path string
 	}
+	Plan struct {
+		Strategy        string
+		MinRestDays     uint
+		MinRestMeetings uint
+		ConflictCheck   struct {
+			Schedule string
+			Months   uint
+		}
+		History struct {
+			Months uint
+			Decay  float64
+		}
+		Swap struct {
+			AutoApprove string
+		}
+		PreferenceWeight float64
+	}
+	Holiday struct {
+		Region string
+		ICS    string
+		Mode   string
+	}
+	Names struct {
+		// format of names, which are not unique
+		Duplicate string
+	}
 
 	SECRETS struct {
 		Use             bool
@@ -85,6 +113,7 @@ func LoadConfig() {
 	Config.Database.Path = os.ExpandEnv(Config.Database.Path)
 	Config.Log.Path = os.ExpandEnv(Config.Log.Path)
 	Config.PDF.Path = os.ExpandEnv(Config.PDF.Path)
+	Config.Holiday.ICS = os.ExpandEnv(Config.Holiday.ICS)
 
 	createDirIfNotExist(Config.Database.Path)
 	createDirIfNotExist(Config.Log.Path)
@@ -4,12 +4,14 @@ package helper
 import (
 	"crypto/aes"
 	"crypto/cipher"
+	"crypto/hmac"
 	"crypto/rand"
 	"crypto/sha256"
 	"encoding/base64"
 	"errors"
 	"io"
 	"mpt_data/helper/config"
+	"strings"
 )
 
 func createCipherBlock() (cipher.Block, error) {
@@ -114,6 +116,18 @@ func DecryptDataDeterministicFromBase64(data string) ([]byte, error) {
 	return DecryptData(data)
 }
 
+// BlindIndex returns a keyed HMAC of data, to search and compare encrypted data in the database.
+// Data is compared case insensitive and without surrounding spaces
+func BlindIndex(data string) string {
+	// the key of the index is derived from the encryption key, so the encryption key is never used twice
+	key := hmac.New(sha256.New, config.Config.GetDBEncryptionKey())
+	key.Write([]byte("blind index"))
+
+	mac := hmac.New(sha256.New, key.Sum(nil))
+	mac.Write([]byte(strings.ToLower(strings.TrimSpace(data))))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
 func padData(data []byte, blockSize int) []byte {
 	padding := blockSize - len(data)%blockSize
 	padded := make([]byte, len(data)+padding)
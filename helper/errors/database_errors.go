@@ -7,9 +7,27 @@ import (
 
 var ErrUserAlreadyExists = errors.New("user already exists")
 
+// User-Model errors
+var (
+	ErrInvalidRole         = errors.New("role of user is invalid")
+	ErrUserNotLinked       = errors.New("user is not linked to a person")
+	ErrPersonAlreadyLinked = errors.New("person is already linked to another user")
+)
+
 // Person-Model errors
 var (
-	ErrPersonMissingName = errors.New("givenname or lastname missing")
+	ErrPersonMissingName      = errors.New("givenname or lastname missing")
+	ErrPersonInPlan           = errors.New("person is assigned in a plan, set the person inactive instead")
+	ErrPersonInvalidEmail     = errors.New("email of person is invalid")
+	ErrPersonInvalidPhone     = errors.New("phone of person is invalid")
+	ErrPersonInvalidActivity  = errors.New("active days of person are invalid")
+	ErrInvalidPersonStatus    = errors.New("status of people is invalid")
+	ErrPersonInvalidBirthYear = errors.New("birth year of person is invalid")
+	ErrInvalidRelation        = errors.New("relation between people is invalid")
+	ErrInvalidPreference      = errors.New("preference of person is invalid")
+	ErrPreferenceExists       = errors.New("preference for task or weekday already exists")
+	ErrInvalidAbsencePeriod   = errors.New("absence period of person is invalid")
+	ErrRecurringAbsenceExists = errors.New("recurring absence with rule and validity already exists")
 )
 
 // Meeting-Model errors
@@ -17,6 +35,10 @@ var (
 	ErrNotAllMeetingsCreated = errors.New("not all given meetings written to DB")
 	ErrMeetingNotDeleted     = errors.New("meeting not deleted")
 	ErrMeetingTagAlreadySet  = errors.New("tag for meeting is already set")
+	ErrInvalidMeetingSeries  = errors.New("meeting series is invalid")
+	ErrInvalidMeetingType    = errors.New("meeting type is invalid")
+	ErrMeetingTypeInUse      = errors.New("meeting type is used by meetings")
+	ErrMeetingTypeExists     = errors.New("meeting type with descr already exists")
 )
 
 // Task(-detail) errors
@@ -30,6 +52,23 @@ var (
 	ErrTaskForPersonNotAllowed = errors.New("person is not allowed for task")
 )
 
+// Plan errors
+var (
+	ErrUnknownStrategy       = errors.New("unknown assignment strategy")
+	ErrAssignmentCapExceeded = errors.New("maximum number of assignments for person exceeded")
+	ErrRelationViolated      = errors.New("assignment violates relation between people")
+	ErrRestViolated          = errors.New("assignment violates minimum rest interval of person")
+	ErrPlanPublished         = errors.New("plan is published, changes must be forced")
+	ErrInvalidPlanPeriod     = errors.New("plan period is invalid")
+	ErrPlanPeriodOverlap     = errors.New("plan period overlaps with another plan period")
+	ErrPlanPeriodArchived    = errors.New("state of archived plan period can not be changed")
+	ErrSwapState             = errors.New("swap request is not in the required state")
+	ErrSwapAlreadyOffered    = errors.New("plan element is already offered for a swap")
+	ErrSwapInvalid           = errors.New("plan elements can not be swapped")
+	ErrSwapNotAvailable      = errors.New("person is not available for the meeting of the swap")
+	ErrSwapOutdated          = errors.New("plan element was changed after the swap was requested")
+)
+
 var (
 	ErrIDNotSet        = errors.New("the id was not set")
 	ErrForeignIDNotSet = errors.New("the foreign id was not set")
@@ -0,0 +1,8 @@
+package errors
+
+import "errors"
+
+var (
+	ErrUnknownHolidayRegion = errors.New("unknown holiday region")
+	ErrInvalidICS           = errors.New("ics file is invalid")
+)
@@ -0,0 +1,5 @@
+package errors
+
+import "errors"
+
+var ErrInvalidRecurrenceRule = errors.New("recurrence rule is invalid or not supported")
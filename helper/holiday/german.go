@@ -0,0 +1,103 @@
+package holiday
+
+import (
+	"mpt_data/helper/errors"
+	"slices"
+	"strings"
+	"time"
+)
+
+// states holds the ISO 3166-2 codes of the german states
+var states = []string{"BW", "BY", "BE", "BB", "HB", "HH", "HE", "MV", "NI", "NW", "RP", "SL", "SN", "ST", "SH", "TH"}
+
+// German computes the public holidays of germany, either federal only or of one state.
+// Holidays of only some communities of a state, e.g. Mariä Himmelfahrt in bavaria, are not included
+type German struct {
+	// empty for federal holidays only
+	state string
+}
+
+// NewGerman returns the provider for a region, DE for federal holidays or DE-XX with the code of a state, e.g. DE-BY
+func NewGerman(region string) (German, error) {
+	region = strings.ToUpper(region)
+	if region == "DE" {
+		return German{}, nil
+	}
+	state, ok := strings.CutPrefix(region, "DE-")
+	if !ok || !slices.Contains(states, state) {
+		return German{}, errors.ErrUnknownHolidayRegion
+	}
+	return German{state: state}, nil
+}
+
+// Holidays returns the federal holidays and the holidays of the state in year
+func (g German) Holidays(year int) ([]Holiday, error) {
+	easter := Easter(year)
+	date := func(month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	}
+
+	holidays := []Holiday{
+		{date(time.January, 1), "Neujahr"},
+		{easter.AddDate(0, 0, -2), "Karfreitag"},
+		{easter.AddDate(0, 0, 1), "Ostermontag"},
+		{date(time.May, 1), "Tag der Arbeit"},
+		{easter.AddDate(0, 0, 39), "Christi Himmelfahrt"},
+		{easter.AddDate(0, 0, 50), "Pfingstmontag"},
+		{date(time.October, 3), "Tag der Deutschen Einheit"},
+		{date(time.December, 25), "1. Weihnachtstag"},
+		{date(time.December, 26), "2. Weihnachtstag"},
+	}
+	if year == 2017 {
+		// 500 years of reformation
+		holidays = append(holidays, Holiday{date(time.October, 31), "Reformationstag"})
+	}
+
+	in := func(states ...string) bool {
+		return slices.Contains(states, g.state)
+	}
+	state := []struct {
+		applies bool
+		holiday Holiday
+	}{
+		{in("BW", "BY", "ST"), Holiday{date(time.January, 6), "Heilige Drei Könige"}},
+		{(in("BE") && year >= 2019) || (in("MV") && year >= 2023), Holiday{date(time.March, 8), "Internationaler Frauentag"}},
+		{in("BB"), Holiday{easter, "Ostersonntag"}},
+		{in("BB"), Holiday{easter.AddDate(0, 0, 49), "Pfingstsonntag"}},
+		{in("BW", "BY", "HE", "NW", "RP", "SL"), Holiday{easter.AddDate(0, 0, 60), "Fronleichnam"}},
+		{in("SL"), Holiday{date(time.August, 15), "Mariä Himmelfahrt"}},
+		{in("TH") && year >= 2019, Holiday{date(time.September, 20), "Weltkindertag"}},
+		{year != 2017 && (in("BB", "MV", "SN", "ST", "TH") || (in("HB", "HH", "NI", "SH") && year >= 2018)), Holiday{date(time.October, 31), "Reformationstag"}},
+		{in("BW", "BY", "NW", "RP", "SL"), Holiday{date(time.November, 1), "Allerheiligen"}},
+		{in("SN"), Holiday{repentanceDay(year), "Buß- und Bettag"}},
+	}
+	for _, entry := range state {
+		if entry.applies {
+			holidays = append(holidays, entry.holiday)
+		}
+	}
+	return holidays, nil
+}
+
+// Easter returns easter sunday of year in the gregorian calendar
+func Easter(year int) time.Time {
+	// anonymous gregorian algorithm
+	a := year % 19
+	b, c := year/100, year%100
+	d, e := b/4, b%4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	i, k := c/4, c%4
+	l := (32 + 2*e + 2*i - h - k) % 7
+	m := (a + 11*h + 22*l) / 451
+	month := (h + l - 7*m + 114) / 31
+	day := (h+l-7*m+114)%31 + 1
+	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+}
+
+// repentanceDay returns the wednesday before the 23rd of november
+func repentanceDay(year int) time.Time {
+	day := time.Date(year, time.November, 22, 0, 0, 0, 0, time.UTC)
+	return day.AddDate(0, 0, -((int(day.Weekday()) - int(time.Wednesday) + 7) % 7))
+}
@@ -0,0 +1,103 @@
+// Package holiday provides public holidays, computed for german regions or imported from an ICS file
+package holiday
+
+import (
+	"mpt_data/helper/calendar"
+	"mpt_data/helper/config"
+	"strings"
+	"time"
+)
+
+// Modes to handle meetings at a holiday
+const (
+	// ModeTag creates a tag with the name of the holiday for the meeting
+	ModeTag = "tag"
+	// ModeFlag only reports the meetings at a holiday
+	ModeFlag = "flag"
+)
+
+// Holiday is a day without regular meetings
+type Holiday struct {
+	// date at midnight in UTC
+	Date time.Time
+	Name string
+}
+
+// Provider returns the holidays of a year
+type Provider interface {
+	Holidays(year int) ([]Holiday, error)
+}
+
+// providers combines several providers, a date may be returned by more than one of them
+type providers []Provider
+
+func (p providers) Holidays(year int) (holidays []Holiday, err error) {
+	for _, provider := range p {
+		some, err := provider.Holidays(year)
+		if err != nil {
+			return nil, err
+		}
+		holidays = append(holidays, some...)
+	}
+	return holidays, nil
+}
+
+// FromConfig returns the provider for the configured region and ICS file.
+// Returns nil, if neither is configured
+func FromConfig() (Provider, error) {
+	var configured providers
+	if region := config.Config.Holiday.Region; region != "" {
+		german, err := NewGerman(region)
+		if err != nil {
+			return nil, err
+		}
+		configured = append(configured, german)
+	}
+	if path := config.Config.Holiday.ICS; path != "" {
+		configured = append(configured, NewICS(path))
+	}
+	if len(configured) == 0 {
+		return nil, nil
+	}
+	return configured, nil
+}
+
+// Tagging reports if meetings at a holiday get a tag, otherwise they are only flagged
+func Tagging() bool {
+	return strings.ToLower(config.Config.Holiday.Mode) != ModeFlag
+}
+
+// OnDates returns the holidays of provider at the given days, grouped by day at midnight in UTC
+func OnDates(provider Provider, dates []time.Time) (map[time.Time][]Holiday, error) {
+	found := make(map[time.Time][]Holiday)
+	if provider == nil {
+		return found, nil
+	}
+	years := make(map[int][]Holiday)
+	for _, date := range dates {
+		day := calendar.DayOf(date)
+		holidays, ok := years[day.Year()]
+		if !ok {
+			var err error
+			if holidays, err = provider.Holidays(day.Year()); err != nil {
+				return nil, err
+			}
+			years[day.Year()] = holidays
+		}
+		for _, holiday := range holidays {
+			if holiday.Date.Equal(day) {
+				found[day] = append(found[day], holiday)
+			}
+		}
+	}
+	return found, nil
+}
+
+// Names joins the names of the holidays
+func Names(holidays []Holiday) string {
+	names := make([]string, 0, len(holidays))
+	for _, holiday := range holidays {
+		names = append(names, holiday.Name)
+	}
+	return strings.Join(names, ", ")
+}
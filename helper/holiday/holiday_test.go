@@ -0,0 +1,116 @@
+package holiday
+
+import (
+	"mpt_data/helper/errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEaster(t *testing.T) {
+	var testcases = []struct {
+		year     int
+		expected time.Time
+	}{
+		{2000, time.Date(2000, 4, 23, 0, 0, 0, 0, time.UTC)},
+		{2019, time.Date(2019, 4, 21, 0, 0, 0, 0, time.UTC)},
+		{2024, time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC)},
+		{2025, time.Date(2025, 4, 20, 0, 0, 0, 0, time.UTC)},
+		{2038, time.Date(2038, 4, 25, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.expected.Format(time.DateOnly), func(t *testing.T) {
+			// Act
+			easter := Easter(testcase.year)
+			// Assert
+			if !easter.Equal(testcase.expected) {
+				t.Errorf("expected %v, got %v", testcase.expected, easter)
+			}
+		})
+	}
+}
+
+func TestGerman(t *testing.T) {
+	date := func(month time.Month, day int) time.Time {
+		return time.Date(2024, month, day, 0, 0, 0, 0, time.UTC)
+	}
+	var testcases = []struct {
+		name     string
+		region   string
+		date     time.Time
+		expected string
+		err      error
+	}{
+		{"federal", "DE", date(time.October, 3), "Tag der Deutschen Einheit", nil},
+		{"movable federal", "DE", date(time.May, 9), "Christi Himmelfahrt", nil},
+		{"not federal", "DE", date(time.January, 6), "", nil},
+		{"state", "DE-BY", date(time.January, 6), "Heilige Drei Könige", nil},
+		{"movable state", "de-nw", date(time.May, 30), "Fronleichnam", nil},
+		{"repentance day", "DE-SN", date(time.November, 20), "Buß- und Bettag", nil},
+		{"unknown state", "DE-XX", time.Time{}, "", errors.ErrUnknownHolidayRegion},
+		{"other country", "AT", time.Time{}, "", errors.ErrUnknownHolidayRegion},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			// Act
+			provider, err := NewGerman(testcase.region)
+			if err != testcase.err {
+				t.Fatalf("expected %v, got %v", testcase.err, err)
+			}
+			if err != nil {
+				return
+			}
+			found, err := OnDates(provider, []time.Time{testcase.date})
+			// Assert
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if name := Names(found[testcase.date]); name != testcase.expected {
+				t.Errorf("expected %q, got %q", testcase.expected, name)
+			}
+		})
+	}
+}
+
+func TestICS(t *testing.T) {
+	// Prepare
+	calendar := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"BEGIN:VEVENT",
+		"DTSTART;VALUE=DATE:20241224",
+		"DTEND;VALUE=DATE:20241227",
+		"SUMMARY:Weihnachten\\, Gemeinde",
+		" ferien",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"DTSTART:20250101T100000Z",
+		"SUMMARY:Neujahr",
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}, "\r\n")
+	path := filepath.Join(t.TempDir(), "holidays.ics")
+	if err := os.WriteFile(path, []byte(calendar), 0600); err != nil {
+		t.Fatalf("test preparation failed: %v", err)
+	}
+
+	// Act
+	holidays, err := NewICS(path).Holidays(2024)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(holidays) != 3 {
+		t.Fatalf("expected 3 days, got %v", holidays)
+	}
+	if holidays[2].Date != time.Date(2024, 12, 26, 0, 0, 0, 0, time.UTC) || holidays[2].Name != "Weihnachten, Gemeindeferien" {
+		t.Errorf("expected Weihnachten, Gemeindeferien at 2024-12-26, got %v", holidays[2])
+	}
+	if _, err := NewICS(filepath.Join(t.TempDir(), "missing.ics")).Holidays(2024); err == nil {
+		t.Errorf("expected error for missing file")
+	}
+}
@@ -0,0 +1,124 @@
+package holiday
+
+import (
+	"bufio"
+	"io"
+	"mpt_data/helper/errors"
+	"os"
+	"strings"
+	"time"
+)
+
+// ICS reads holidays from the events of a local ICS file.
+// Events spanning several days are a holiday at every day, recurrence rules are not supported
+type ICS struct {
+	path string
+}
+
+// NewICS returns the provider for the ICS file at path, the file is read on every call of Holidays
+func NewICS(path string) ICS {
+	return ICS{path: path}
+}
+
+// Holidays returns the holidays of the ICS file in year
+func (i ICS) Holidays(year int) ([]Holiday, error) {
+	file, err := os.Open(i.path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	all, err := parseICS(file)
+	if err != nil {
+		return nil, err
+	}
+	var holidays []Holiday
+	for _, holiday := range all {
+		if holiday.Date.Year() == year {
+			holidays = append(holidays, holiday)
+		}
+	}
+	return holidays, nil
+}
+
+// parseICS reads all events of a calendar as holidays
+func parseICS(reader io.Reader) (holidays []Holiday, err error) {
+	lines, err := unfoldLines(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	var inEvent bool
+	var name string
+	var start, end time.Time
+	for _, line := range lines {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		// parameters like VALUE=DATE are not needed, the format of the value is detected
+		key, _, _ = strings.Cut(key, ";")
+
+		switch strings.ToUpper(key) {
+		case "BEGIN":
+			if strings.EqualFold(value, "VEVENT") {
+				inEvent, name, start, end = true, "", time.Time{}, time.Time{}
+			}
+		case "SUMMARY":
+			name = unescapeText(value)
+		case "DTSTART":
+			if start, err = parseICSDate(value); err != nil {
+				return nil, err
+			}
+		case "DTEND":
+			if end, err = parseICSDate(value); err != nil {
+				return nil, err
+			}
+		case "END":
+			if !inEvent || !strings.EqualFold(value, "VEVENT") {
+				continue
+			}
+			inEvent = false
+			if start.IsZero() {
+				return nil, errors.ErrInvalidICS
+			}
+			// the end of an event is exclusive
+			holidays = append(holidays, Holiday{Date: start, Name: name})
+			for day := start.AddDate(0, 0, 1); day.Before(end); day = day.AddDate(0, 0, 1) {
+				holidays = append(holidays, Holiday{Date: day, Name: name})
+			}
+		}
+	}
+	return holidays, nil
+}
+
+// unfoldLines joins lines, which are continued in the next line starting with a space or tab
+func unfoldLines(reader io.Reader) (lines []string, err error) {
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if len(lines) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, scanner.Err()
+}
+
+// parseICSDate parses a date or a date with time, the time is ignored
+func parseICSDate(value string) (time.Time, error) {
+	if len(value) < len("20060102") {
+		return time.Time{}, errors.ErrInvalidICS
+	}
+	date, err := time.Parse("20060102", value[:len("20060102")])
+	if err != nil {
+		return time.Time{}, errors.ErrInvalidICS
+	}
+	return date, nil
+}
+
+// unescapeText removes the escaping of text values
+func unescapeText(value string) string {
+	return strings.NewReplacer(`\n`, " ", `\N`, " ", `\,`, ",", `\;`, ";", `\\`, `\`).Replace(value)
+}
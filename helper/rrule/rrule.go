@@ -0,0 +1,343 @@
+// Package rrule provides recurrence rules as a subset of RFC 5545 RRULE, evaluated day by day
+package rrule
+
+import (
+	"fmt"
+	"mpt_data/helper/calendar"
+	"mpt_data/helper/errors"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Frequencies of a Rule
+const (
+	Daily   = "DAILY"
+	Weekly  = "WEEKLY"
+	Monthly = "MONTHLY"
+	Yearly  = "YEARLY"
+)
+
+// weekdays by their RRULE abbreviation
+var weekdays = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+// Day is a weekday of BYDAY, optional with ordinal, e.g. 1SU for the first or -1SU for the last sunday
+type Day struct {
+	Weekday time.Weekday
+	// 0 for every weekday in the period
+	Ordinal int
+}
+
+// Rule is a recurrence rule of the parts FREQ, INTERVAL, UNTIL, BYMONTH, BYWEEKNO, BYMONTHDAY and BYDAY.
+// Weeks start on monday, BYWEEKNO refers to ISO weeks and may be used with every frequency
+type Rule struct {
+	Freq     string
+	Interval int
+	// last day of the recurrence, zero if unlimited
+	Until      time.Time
+	ByMonth    []int
+	ByWeekNo   []int
+	ByMonthDay []int
+	ByDay      []Day
+}
+
+// OnWeekday returns the weekly rule for every weekday
+func OnWeekday(weekday time.Weekday) Rule {
+	return Rule{Freq: Weekly, Interval: 1, ByDay: []Day{{Weekday: weekday}}}
+}
+
+// Parse reads a rule like FREQ=MONTHLY;BYDAY=1SU, an optional RRULE: prefix is removed.
+// Returns ErrInvalidRecurrenceRule for unknown or unsupported parts
+func Parse(text string) (rule Rule, err error) {
+	text = strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(text)), "RRULE:")
+	rule.Interval = 1
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(text, ";") {
+		name, value, found := strings.Cut(part, "=")
+		if !found || value == "" || seen[name] {
+			return Rule{}, errors.ErrInvalidRecurrenceRule
+		}
+		seen[name] = true
+
+		switch name {
+		case "FREQ":
+			rule.Freq = value
+		case "INTERVAL":
+			rule.Interval, err = strconv.Atoi(value)
+			if err != nil || rule.Interval < 1 {
+				return Rule{}, errors.ErrInvalidRecurrenceRule
+			}
+		case "UNTIL":
+			rule.Until, err = parseDate(value)
+		case "BYMONTH":
+			rule.ByMonth, err = parseNumbers(value, 1, 12, false)
+		case "BYWEEKNO":
+			rule.ByWeekNo, err = parseNumbers(value, 1, 53, true)
+		case "BYMONTHDAY":
+			rule.ByMonthDay, err = parseNumbers(value, 1, 31, true)
+		case "BYDAY":
+			rule.ByDay, err = parseDays(value)
+		default:
+			return Rule{}, errors.ErrInvalidRecurrenceRule
+		}
+		if err != nil {
+			return Rule{}, errors.ErrInvalidRecurrenceRule
+		}
+	}
+	return rule, rule.validate()
+}
+
+// validate checks the frequency and that ordinals of BYDAY are only used monthly or yearly
+func (rule Rule) validate() error {
+	switch rule.Freq {
+	case Daily, Weekly:
+		for _, day := range rule.ByDay {
+			if day.Ordinal != 0 {
+				return errors.ErrInvalidRecurrenceRule
+			}
+		}
+		return nil
+	case Monthly, Yearly:
+		return nil
+	default:
+		return errors.ErrInvalidRecurrenceRule
+	}
+}
+
+// NeedsStart reports if the rule is anchored to its first day, as it has an interval
+// or its frequency has no part selecting the days
+func (rule Rule) NeedsStart() bool {
+	if rule.Interval > 1 {
+		return true
+	}
+	switch rule.Freq {
+	case Weekly:
+		return len(rule.ByDay) == 0
+	case Monthly:
+		return len(rule.ByDay) == 0 && len(rule.ByMonthDay) == 0
+	case Yearly:
+		return len(rule.ByDay) == 0 && len(rule.ByMonthDay) == 0 && len(rule.ByMonth) == 0 && len(rule.ByWeekNo) == 0
+	default:
+		return false
+	}
+}
+
+// Matches reports if the rule recurs at the day of date.
+// start is the first day of the recurrence and must be set, if the rule NeedsStart
+func (rule Rule) Matches(date, start time.Time) bool {
+	day := calendar.DayOf(date)
+	if !start.IsZero() {
+		start = calendar.DayOf(start)
+		if day.Before(start) {
+			return false
+		}
+	} else if rule.NeedsStart() {
+		return false
+	}
+	if !rule.Until.IsZero() && day.After(rule.Until) {
+		return false
+	}
+
+	if len(rule.ByMonth) > 0 && !slices.Contains(rule.ByMonth, int(day.Month())) {
+		return false
+	}
+	if len(rule.ByWeekNo) > 0 && !matchesWeekNo(rule.ByWeekNo, day) {
+		return false
+	}
+	if len(rule.ByMonthDay) > 0 && !matchesMonthDay(rule.ByMonthDay, day) {
+		return false
+	}
+	if len(rule.ByDay) > 0 && !rule.matchesDay(day) {
+		return false
+	}
+	if !rule.matchesStart(day, start) {
+		return false
+	}
+	return rule.matchesInterval(day, start)
+}
+
+// matchesStart applies the day of start for frequencies without part selecting the days
+func (rule Rule) matchesStart(day, start time.Time) bool {
+	if start.IsZero() {
+		return true
+	}
+	switch rule.Freq {
+	case Weekly:
+		return len(rule.ByDay) > 0 || day.Weekday() == start.Weekday()
+	case Monthly:
+		return len(rule.ByDay) > 0 || len(rule.ByMonthDay) > 0 || day.Day() == start.Day()
+	case Yearly:
+		if len(rule.ByDay) > 0 || len(rule.ByMonthDay) > 0 || len(rule.ByWeekNo) > 0 {
+			return true
+		}
+		if len(rule.ByMonth) > 0 {
+			return day.Day() == start.Day()
+		}
+		return day.Month() == start.Month() && day.Day() == start.Day()
+	default:
+		return true
+	}
+}
+
+// matchesInterval reports if day is in a period of the frequency, which is a multiple of the interval after start
+func (rule Rule) matchesInterval(day, start time.Time) bool {
+	if rule.Interval <= 1 {
+		return true
+	}
+	var periods int
+	switch rule.Freq {
+	case Daily:
+		periods = int(day.Sub(start).Hours() / 24)
+	case Weekly:
+		periods = int(mondayOf(day).Sub(mondayOf(start)).Hours() / 24 / 7)
+	case Monthly:
+		periods = (day.Year()-start.Year())*12 + int(day.Month()) - int(start.Month())
+	case Yearly:
+		periods = day.Year() - start.Year()
+	}
+	return periods%rule.Interval == 0
+}
+
+// matchesDay reports if day is one of BYDAY, ordinals count within the month,
+// or within the year for a yearly rule without BYMONTH
+func (rule Rule) matchesDay(day time.Time) bool {
+	for _, byDay := range rule.ByDay {
+		if day.Weekday() != byDay.Weekday {
+			continue
+		}
+		if byDay.Ordinal == 0 {
+			return true
+		}
+		first := time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC)
+		next := first.AddDate(0, 1, 0)
+		if rule.Freq == Yearly && len(rule.ByMonth) == 0 {
+			first = time.Date(day.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+			next = first.AddDate(1, 0, 0)
+		}
+		if byDay.Ordinal > 0 && int(day.Sub(first).Hours()/24)/7+1 == byDay.Ordinal {
+			return true
+		}
+		if byDay.Ordinal < 0 && int(next.Sub(day).Hours()/24-1)/7+1 == -byDay.Ordinal {
+			return true
+		}
+	}
+	return false
+}
+
+// String returns the rule in its canonical form
+func (rule Rule) String() string {
+	parts := []string{"FREQ=" + rule.Freq}
+	if rule.Interval > 1 {
+		parts = append(parts, fmt.Sprintf("INTERVAL=%d", rule.Interval))
+	}
+	if !rule.Until.IsZero() {
+		parts = append(parts, "UNTIL="+rule.Until.Format("20060102"))
+	}
+	if len(rule.ByMonth) > 0 {
+		parts = append(parts, "BYMONTH="+joinNumbers(rule.ByMonth))
+	}
+	if len(rule.ByWeekNo) > 0 {
+		parts = append(parts, "BYWEEKNO="+joinNumbers(rule.ByWeekNo))
+	}
+	if len(rule.ByMonthDay) > 0 {
+		parts = append(parts, "BYMONTHDAY="+joinNumbers(rule.ByMonthDay))
+	}
+	if len(rule.ByDay) > 0 {
+		days := make([]string, 0, len(rule.ByDay))
+		for _, day := range rule.ByDay {
+			abbreviation := strings.ToUpper(day.Weekday.String()[:2])
+			if day.Ordinal != 0 {
+				abbreviation = strconv.Itoa(day.Ordinal) + abbreviation
+			}
+			days = append(days, abbreviation)
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	return strings.Join(parts, ";")
+}
+
+// matchesWeekNo reports if the ISO week of day is one of weeks, negative weeks count from the end of the ISO year
+func matchesWeekNo(weeks []int, day time.Time) bool {
+	year, week := day.ISOWeek()
+	// the 28th of december is always in the last ISO week
+	_, last := time.Date(year, time.December, 28, 0, 0, 0, 0, time.UTC).ISOWeek()
+	return slices.Contains(weeks, week) || slices.Contains(weeks, week-last-1)
+}
+
+// matchesMonthDay reports if day is one of days, negative days count from the end of the month
+func matchesMonthDay(days []int, day time.Time) bool {
+	last := time.Date(day.Year(), day.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	return slices.Contains(days, day.Day()) || slices.Contains(days, day.Day()-last-1)
+}
+
+// parseNumbers reads a comma separated list of numbers between min and max, or -max and -min if negative is set
+func parseNumbers(value string, min, max int, negative bool) ([]int, error) {
+	var numbers []int
+	for _, text := range strings.Split(value, ",") {
+		number, err := strconv.Atoi(text)
+		if err != nil {
+			return nil, err
+		}
+		abs := number
+		if negative && number < 0 {
+			abs = -number
+		}
+		if abs < min || abs > max {
+			return nil, errors.ErrInvalidRecurrenceRule
+		}
+		numbers = append(numbers, number)
+	}
+	return numbers, nil
+}
+
+// parseDays reads a comma separated list of weekdays with optional ordinal, e.g. MO,-1FR
+func parseDays(value string) ([]Day, error) {
+	var days []Day
+	for _, text := range strings.Split(value, ",") {
+		if len(text) < 2 {
+			return nil, errors.ErrInvalidRecurrenceRule
+		}
+		weekday, found := weekdays[text[len(text)-2:]]
+		if !found {
+			return nil, errors.ErrInvalidRecurrenceRule
+		}
+		day := Day{Weekday: weekday}
+		if ordinal := text[:len(text)-2]; ordinal != "" {
+			var err error
+			if day.Ordinal, err = strconv.Atoi(ordinal); err != nil || day.Ordinal == 0 || day.Ordinal > 53 || day.Ordinal < -53 {
+				return nil, errors.ErrInvalidRecurrenceRule
+			}
+		}
+		days = append(days, day)
+	}
+	return days, nil
+}
+
+// parseDate reads a date of the form 20060102, a time part is ignored
+func parseDate(value string) (time.Time, error) {
+	date, _, _ := strings.Cut(value, "T")
+	return time.Parse("20060102", date)
+}
+
+// joinNumbers joins numbers comma separated
+func joinNumbers(numbers []int) string {
+	texts := make([]string, 0, len(numbers))
+	for _, number := range numbers {
+		texts = append(texts, strconv.Itoa(number))
+	}
+	return strings.Join(texts, ",")
+}
+
+// mondayOf returns the monday of the week of day
+func mondayOf(day time.Time) time.Time {
+	return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+}
@@ -0,0 +1,89 @@
+package rrule
+
+import (
+	"mpt_data/helper/errors"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	var testcases = []struct {
+		text      string
+		canonical string
+		err       error
+	}{
+		{"FREQ=WEEKLY;BYDAY=SU", "FREQ=WEEKLY;BYDAY=SU", nil},
+		{"rrule:freq=monthly;byday=1su", "FREQ=MONTHLY;BYDAY=1SU", nil},
+		{"BYDAY=WE;FREQ=WEEKLY;UNTIL=20260601T000000Z", "FREQ=WEEKLY;UNTIL=20260601;BYDAY=WE", nil},
+		{"FREQ=YEARLY;BYWEEKNO=1,3,-1", "FREQ=YEARLY;BYWEEKNO=1,3,-1", nil},
+		{"FREQ=WEEKLY;INTERVAL=2", "FREQ=WEEKLY;INTERVAL=2", nil},
+		{"FREQ=WEEKLY;BYDAY=1SU", "", errors.ErrInvalidRecurrenceRule},
+		{"FREQ=HOURLY", "", errors.ErrInvalidRecurrenceRule},
+		{"FREQ=DAILY;COUNT=3", "", errors.ErrInvalidRecurrenceRule},
+		{"FREQ=MONTHLY;BYMONTHDAY=32", "", errors.ErrInvalidRecurrenceRule},
+		{"FREQ=WEEKLY;FREQ=DAILY", "", errors.ErrInvalidRecurrenceRule},
+		{"BYDAY=SU", "", errors.ErrInvalidRecurrenceRule},
+		{"", "", errors.ErrInvalidRecurrenceRule},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.text, func(t *testing.T) {
+			// Act
+			rule, err := Parse(testcase.text)
+			// Assert
+			if err != testcase.err {
+				t.Fatalf("expected %v, got %v", testcase.err, err)
+			}
+			if err == nil && rule.String() != testcase.canonical {
+				t.Errorf("expected %s, got %s", testcase.canonical, rule.String())
+			}
+		})
+	}
+}
+
+func TestMatches(t *testing.T) {
+	date := func(month time.Month, day int) time.Time {
+		return time.Date(2026, month, day, 10, 0, 0, 0, time.UTC)
+	}
+	var testcases = []struct {
+		name  string
+		rule  string
+		start time.Time
+		date  time.Time
+		match bool
+	}{
+		{"weekday", "FREQ=WEEKLY;BYDAY=SU", time.Time{}, date(3, 1), true},
+		{"other weekday", "FREQ=WEEKLY;BYDAY=SU", time.Time{}, date(3, 2), false},
+		{"first sunday", "FREQ=MONTHLY;BYDAY=1SU", time.Time{}, date(3, 1), true},
+		{"second sunday", "FREQ=MONTHLY;BYDAY=1SU", time.Time{}, date(3, 8), false},
+		{"last sunday", "FREQ=MONTHLY;BYDAY=-1SU", time.Time{}, date(3, 29), true},
+		{"not last sunday", "FREQ=MONTHLY;BYDAY=-1SU", time.Time{}, date(3, 22), false},
+		{"odd ISO week", "FREQ=WEEKLY;BYDAY=SU;BYWEEKNO=1,3,5,7,9,11", time.Time{}, date(3, 15), true},
+		{"even ISO week", "FREQ=WEEKLY;BYDAY=SU;BYWEEKNO=1,3,5,7,9,11", time.Time{}, date(3, 8), false},
+		{"before until", "FREQ=WEEKLY;BYDAY=WE;UNTIL=20260601", time.Time{}, date(5, 27), true},
+		{"after until", "FREQ=WEEKLY;BYDAY=WE;UNTIL=20260601", time.Time{}, date(6, 3), false},
+		{"every second week", "FREQ=WEEKLY;INTERVAL=2;BYDAY=SU", date(3, 1), date(3, 15), true},
+		{"skipped week", "FREQ=WEEKLY;INTERVAL=2;BYDAY=SU", date(3, 1), date(3, 8), false},
+		{"before start", "FREQ=WEEKLY;BYDAY=SU", date(3, 2), date(3, 1), false},
+		{"interval without start", "FREQ=WEEKLY;INTERVAL=2;BYDAY=SU", time.Time{}, date(3, 1), false},
+		{"weekday of start", "FREQ=WEEKLY", date(3, 4), date(3, 11), true},
+		{"last day of month", "FREQ=MONTHLY;BYMONTHDAY=-1", time.Time{}, date(2, 28), true},
+		{"yearly", "FREQ=YEARLY;BYMONTH=12;BYMONTHDAY=24,25,26", time.Time{}, time.Date(2030, 12, 25, 0, 0, 0, 0, time.UTC), true},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			// Prepare
+			rule, err := Parse(testcase.rule)
+			if err != nil {
+				t.Fatalf("test preparation failed: %v", err)
+			}
+			// Act
+			match := rule.Matches(testcase.date, testcase.start)
+			// Assert
+			if match != testcase.match {
+				t.Errorf("expected %t, got %t", testcase.match, match)
+			}
+		})
+	}
+}
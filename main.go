@@ -10,6 +10,7 @@ import (
 	generalmodel "mpt_data/models/general"
 	"net/http"
 	"os"
+	"time"
 
 	"github.com/robfig/cron/v3"
 	"go.uber.org/zap"
@@ -34,9 +35,46 @@ func init() {
 		plan.PDFAutoRemoval(database.DB, 3)
 		zap.L().Info(generalmodel.EndExecPDFAutoremoval)
 	})
+	if schedule := config.Config.Plan.ConflictCheck.Schedule; schedule != "" {
+		if _, err := c.AddFunc(schedule, logPlanConflicts); err != nil {
+			zap.L().Error(generalmodel.ConflictCheckScheduleFailed, zap.Error(err), zap.String("schedule", schedule))
+			os.Exit(1)
+		}
+	}
 	go c.Start()
 }
 
+// defaultConflictMonths is used, if the number of months for the conflict check is not configured
+const defaultConflictMonths = 3
+
+// conflictMonths returns the configured number of months from today that are checked for conflicts
+func conflictMonths() int {
+	months := int(config.Config.Plan.ConflictCheck.Months)
+	if months == 0 {
+		return defaultConflictMonths
+	}
+	return months
+}
+
+// logPlanConflicts logs all conflicts of the plan from today for the configured number of months
+func logPlanConflicts() {
+	zap.L().Info(generalmodel.StartExecConflictCheck)
+	start := time.Now()
+	period := generalmodel.Period{StartDate: start, EndDate: start.AddDate(0, conflictMonths(), 0)}
+	conflicts, err := plan.GetConflicts(database.DB, period)
+	if err != nil {
+		zap.L().Error(generalmodel.PlanConflictFailed, zap.Error(err))
+	}
+	for _, conflict := range conflicts {
+		zap.L().Warn(generalmodel.PlanConflictFound,
+			zap.Uint("plan", conflict.Plan.ID),
+			zap.Uint("person", conflict.Plan.PersonID),
+			zap.Time("date", conflict.Plan.Meeting.Date),
+			zap.String(generalmodel.Reason, conflict.Reason))
+	}
+	zap.L().Info(generalmodel.EndExecConflictCheck, zap.Int("conflicts", len(conflicts)))
+}
+
 // @title						MPT
 // @version					1
 // @description				Meeting Planning Tool API
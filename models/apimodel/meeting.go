@@ -0,0 +1,19 @@
+package apimodel
+
+import "mpt_data/models/dbmodel"
+
+// MaterializedMeetings holds the meetings of a MeetingSeries in a period,
+// split into the newly created meetings and the meetings, which existed before
+type MaterializedMeetings struct {
+	Created  []dbmodel.Meeting
+	Existing []dbmodel.Meeting
+	// created and existing meetings at a holiday
+	Holidays []HolidayMeeting
+}
+
+// HolidayMeeting is a meeting at a public holiday
+type HolidayMeeting struct {
+	Meeting dbmodel.Meeting
+	// names of all holidays at the day of the meeting
+	Holiday string
+}
@@ -1,11 +1,91 @@
 // Package apimodel contains all models used by api to exchange data with client
 package apimodel
 
-import "mpt_data/models/dbmodel"
+import (
+	"bytes"
+	"encoding/json"
+	"mpt_data/helper/errors"
+	"mpt_data/helper/rrule"
+	"mpt_data/models/dbmodel"
+	"time"
+)
 
 // People is a struct to hold absent and available people
 type People struct {
 	Absent    []dbmodel.Person `json:"absent"`
 	Available []dbmodel.Person `json:"available"`
 	Assigned  dbmodel.Person   `json:"assigned"`
+	// available people, who would violate the minimum rest interval
+	RestViolation []dbmodel.Person `json:"restViolation"`
+}
+
+// PersonFilter selects people by their status and their names
+type PersonFilter struct {
+	// active or inactive, all people if empty
+	Status string
+	// names to search, every word is a prefix of a word of the names, or the whole name if Exact is set
+	Query string
+	// Query is compared with the given name, the last name or the full name
+	Exact bool
+}
+
+// PersonTaskLimit is type for client to send the maximum number of assignments of a person for a taskDetail in a period
+type PersonTaskLimit struct {
+	TaskDetailID         uint
+	MaxAssignmentsPeriod uint
+}
+
+// PersonAbsences holds the absence of a person at single meetings and in periods of days
+type PersonAbsences struct {
+	Meetings []dbmodel.Meeting
+	Periods  []dbmodel.PersonAbsencePeriod
+}
+
+// AbsenceRequest is type for client to add or remove absence of a person.
+// Periods are identified by their ID on removal.
+// A plain array is read as IDs of meetings
+type AbsenceRequest struct {
+	Meetings []uint
+	Periods  []dbmodel.PersonAbsencePeriod
+}
+
+// UnmarshalJSON reads either an object or an array of meeting IDs
+func (ar *AbsenceRequest) UnmarshalJSON(data []byte) error {
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		ar.Periods = nil
+		return json.Unmarshal(trimmed, &ar.Meetings)
+	}
+	type absenceRequest AbsenceRequest
+	return json.Unmarshal(data, (*absenceRequest)(ar))
+}
+
+// RecurringAbsenceRequest is type for client to add or remove recurring absences of a person.
+// Numbers are read as weekdays, 0 = Sunday, and stand for the weekly rule of this weekday
+type RecurringAbsenceRequest []dbmodel.PersonRecurringAbsence
+
+// UnmarshalJSON reads an array of recurring absences or weekdays
+func (rr *RecurringAbsenceRequest) UnmarshalJSON(data []byte) error {
+	var elements []json.RawMessage
+	if err := json.Unmarshal(data, &elements); err != nil {
+		return err
+	}
+	absences := make(RecurringAbsenceRequest, 0, len(elements))
+	for _, element := range elements {
+		var absence dbmodel.PersonRecurringAbsence
+		if trimmed := bytes.TrimSpace(element); len(trimmed) > 0 && trimmed[0] != '{' {
+			var weekday int
+			if err := json.Unmarshal(trimmed, &weekday); err != nil {
+				return err
+			}
+			if weekday < 0 || weekday > 6 {
+				return errors.ErrInvalidRecurrenceRule
+			}
+			absence.Rule = rrule.OnWeekday(time.Weekday(weekday)).String()
+		} else if err := json.Unmarshal(element, &absence); err != nil {
+			return err
+		}
+		absences = append(absences, absence)
+	}
+	*rr = absences
+	return nil
 }
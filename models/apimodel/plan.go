@@ -0,0 +1,54 @@
+package apimodel
+
+import "mpt_data/models/dbmodel"
+
+// PlanResult holds the plan elements of a plan creation and the slots nobody could be assigned to
+type PlanResult struct {
+	Plan     []dbmodel.Plan
+	Unfilled []dbmodel.Plan
+	Complete bool
+}
+
+// Reasons of a PlanConflict
+const (
+	// ConflictAbsent person is absent at the meeting
+	ConflictAbsent = "absent"
+	// ConflictRecurringAbsent person is absent at the weekday of the meeting
+	ConflictRecurringAbsent = "recurring_absent"
+	// ConflictNotQualified person is no longer allowed for the task
+	ConflictNotQualified = "not_qualified"
+	// ConflictDoubleBooked person is assigned more than once at the meeting
+	ConflictDoubleBooked = "double_booked"
+	// ConflictPersonDeleted person was deleted
+	ConflictPersonDeleted = "person_deleted"
+	// ConflictInactive person is not active at the date of the meeting
+	ConflictInactive = "inactive"
+)
+
+// PlanConflict is a plan element, whose assigned person is no longer valid.
+// A plan element with several conflicts is listed once per reason
+type PlanConflict struct {
+	Plan   dbmodel.Plan
+	Reason string
+}
+
+// SwapPlan is type for client to send the plan element to offer or accept for a swap
+type SwapPlan struct {
+	PlanID uint
+}
+
+// AssignmentStat shows how often a person was assigned to a task detail in a period compared to other qualified people
+type AssignmentStat struct {
+	Person     dbmodel.Person
+	TaskDetail dbmodel.TaskDetail
+	// number of assignments to the task detail
+	Assignments uint
+	// meetings without tag in the period needing the task detail, at which the person is active and not absent
+	EligibleMeetings uint
+	// Assignments divided by EligibleMeetings, 0 without eligible meetings
+	Share float64
+	// difference of Assignments to the mean of all people qualified for the task detail
+	Deviation float64
+	// meetings without tag in the period needing the task detail, at which the person is active but absent
+	Absences uint
+}
@@ -4,3 +4,21 @@ type UserLogin struct {
 	Username string
 	Password string
 }
+
+// User is type for client to create a user or change its role and person.
+// The password is only used on creation
+type User struct {
+	Username string
+	Password string `json:",omitempty"`
+	// admin or self, self needs a person
+	Role     string
+	PersonID *uint
+}
+
+// UserInfo is a user as it is sent to clients
+type UserInfo struct {
+	ID       uint
+	Username string
+	Role     string
+	PersonID *uint `json:",omitempty"`
+}
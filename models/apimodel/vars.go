@@ -9,6 +9,16 @@ const (
 	LoginHref        = base + "/login"
 	UserHref         = base + "/user"
 	UserChangePWHref = UserHref + "/password"
+	UserHrefWithID   = UserHref + "/{id}"
+)
+
+// Self service Routes for API, scoped to the person linked to the user
+const (
+	MeHref                 = base + "/me"
+	MeAbsenceHref          = MeHref + "/absence"
+	MeAbsenceRecurringHref = MeHref + "/absencerecurring"
+	MePlanHref             = MeHref + "/plan"
+	MeTasksHref            = MeHref + "/tasks"
 )
 
 // Meeting Routes for API
@@ -16,14 +26,34 @@ const (
 	MeetingHref       = base + "/meeting"
 	MeetingHrefWithID = MeetingHref + "/{id}"
 	MeetingTagHref    = MeetingHrefWithID + "/tag"
+
+	MeetingHolidayHref    = MeetingHref + "/holiday"
+	MeetingTypeHref       = MeetingHref + "/type"
+	MeetingTypeHrefWithID = MeetingTypeHref + "/{id}"
+
+	MeetingSeriesHref            = MeetingHref + "/series"
+	MeetingSeriesHrefWithID      = MeetingSeriesHref + "/{id}"
+	MeetingSeriesHrefMaterialize = MeetingSeriesHrefWithID + "/materialize"
 )
 
 // Plan Routes for API
 const (
-	PlanHref             = base + "/plan"
-	PlanHrefPDF          = PlanHref + "/pdf"
-	PlanHrefWithID       = PlanHref + "/{id}"
-	PlanHrefWithIDPeople = PlanHrefWithID + "/people"
+	PlanHref              = base + "/plan"
+	PlanHrefPDF           = PlanHref + "/pdf"
+	PlanHrefSolve         = PlanHref + "/solve"
+	PlanHrefFill          = PlanHref + "/fill"
+	PlanHrefConflicts     = PlanHref + "/conflicts"
+	PlanHrefChanges       = PlanHref + "/changes"
+	PlanPeriodHref        = PlanHref + "/period"
+	PlanPeriodHrefWithID  = PlanPeriodHref + "/{id}"
+	PlanSwapHref          = PlanHref + "/swap"
+	PlanSwapHrefWithID    = PlanSwapHref + "/{id}"
+	PlanSwapHrefAccept    = PlanSwapHrefWithID + "/accept"
+	PlanSwapHrefApprove   = PlanSwapHrefWithID + "/approve"
+	PlanSwapHrefReject    = PlanSwapHrefWithID + "/reject"
+	PlanHrefWithID        = PlanHref + "/{id}"
+	PlanHrefWithIDPeople  = PlanHrefWithID + "/people"
+	PlanHrefWithIDHistory = PlanHrefWithID + "/history"
 )
 
 // Absence Routes for API
@@ -47,4 +77,17 @@ const (
 	PersonHref       = base + "/person"
 	PersonHrefWithID = PersonHref + "/{id}"
 	PersonHrefTask   = PersonHrefWithID + "/task"
+	PersonHrefPlan   = PersonHrefWithID + "/plan"
+
+	PersonHrefRelation       = PersonHrefWithID + "/relation"
+	PersonHrefRelationWithID = PersonHrefRelation + "/{relationId}"
+
+	PersonHrefPreference       = PersonHrefWithID + "/preference"
+	PersonHrefPreferenceWithID = PersonHrefPreference + "/{preferenceId}"
+)
+
+// Statistic Routes for API
+const (
+	StatsHref            = base + "/stats"
+	StatsAssignmentsHref = StatsHref + "/assignments"
 )
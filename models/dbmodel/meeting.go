@@ -4,6 +4,8 @@ package dbmodel
 import (
 	"encoding/json"
 	"mpt_data/helper"
+	"mpt_data/helper/errors"
+	generalmodel "mpt_data/models/general"
 	"time"
 
 	"gorm.io/gorm"
@@ -16,6 +18,36 @@ type Meeting struct {
 	Date       time.Time `gorm:"uniqueIndex" json:"Date"`
 	TagID      uint      `json:"-"`
 	Tag        Tag       `gorm:"ForeignKey:TagID"`
+	// meetings without type need all TaskDetails
+	MeetingTypeID *uint `gorm:"index" json:",omitempty"`
+}
+
+// BeforeSave checks if the MeetingType exists
+func (m *Meeting) BeforeSave(db *gorm.DB) (err error) {
+	return checkMeetingType(db, m.MeetingTypeID)
+}
+
+// checkMeetingType returns ErrInvalidMeetingType, if the MeetingType is set and does not exist
+func checkMeetingType(db *gorm.DB, meetingTypeID *uint) error {
+	if meetingTypeID == nil {
+		return nil
+	}
+	var count int64
+	if err := db.Session(&gorm.Session{NewDB: true}).Model(&MeetingType{}).Where("id = ?", *meetingTypeID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return errors.ErrInvalidMeetingType
+	}
+	return nil
+}
+
+// MeetingType groups meetings, which need the same TaskDetails, e.g. sunday service or youth evening
+type MeetingType struct {
+	gorm.Model  `json:"-"`
+	ID          uint
+	Descr       string       `gorm:"not null;uniqueIndex"`
+	TaskDetails []TaskDetail `gorm:"many2many:meeting_type_task_details"`
 }
 
 // Tag is a struct to have a descr
@@ -49,12 +81,14 @@ func (t *Tag) AfterFind(_ *gorm.DB) (err error) {
 func (m *Meeting) UnmarshalJSON(data []byte) (err error) {
 	// Unmarshal the JSON data into the temporary struct
 	var meetingJSON = struct {
-		Date string `json:"Date"`
+		Date          string `json:"Date"`
+		MeetingTypeID *uint
 	}{}
 
 	if err := json.Unmarshal(data, &meetingJSON); err != nil {
 		return err
 	}
+	m.MeetingTypeID = meetingJSON.MeetingTypeID
 
 	date, err := helper.ParseTime(meetingJSON.Date)
 	m.Date = date
@@ -80,3 +114,78 @@ func (m Meeting) MarshalJSON() ([]byte, error) {
 		Alias: (Alias)(m),
 	})
 }
+
+// Frequencies of a MeetingSeries
+const (
+	// SeriesWeekly meetings take place on Weekday every Interval weeks
+	SeriesWeekly = "weekly"
+	// SeriesMonthly meetings take place on the Ordinal Weekday of the month every Interval months
+	SeriesMonthly = "monthly"
+)
+
+// TimeOfDayFormat is the format of the time of day of a MeetingSeries
+const TimeOfDayFormat = "15:04"
+
+// MeetingSeries stores a recurrence rule to create meetings between StartDate and EndDate
+type MeetingSeries struct {
+	gorm.Model `json:"-"`
+	ID         uint
+	Descr      string
+	generalmodel.Period
+	Frequency string `gorm:"not null"`
+	// 1 for every week or month, 2 for every second week or month
+	Interval uint `gorm:"not null;default:1"`
+	// 0 for sunday to 6 for saturday
+	Weekday int
+	// only monthly: 1 to 4 for the first to fourth, -1 for the last Weekday of the month
+	Ordinal int
+	// time of the meetings in UTC, e.g. 10:30, empty for midnight
+	TimeOfDay string
+	// type of the created meetings
+	MeetingTypeID *uint
+	// dates without a meeting
+	Exceptions []MeetingSeriesException `gorm:"constraint:OnDelete:CASCADE"`
+}
+
+// MeetingSeriesException stores a date, at which a MeetingSeries has no meeting
+type MeetingSeriesException struct {
+	gorm.Model      `json:"-"`
+	ID              uint
+	MeetingSeriesID uint `gorm:"index" json:"-"`
+	Date            time.Time
+}
+
+func (ms *MeetingSeries) validate() error {
+	if ms.Interval == 0 {
+		ms.Interval = 1
+	}
+	if ms.StartDate.IsZero() || ms.EndDate.Before(ms.StartDate) || ms.Weekday < 0 || ms.Weekday > 6 {
+		return errors.ErrInvalidMeetingSeries
+	}
+	if ms.TimeOfDay != "" {
+		if _, err := time.Parse(TimeOfDayFormat, ms.TimeOfDay); err != nil {
+			return errors.ErrInvalidMeetingSeries
+		}
+	}
+	switch ms.Frequency {
+	case SeriesWeekly:
+		if ms.Ordinal != 0 {
+			return errors.ErrInvalidMeetingSeries
+		}
+	case SeriesMonthly:
+		if ms.Ordinal != -1 && (ms.Ordinal < 1 || ms.Ordinal > 4) {
+			return errors.ErrInvalidMeetingSeries
+		}
+	default:
+		return errors.ErrInvalidMeetingSeries
+	}
+	return nil
+}
+
+// BeforeSave validates the series
+func (ms *MeetingSeries) BeforeSave(db *gorm.DB) (err error) {
+	if err := ms.validate(); err != nil {
+		return err
+	}
+	return checkMeetingType(db, ms.MeetingTypeID)
+}
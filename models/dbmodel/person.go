@@ -4,21 +4,233 @@ package dbmodel
 import (
 	"encoding/json"
 	"mpt_data/helper"
+	"mpt_data/helper/calendar"
+	"mpt_data/helper/config"
 	"mpt_data/helper/errors"
+	"mpt_data/helper/rrule"
+	"net/mail"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
 
 	"gorm.io/gorm"
 )
 
-// TODO: possibility to decide between people with same name
-// Person stores persons data
+// Person stores persons data.
+// People with the same name are told apart by Disambiguator or BirthYear, see SetDisplayNames
 type Person struct {
 	gorm.Model `json:"-"`
 	ID         uint
 	GivenName  string `gorm:"not null"`
 	LastName   string `gorm:"not null"`
+	// blind indexes of the names, to search the encrypted names
+	GivenNameIndex string `gorm:"index" json:"-"`
+	LastNameIndex  string `gorm:"index" json:"-"`
+	NameIndex      string `gorm:"index" json:"-"`
+	// optional addition to tell people with the same name apart, e.g. jun.
+	Disambiguator string `json:",omitempty"`
+	// optional year of birth, 0 if not set
+	BirthYear uint `gorm:"not null;default:0" json:",omitempty"`
+	// name rendered by SetDisplayNames, not stored
+	DisplayName string `gorm:"-" json:",omitempty"`
+	// maximum number of assignments in a planning period, 0 for unlimited
+	MaxAssignmentsPeriod uint `gorm:"not null;default:0"`
+	// maximum number of assignments in a calendar month, 0 for unlimited
+	MaxAssignmentsMonth uint `gorm:"not null;default:0"`
+	// contact data is optional, stored encrypted and only marshaled if shown by ShowContact
+	Email string `json:",omitempty"`
+	Phone string `json:",omitempty"`
+	Notes string `json:",omitempty"`
+	// inactive people are not planned, but kept with their history, true if not set
+	Active *bool `gorm:"not null;default:true"`
+	// first day the person is active
+	ActiveFrom *time.Time `json:",omitempty"`
+	// last day the person is active
+	ActiveUntil *time.Time `json:",omitempty"`
+
+	showContact bool
+}
+
+// Filters of people by their status
+const (
+	// PersonStatusActive people are active today
+	PersonStatusActive = "active"
+	// PersonStatusInactive people are inactive or outside of their active days today
+	PersonStatusInactive = "inactive"
+)
+
+// IsActive reports if the person is active at the day of date
+func (p Person) IsActive(date time.Time) bool {
+	if p.Active != nil && !*p.Active {
+		return false
+	}
+	day := calendar.DayOf(date)
+	if p.ActiveFrom != nil && day.Before(calendar.DayOf(*p.ActiveFrom)) {
+		return false
+	}
+	return p.ActiveUntil == nil || !day.After(calendar.DayOf(*p.ActiveUntil))
+}
+
+// phonePattern allows digits with an optional leading plus and common separators
+var phonePattern = regexp.MustCompile(`^\+?[0-9][0-9 ()/.-]{2,30}$`)
+
+// validate checks the name, the contact data, the birth year and the active days, Active is true if not set
+func (p *Person) validate() error {
+	if p.GivenName == "" || p.LastName == "" {
+		return errors.ErrPersonMissingName
+	}
+	if p.Email != "" {
+		if address, err := mail.ParseAddress(p.Email); err != nil || address.Address != p.Email {
+			return errors.ErrPersonInvalidEmail
+		}
+	}
+	if p.Phone != "" && !phonePattern.MatchString(p.Phone) {
+		return errors.ErrPersonInvalidPhone
+	}
+	if p.BirthYear != 0 && (p.BirthYear < 1900 || int(p.BirthYear) > time.Now().Year()) {
+		return errors.ErrPersonInvalidBirthYear
+	}
+
+	if p.Active == nil {
+		active := true
+		p.Active = &active
+	}
+	if p.ActiveFrom != nil {
+		from := calendar.DayOf(*p.ActiveFrom)
+		p.ActiveFrom = &from
+	}
+	if p.ActiveUntil != nil {
+		until := calendar.DayOf(*p.ActiveUntil)
+		p.ActiveUntil = &until
+		if p.ActiveFrom != nil && until.Before(*p.ActiveFrom) {
+			return errors.ErrPersonInvalidActivity
+		}
+	}
+	return nil
+}
+
+// ShowContact sets whether Email, Phone and Notes are marshaled to JSON
+func (p *Person) ShowContact(show bool) {
+	p.showContact = show
+}
+
+// MarshalJSON omits the contact data, unless it is shown
+func (p Person) MarshalJSON() ([]byte, error) {
+	type Alias Person
+	if !p.showContact {
+		p.Email, p.Phone, p.Notes = "", "", ""
+	}
+	return json.Marshal((Alias)(p))
+}
+
+// FullName returns given and last name separated by a space
+func (p Person) FullName() string {
+	return p.GivenName + " " + p.LastName
+}
+
+// normalizedName returns the full name in lower case with single spaces
+func (p Person) normalizedName() string {
+	return strings.ToLower(strings.Join(strings.Fields(p.FullName()), " "))
+}
+
+// defaultDuplicateName is the format of names, which are not unique, if not configured
+const defaultDuplicateName = "{given} {last} ({disambiguator})"
+
+// SetDisplayNames sets the DisplayName of people to their full name.
+// If people with different IDs share a name, it is rendered by the format Names.Duplicate of the config,
+// where {disambiguator} is the Disambiguator, else the BirthYear, else the ID of the person
+func SetDisplayNames(people ...*Person) {
+	ids := make(map[string]map[uint]bool)
+	for _, person := range people {
+		name := person.normalizedName()
+		if ids[name] == nil {
+			ids[name] = make(map[uint]bool)
+		}
+		ids[name][person.ID] = true
+	}
+
+	format := config.Config.Names.Duplicate
+	if format == "" {
+		format = defaultDuplicateName
+	}
+	for _, person := range people {
+		name := person.normalizedName()
+		if len(ids[name]) < 2 {
+			person.DisplayName = person.FullName()
+			continue
+		}
+		person.DisplayName = person.formatName(format)
+	}
+}
+
+// formatName replaces the placeholders {given}, {last}, {disambiguator}, {birthyear} and {id} of format
+func (p Person) formatName(format string) string {
+	birthYear, disambiguator := "", p.Disambiguator
+	if p.BirthYear != 0 {
+		birthYear = strconv.FormatUint(uint64(p.BirthYear), 10)
+	}
+	if disambiguator == "" {
+		disambiguator = birthYear
+	}
+	if disambiguator == "" {
+		disambiguator = "#" + strconv.FormatUint(uint64(p.ID), 10)
+	}
+	return strings.NewReplacer(
+		"{given}", p.GivenName,
+		"{last}", p.LastName,
+		"{disambiguator}", disambiguator,
+		"{birthyear}", birthYear,
+		"{id}", strconv.FormatUint(uint64(p.ID), 10),
+	).Replace(format)
+}
+
+// SetIndex sets the blind indexes of the names
+func (p *Person) SetIndex() {
+	p.GivenNameIndex = helper.BlindIndex(p.GivenName)
+	p.LastNameIndex = helper.BlindIndex(p.LastName)
+	p.NameIndex = helper.BlindIndex(p.normalizedName())
+}
+
+// maxPrefixLength limits the prefixes of a word stored in the search index
+const maxPrefixLength = 32
+
+// SearchIndex returns the blind indexes of all prefixes of the words of the names
+func (p Person) SearchIndex() []string {
+	seen := make(map[string]bool)
+	var index []string
+	for _, word := range strings.Fields(strings.ToLower(p.FullName())) {
+		runes := []rune(word)
+		for length := 1; length <= len(runes) && length <= maxPrefixLength; length++ {
+			hash := helper.BlindIndex(string(runes[:length]))
+			if !seen[hash] {
+				seen[hash] = true
+				index = append(index, hash)
+			}
+		}
+	}
+	return index
+}
+
+// storeSearchIndex replaces the search index of the person
+func (p Person) storeSearchIndex(db *gorm.DB) error {
+	db = db.Session(&gorm.Session{NewDB: true})
+	if err := db.Where("person_id = ?", p.ID).Delete(&PersonSearchIndex{}).Error; err != nil {
+		return err
+	}
+	index := p.SearchIndex()
+	rows := make([]PersonSearchIndex, 0, len(index))
+	for _, hash := range index {
+		rows = append(rows, PersonSearchIndex{PersonID: p.ID, Hash: hash})
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	return db.Create(&rows).Error
 }
 
 func (p *Person) encrypt() error {
+	p.SetIndex()
 	givenName, err := helper.EncryptData(p.GivenName)
 	if err != nil {
 		return err
@@ -31,6 +243,15 @@ func (p *Person) encrypt() error {
 	}
 	p.LastName = lastName
 
+	for _, field := range []*string{&p.Email, &p.Phone, &p.Notes} {
+		if *field == "" {
+			continue
+		}
+		if *field, err = helper.EncryptData(*field); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -47,33 +268,50 @@ func (p *Person) decrypt() error {
 	}
 	p.LastName = string(lastName)
 
+	for _, field := range []*string{&p.Email, &p.Phone, &p.Notes} {
+		if *field == "" {
+			continue
+		}
+		data, err := helper.DecryptData(*field)
+		if err != nil {
+			return err
+		}
+		*field = string(data)
+	}
+
 	return nil
 }
 
 // BeforeCreate encryptes data in Database
 func (p *Person) BeforeCreate(_ *gorm.DB) (err error) {
-	if p.GivenName == "" || p.LastName == "" {
-		return errors.ErrPersonMissingName
+	if err := p.validate(); err != nil {
+		return err
 	}
 	return p.encrypt()
 }
 
-// AfterCreate decryptes data after creation
-func (p *Person) AfterCreate(_ *gorm.DB) (err error) {
-	return p.decrypt()
+// AfterCreate decryptes data after creation and stores the search index
+func (p *Person) AfterCreate(db *gorm.DB) (err error) {
+	if err := p.decrypt(); err != nil {
+		return err
+	}
+	return p.storeSearchIndex(db)
 }
 
 // BeforeUpdate encryptes data in Database
 func (p *Person) BeforeUpdate(_ *gorm.DB) (err error) {
-	if p.GivenName == "" || p.LastName == "" {
-		return errors.ErrPersonMissingName
+	if err := p.validate(); err != nil {
+		return err
 	}
 	return p.encrypt()
 }
 
-// AfterUpdate decryptes data after creation
-func (p *Person) AfterUpdate(_ *gorm.DB) (err error) {
-	return p.decrypt()
+// AfterUpdate decryptes data after update and stores the search index
+func (p *Person) AfterUpdate(db *gorm.DB) (err error) {
+	if err := p.decrypt(); err != nil {
+		return err
+	}
+	return p.storeSearchIndex(db)
 }
 
 // AfterFind decryptes data from Database
@@ -81,6 +319,21 @@ func (p *Person) AfterFind(_ *gorm.DB) (err error) {
 	return p.decrypt()
 }
 
+// AfterDelete removes the search index of the person
+func (p *Person) AfterDelete(db *gorm.DB) (err error) {
+	if p.ID == 0 {
+		return nil
+	}
+	return db.Session(&gorm.Session{NewDB: true}).Where("person_id = ?", p.ID).Delete(&PersonSearchIndex{}).Error
+}
+
+// PersonSearchIndex stores the blind index of a prefix of a word of the names of a person
+type PersonSearchIndex struct {
+	ID       uint
+	PersonID uint   `gorm:"not null;index"`
+	Hash     string `gorm:"not null;index"`
+}
+
 type PersonAbsence struct {
 	gorm.Model `json:"-"`
 	ID         uint
@@ -90,11 +343,225 @@ type PersonAbsence struct {
 	Person     Person  `gorm:"ForeignKey:PersonID"`
 }
 
+// PersonRecurringAbsence stores a recurrence rule, at whose days the person is absent.
+// The rule is a subset of RFC 5545 RRULE, e.g. FREQ=MONTHLY;BYDAY=1SU for every first sunday of the month
 type PersonRecurringAbsence struct {
 	gorm.Model `json:"-"`
 	ID         uint
-	Weekday    int  `gorm:"not null;index:personRecurringAbsence,unique"`
-	PersonID   uint `gorm:"not null;index:personRecurringAbsence,unique" json:"-"`
+	PersonID   uint   `gorm:"not null;index;index:personRecurringAbsenceRule,unique" json:"-"`
+	Rule       string `gorm:"not null;default:'';index:personRecurringAbsenceRule,unique"`
+	// first day of the rule, needed for rules with interval
+	ValidFrom *time.Time `gorm:"index:personRecurringAbsenceRule,unique" json:",omitempty"`
+	// last day of the rule
+	ValidUntil *time.Time `gorm:"index:personRecurringAbsenceRule,unique" json:",omitempty"`
+
+	rule *rrule.Rule
+}
+
+// BeforeSave validates the rule, stores it in canonical form and the validity as days
+func (pr *PersonRecurringAbsence) BeforeSave(_ *gorm.DB) (err error) {
+	rule, err := rrule.Parse(pr.Rule)
+	if err != nil {
+		return err
+	}
+	if pr.PersonID == 0 || (rule.NeedsStart() && pr.ValidFrom == nil) {
+		return errors.ErrInvalidRecurrenceRule
+	}
+	if pr.ValidFrom != nil {
+		from := calendar.DayOf(*pr.ValidFrom)
+		pr.ValidFrom = &from
+	}
+	if pr.ValidUntil != nil {
+		until := calendar.DayOf(*pr.ValidUntil)
+		pr.ValidUntil = &until
+		if pr.ValidFrom != nil && until.Before(*pr.ValidFrom) {
+			return errors.ErrInvalidRecurrenceRule
+		}
+	}
+	pr.Rule = rule.String()
+	pr.rule = &rule
+	return nil
+}
+
+// AfterFind parses the rule
+func (pr *PersonRecurringAbsence) AfterFind(_ *gorm.DB) (err error) {
+	rule, err := rrule.Parse(pr.Rule)
+	if err == nil {
+		pr.rule = &rule
+	}
+	return nil
+}
+
+// Covers reports if the rule recurs at the day of date within the validity
+func (pr PersonRecurringAbsence) Covers(date time.Time) bool {
+	rule := pr.rule
+	if rule == nil {
+		parsed, err := rrule.Parse(pr.Rule)
+		if err != nil {
+			return false
+		}
+		rule = &parsed
+	}
+	if pr.ValidUntil != nil && calendar.DayOf(date).After(calendar.DayOf(*pr.ValidUntil)) {
+		return false
+	}
+	var start time.Time
+	if pr.ValidFrom != nil {
+		start = *pr.ValidFrom
+	}
+	return rule.Matches(date, start)
+}
+
+// PersonAbsencePeriod stores a range of days, e.g. a vacation, at which the person is absent.
+// It covers every meeting in the range, including meetings created later
+type PersonAbsencePeriod struct {
+	gorm.Model `json:"-"`
+	ID         uint
+	PersonID   uint `gorm:"not null;index" json:"-"`
+	// first day of absence
+	From time.Time `gorm:"column:from_date;not null;index"`
+	// last day of absence
+	To   time.Time `gorm:"column:to_date;not null;index"`
+	Note string
+}
+
+// BeforeSave validates the period and stores From and To as days
+func (pa *PersonAbsencePeriod) BeforeSave(_ *gorm.DB) (err error) {
+	if pa.PersonID == 0 || pa.From.IsZero() || pa.To.IsZero() {
+		return errors.ErrInvalidAbsencePeriod
+	}
+	pa.From, pa.To = calendar.DayOf(pa.From), calendar.DayOf(pa.To)
+	if pa.To.Before(pa.From) {
+		return errors.ErrInvalidAbsencePeriod
+	}
+	return nil
+}
+
+// Covers reports if the day of date is part of the period
+func (pa PersonAbsencePeriod) Covers(date time.Time) bool {
+	day := calendar.DayOf(date)
+	return !day.Before(calendar.DayOf(pa.From)) && !day.After(calendar.DayOf(pa.To))
+}
+
+// Types of PersonRelation
+const (
+	// RelationTogether people serve at the same meetings
+	RelationTogether = "together"
+	// RelationApart people never serve at the same meeting
+	RelationApart = "apart"
+	// RelationRequires person only serves at meetings, where the related person serves
+	RelationRequires = "requires"
+)
+
+// PersonRelation stores a rule between two people, which is enforced when people are assigned to a plan.
+// together and apart apply in both directions, requires only from person to related person
+type PersonRelation struct {
+	gorm.Model      `json:"-"`
+	ID              uint
+	PersonID        uint   `gorm:"not null;index:personRelation,unique"`
+	RelatedPersonID uint   `gorm:"not null;index:personRelation,unique"`
+	Type            string `gorm:"not null"`
+}
+
+func (pr *PersonRelation) validate() error {
+	if pr.PersonID == 0 || pr.RelatedPersonID == 0 || pr.PersonID == pr.RelatedPersonID {
+		return errors.ErrInvalidRelation
+	}
+	switch pr.Type {
+	case RelationTogether, RelationApart, RelationRequires:
+		return nil
+	default:
+		return errors.ErrInvalidRelation
+	}
+}
+
+// BeforeCreate validates the relation
+func (pr *PersonRelation) BeforeCreate(_ *gorm.DB) (err error) {
+	return pr.validate()
+}
+
+// BeforeUpdate validates the relation
+func (pr *PersonRelation) BeforeUpdate(_ *gorm.DB) (err error) {
+	return pr.validate()
+}
+
+// Partner returns the other person of the relation for personID.
+// ok is false, if the relation does not apply to personID
+func (pr PersonRelation) Partner(personID uint) (partner uint, ok bool) {
+	switch {
+	case pr.PersonID == personID:
+		return pr.RelatedPersonID, true
+	case pr.RelatedPersonID == personID && pr.Type != RelationRequires:
+		return pr.PersonID, true
+	default:
+		return 0, false
+	}
+}
+
+// Weights of a PersonPreference
+const (
+	// PreferencePrefers person is ranked before people with the same load
+	PreferencePrefers = "prefers"
+	// PreferenceNeutral person is ranked by load only
+	PreferenceNeutral = "neutral"
+	// PreferenceDislikes person is ranked after people with the same load
+	PreferenceDislikes = "dislikes"
+)
+
+// PersonPreference stores a soft wish of a person for a TaskDetail or a weekday.
+// Preferences only change the order of available people, they never make a person available or unavailable
+type PersonPreference struct {
+	gorm.Model `json:"-"`
+	ID         uint
+	PersonID   uint `gorm:"not null;index" json:"-"`
+	// either TaskDetailID or Weekday is set
+	TaskDetailID *uint
+	// 0 for sunday to 6 for saturday
+	Weekday *int
+	Weight  string `gorm:"not null"`
+}
+
+func (pp *PersonPreference) validate() error {
+	if pp.PersonID == 0 || (pp.TaskDetailID == nil) == (pp.Weekday == nil) {
+		return errors.ErrInvalidPreference
+	}
+	if pp.Weekday != nil && (*pp.Weekday < 0 || *pp.Weekday > 6) {
+		return errors.ErrInvalidPreference
+	}
+	switch pp.Weight {
+	case PreferencePrefers, PreferenceNeutral, PreferenceDislikes:
+		return nil
+	default:
+		return errors.ErrInvalidPreference
+	}
+}
+
+// BeforeCreate validates the preference
+func (pp *PersonPreference) BeforeCreate(_ *gorm.DB) (err error) {
+	return pp.validate()
+}
+
+// BeforeUpdate validates the preference
+func (pp *PersonPreference) BeforeUpdate(_ *gorm.DB) (err error) {
+	return pp.validate()
+}
+
+// Score returns 1 for prefers, -1 for dislikes and 0 for neutral
+func (pp PersonPreference) Score() int {
+	switch pp.Weight {
+	case PreferencePrefers:
+		return 1
+	case PreferenceDislikes:
+		return -1
+	default:
+		return 0
+	}
+}
+
+// Applies reports if the preference is for the TaskDetail or the weekday
+func (pp PersonPreference) Applies(taskDetailID uint, weekday time.Weekday) bool {
+	return (pp.TaskDetailID != nil && *pp.TaskDetailID == taskDetailID) ||
+		(pp.Weekday != nil && time.Weekday(*pp.Weekday) == weekday)
 }
 
 type PersonTask struct {
@@ -104,6 +571,8 @@ type PersonTask struct {
 	TaskDetailID uint       `gorm:"not null;index:personTask,unique" json:"-"`
 	TaskDetail   TaskDetail `gorm:"ForeignKey:PersonID"`
 	Person       Person     `gorm:"ForeignKey:TaskDetailID"`
+	// maximum number of assignments for the TaskDetail in a planning period, 0 for unlimited
+	MaxAssignmentsPeriod uint `gorm:"not null;default:0"`
 }
 
 func (pt PersonTask) MarshalJSON() ([]byte, error) {
@@ -2,6 +2,7 @@ package dbmodel
 
 import (
 	generalmodel "mpt_data/models/general"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -10,11 +11,13 @@ type Plan struct {
 	gorm.Model   `json:"-"`
 	ID           uint
 	PersonID     uint       `json:"-"`
-	MeetingID    uint       `json:"-" gorm:"not null;index:planTaskMeeting,unique"`
-	TaskDetailID uint       `json:"-" gorm:"not null;index:planTaskMeeting,unique"`
+	MeetingID    uint       `json:"-" gorm:"not null;index:planTaskMeetingSlot,unique"`
+	TaskDetailID uint       `json:"-" gorm:"not null;index:planTaskMeetingSlot,unique"`
 	Person       Person     `gorm:"ForeignKey:PersonID"`
 	Meeting      Meeting    `gorm:"ForeignKey:MeetingID"`
 	TaskDetail   TaskDetail `gorm:"ForeignKey:TaskDetailID"`
+	// index of the person for TaskDetails with a headcount greater than one, starting at 0
+	Slot uint `gorm:"not null;default:0;index:planTaskMeetingSlot,unique"`
 }
 
 type PDF struct {
@@ -23,4 +26,71 @@ type PDF struct {
 	FilePath string
 	generalmodel.Period
 	DataChanged bool
+	// Frozen PDFs are created at publication of a plan period and not recreated on changes of the plan
+	Frozen bool `gorm:"not null;default:false"`
+}
+
+// States of a PlanPeriod
+const (
+	// PlanPeriodDraft plan may be changed
+	PlanPeriodDraft = "draft"
+	// PlanPeriodPublished plan is handed out and only changed if forced
+	PlanPeriodPublished = "published"
+	// PlanPeriodArchived plan is over, the state can not be changed anymore
+	PlanPeriodArchived = "archived"
+)
+
+// PlanPeriod stores the state of the plan in a period
+type PlanPeriod struct {
+	gorm.Model `json:"-"`
+	ID         uint
+	generalmodel.Period
+	State string `gorm:"not null;default:draft"`
+	// time of the last change to published
+	PublishedAt *time.Time
+}
+
+// States of a SwapRequest
+const (
+	// SwapOffered person offers the plan element, nobody accepted yet
+	SwapOffered = "offered"
+	// SwapAccepted another person accepted and waits for approval
+	SwapAccepted = "accepted"
+	// SwapApproved the plan elements are swapped
+	SwapApproved = "approved"
+	// SwapRejected the swap is not done
+	SwapRejected = "rejected"
+)
+
+// SwapRequest records the offer of a person to swap a plan element with the plan element of another person
+type SwapRequest struct {
+	gorm.Model `json:"-"`
+	ID         uint
+	// offered plan element
+	PlanID uint `gorm:"not null"`
+	Plan   Plan `gorm:"ForeignKey:PlanID"`
+	// person of the offered plan element at the time of the offer
+	PersonID uint `gorm:"not null"`
+	// plan element of the person who accepted the offer
+	AcceptedPlanID *uint
+	AcceptedPlan   *Plan `gorm:"ForeignKey:AcceptedPlanID" json:",omitempty"`
+	// person of the accepted plan element at the time of the acceptance
+	AcceptedPersonID *uint
+	State            string `gorm:"not null;default:offered"`
+}
+
+// PlanRevision records a change of the person of an existing plan element
+type PlanRevision struct {
+	gorm.Model  `json:"-"`
+	ID          uint
+	PlanID      uint   `gorm:"not null;index"`
+	Plan        Plan   `gorm:"ForeignKey:PlanID"`
+	OldPersonID uint   `json:"-"`
+	OldPerson   Person `gorm:"ForeignKey:OldPersonID"`
+	NewPersonID uint   `json:"-"`
+	NewPerson   Person `gorm:"ForeignKey:NewPersonID"`
+	// user who changed the plan element, nil if authentication is disabled
+	UserID    *uint
+	ChangedAt time.Time `gorm:"not null;index"`
+	Reason    string
 }
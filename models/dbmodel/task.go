@@ -14,6 +14,7 @@ type Task struct {
 	gorm.Model  `json:"-"`
 	ID          uint
 	Descr       string       `gorm:"not null;uniqueIndex"`
+	DescrIndex  string       `gorm:"uniqueIndex" json:"-"`
 	TaskDetails []TaskDetail `gorm:"ForeignKey:TaskID" json:",omitempty"`
 	OrderNumber uint
 }
@@ -24,15 +25,18 @@ func (t *Task) BeforeCreate(db *gorm.DB) (err error) {
 		return errors.ErrTaskDescrNotSet
 	}
 
-	var tasks []Task
-	if err := db.Find(&tasks).Error; err != nil {
+	t.DescrIndex = helper.BlindIndex(t.Descr)
+	var count int64
+	if err :=
+		db.Session(&gorm.Session{NewDB: true}).
+			Model(&Task{}).
+			Where("descr_index = ?", t.DescrIndex).
+			Where("id <> ?", t.ID).
+			Count(&count).Error; err != nil {
 		return err
 	}
-
-	for _, task := range tasks {
-		if task.Descr == t.Descr {
-			return errors.ErrTaskAlreadyExists
-		}
+	if count != 0 {
+		return errors.ErrTaskAlreadyExists
 	}
 
 	descr, err := helper.EncryptData(t.Descr)
@@ -78,9 +82,24 @@ type TaskDetail struct {
 	gorm.Model  `json:"-"`
 	ID          uint
 	Descr       string `gorm:"not null;index:taskDetailsUnique,unique"`
-	TaskID      uint   `gorm:"not null;index:taskDetailsUnique,unique"`
+	DescrIndex  string `gorm:"index:taskDetailsIndex,unique" json:"-"`
+	TaskID      uint   `gorm:"not null;index:taskDetailsUnique,unique;index:taskDetailsIndex,unique"`
 	Task        Task   `json:",omitempty" gorm:"foreignkey:TaskID"`
 	OrderNumber uint
+	// number of people needed per meeting
+	Headcount uint `gorm:"not null;default:1"`
+	// overrides the minimum days between two assignments of a person from config, if set
+	MinRestDays *uint
+	// overrides the minimum meetings between two assignments of a person from config, if set
+	MinRestMeetings *uint
+}
+
+// Slots returns the number of people needed per meeting, at least one
+func (t TaskDetail) Slots() uint {
+	if t.Headcount == 0 {
+		return 1
+	}
+	return t.Headcount
 }
 
 // BeforeCreate hook for gorm
@@ -89,15 +108,19 @@ func (t *TaskDetail) BeforeCreate(db *gorm.DB) (err error) {
 		return errors.ErrTaskDescrNotSet
 	}
 
-	var tasks []TaskDetail
-	if err := db.Where("task_id = ?", t.TaskID).Find(&tasks).Error; err != nil {
+	t.DescrIndex = helper.BlindIndex(t.Descr)
+	var count int64
+	if err :=
+		db.Session(&gorm.Session{NewDB: true}).
+			Model(&TaskDetail{}).
+			Where("descr_index = ?", t.DescrIndex).
+			Where("task_id = ?", t.TaskID).
+			Where("id <> ?", t.ID).
+			Count(&count).Error; err != nil {
 		return err
 	}
-
-	for _, task := range tasks {
-		if task.Descr == t.Descr {
-			return errors.ErrTaskDetailAlreadyExists
-		}
+	if count != 0 {
+		return errors.ErrTaskDetailAlreadyExists
 	}
 
 	descr, err := helper.EncryptData(t.Descr)
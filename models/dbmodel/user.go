@@ -7,11 +7,26 @@ import (
 	"gorm.io/gorm"
 )
 
+// Roles of a User
+const (
+	// RoleAdmin may use all endpoints, users without role are admins as well
+	RoleAdmin = "admin"
+	// RoleSelf may only use the endpoints of the person linked to the user
+	RoleSelf = "self"
+)
+
 type User struct {
 	gorm.Model
 	Username string `gorm:"not null; uniqueIndex"`
 	Hash     string `gorm:"not null" json:"-"`
 	Role     string `gorm:"not null" json:"-"`
+	// person the user acts as at the self service endpoints, at most one user per person
+	PersonID *uint `gorm:"uniqueIndex" json:"-"`
+}
+
+// IsSelf reports if the user may only access the data of the linked person
+func (u User) IsSelf() bool {
+	return u.Role == RoleSelf
 }
 
 // Encrypt encrypts user data
@@ -5,15 +5,21 @@ package generalmodel
 const (
 	StartExecPDFAutoremoval = "Start execution of pdf autoremoval"
 	EndExecPDFAutoremoval   = "End execution of pdf autoremoval"
+	StartExecConflictCheck  = "Start execution of plan conflict check"
+	EndExecConflictCheck    = "End execution of plan conflict check"
 
 	DBMigrated = "database migration succesfull"
 
 	UnkownAcceptHeader = "unknown accept header"
+
+	PlanNotComplete = "plan could not be filled completely"
 )
 
 // Log message for Warning
 const (
 	UserInvalidLogin = "Invalid credentials"
+
+	PlanConflictFound = "plan element has a conflict"
 )
 
 // Log message for Error
@@ -33,6 +39,11 @@ const (
 
 	PlanCreationFailed = "failed to create plan"
 	PlanCreationError  = "error during plan creation"
+	PlanConflictFailed = "failed to check plan for conflicts"
+
+	ConflictCheckScheduleFailed = "could not schedule plan conflict check"
+
+	HolidayTaggingFailed = "failed to tag meetings at holidays"
 
 	InternalError    = "Internal Server Error"
 	StatusBadRequest = "Bad Request"
@@ -42,4 +53,5 @@ const (
 const (
 	AdditionalInfo = "AdditionalInfo"
 	AcceptHeader   = "AcceptHedear"
+	Reason         = "Reason"
 )
@@ -3,37 +3,168 @@ package models
 import (
 	"mpt_data/database"
 	"mpt_data/database/auth"
+	"mpt_data/helper"
 	"mpt_data/helper/errors"
+	"mpt_data/helper/rrule"
 	"mpt_data/models/apimodel"
 	"mpt_data/models/dbmodel"
 	generalmodel "mpt_data/models/general"
 	"os"
+	"time"
 
 	"go.uber.org/zap"
+	"gorm.io/gorm"
 )
 
 func Init() {
 	db := database.DB
 
+	// the unique index of plans was extended by the slot
+	if db.Migrator().HasIndex(&dbmodel.Plan{}, "planTaskMeeting") {
+		if err := db.Migrator().DropIndex(&dbmodel.Plan{}, "planTaskMeeting"); err != nil {
+			zap.L().Error(generalmodel.DBMigrationFailed, zap.Error(err))
+			os.Exit(1)
+		}
+	}
+
 	if err := db.AutoMigrate(
 		&dbmodel.User{},
+		&dbmodel.MeetingType{},
 		&dbmodel.Meeting{},
+		&dbmodel.MeetingSeries{},
+		&dbmodel.MeetingSeriesException{},
 		&dbmodel.Task{},
 		&dbmodel.TaskDetail{},
 		&dbmodel.Person{},
+		&dbmodel.PersonSearchIndex{},
 		&dbmodel.PersonTask{},
 		&dbmodel.PersonAbsence{},
 		&dbmodel.PersonRecurringAbsence{},
+		&dbmodel.PersonAbsencePeriod{},
+		&dbmodel.PersonRelation{},
+		&dbmodel.PersonPreference{},
 		&dbmodel.Plan{},
+		&dbmodel.PlanPeriod{},
+		&dbmodel.SwapRequest{},
+		&dbmodel.PlanRevision{},
 		&dbmodel.PDF{},
 	); err != nil {
 		zap.L().Error(generalmodel.DBMigrationFailed, zap.Error(err))
 		os.Exit(1)
 	}
 
+	if err := migrateRecurringAbsences(db); err != nil {
+		zap.L().Error(generalmodel.DBMigrationFailed, zap.Error(err))
+		os.Exit(1)
+	}
+
+	if err := migrateBlindIndex(db); err != nil {
+		zap.L().Error(generalmodel.DBMigrationFailed, zap.Error(err))
+		os.Exit(1)
+	}
+
 	if err := auth.CreateUser(apimodel.UserLogin{Username: "admin", Password: "admin"}); err != nil && err != errors.ErrUserAlreadyExists {
 		zap.L().Error(generalmodel.UserCreationFailed, zap.Error(err))
 	}
 
 	zap.L().Info(generalmodel.DBMigrated)
 }
+
+// migrateRecurringAbsences converts the weekday of recurring absences to a weekly rule,
+// then drops the weekday column together with its unique index
+func migrateRecurringAbsences(db *gorm.DB) error {
+	if !db.Migrator().HasColumn(&dbmodel.PersonRecurringAbsence{}, "weekday") {
+		return nil
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		var rows []struct {
+			ID      uint
+			Weekday int
+		}
+		if err :=
+			tx.Table("person_recurring_absences").
+				Select("id, weekday").
+				Where("rule IS NULL OR rule = ''").
+				Scan(&rows).Error; err != nil {
+			return err
+		}
+		for _, row := range rows {
+			if err :=
+				tx.Table("person_recurring_absences").
+					Where("id = ?", row.ID).
+					Update("rule", rrule.OnWeekday(time.Weekday(row.Weekday%7)).String()).Error; err != nil {
+				return err
+			}
+		}
+		if tx.Migrator().HasIndex(&dbmodel.PersonRecurringAbsence{}, "personRecurringAbsence") {
+			if err := tx.Migrator().DropIndex(&dbmodel.PersonRecurringAbsence{}, "personRecurringAbsence"); err != nil {
+				return err
+			}
+		}
+		return tx.Migrator().DropColumn(&dbmodel.PersonRecurringAbsence{}, "weekday")
+	})
+}
+
+// migrateBlindIndex sets the blind indexes of tasks, task details and people stored without them
+func migrateBlindIndex(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		var tasks []dbmodel.Task
+		if err := tx.Where("descr_index IS NULL OR descr_index = ''").Find(&tasks).Error; err != nil {
+			return err
+		}
+		for _, task := range tasks {
+			// the table is used, to skip the hooks encrypting the data
+			if err :=
+				tx.Table("tasks").
+					Where("id = ?", task.ID).
+					Update("descr_index", helper.BlindIndex(task.Descr)).Error; err != nil {
+				return err
+			}
+		}
+
+		var details []dbmodel.TaskDetail
+		if err := tx.Where("descr_index IS NULL OR descr_index = ''").Find(&details).Error; err != nil {
+			return err
+		}
+		for _, detail := range details {
+			if err :=
+				tx.Table("task_details").
+					Where("id = ?", detail.ID).
+					Update("descr_index", helper.BlindIndex(detail.Descr)).Error; err != nil {
+				return err
+			}
+		}
+
+		var people []dbmodel.Person
+		if err := tx.Where("name_index IS NULL OR name_index = ''").Find(&people).Error; err != nil {
+			return err
+		}
+		for _, person := range people {
+			person.SetIndex()
+			if err :=
+				tx.Table("people").
+					Where("id = ?", person.ID).
+					Updates(map[string]interface{}{
+						"given_name_index": person.GivenNameIndex,
+						"last_name_index":  person.LastNameIndex,
+						"name_index":       person.NameIndex,
+					}).Error; err != nil {
+				return err
+			}
+			var rows []dbmodel.PersonSearchIndex
+			for _, hash := range person.SearchIndex() {
+				rows = append(rows, dbmodel.PersonSearchIndex{PersonID: person.ID, Hash: hash})
+			}
+			if len(rows) == 0 {
+				continue
+			}
+			if err := tx.Where("person_id = ?", person.ID).Delete(&dbmodel.PersonSearchIndex{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Create(&rows).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -17,6 +17,7 @@ type RequestData struct {
 	Method string
 	Router func(http.ResponseWriter, *http.Request)
 	Path   string
+	Header map[string]string
 }
 
 func DoRequest(t *testing.T, reqData RequestData) *httptest.ResponseRecorder {
@@ -31,6 +32,10 @@ func DoRequest(t *testing.T, reqData RequestData) *httptest.ResponseRecorder {
 		t.Fatal(err)
 	}
 
+	for key, value := range reqData.Header {
+		req.Header.Set(key, value)
+	}
+
 	// rollback flag to true
 	req = req.WithContext(middleware.SetRollback(req.Context(), true))
 	rr := httptest.NewRecorder()